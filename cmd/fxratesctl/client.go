@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiClient calls the REST API of a running fxrates server
+type apiClient struct {
+	baseURL string
+	apiKey  string
+	timeout time.Duration
+	http    *http.Client
+}
+
+// apiError is an error response of the API
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// do sends the request and decodes the response into out, keeping the raw body for JSON output.
+// Statuses other than 2xx and 410 (closed updates) are returned as *apiError
+func (c *apiClient) do(ctx context.Context, method, path string, query url.Values, body, out any) (int, []byte, error) {
+	return c.doWithin(ctx, c.timeout, method, path, query, body, out)
+}
+
+func (c *apiClient) doWithin(ctx context.Context, timeout time.Duration, method, path string, query url.Values, body, out any) (int, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var reqBody io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, nil, err
+		}
+		reqBody = bytes.NewReader(payload)
+	}
+	u := strings.TrimSuffix(c.baseURL, "/") + "/api/v1" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusGone {
+		var errRes struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(raw, &errRes) != nil || errRes.Error == "" {
+			errRes.Error = strings.TrimSpace(string(raw))
+		}
+		return resp.StatusCode, raw, &apiError{Status: resp.StatusCode, Message: errRes.Error}
+	}
+	if out != nil {
+		if err = json.Unmarshal(raw, out); err != nil {
+			return resp.StatusCode, raw, fmt.Errorf("unexpected response: %w", err)
+		}
+	}
+	return resp.StatusCode, raw, nil
+}
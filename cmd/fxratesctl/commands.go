@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"fxrates/internal/app"
+	"fxrates/internal/domain"
+	"fxrates/internal/rate/handler"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// maxWaitPerRequest keeps long-polling requests within the server's default wait cap
+	maxWaitPerRequest = 30 * time.Second
+	// pollInterval paces status requests to servers answering without waiting (long-polling disabled)
+	pollInterval = time.Second
+)
+
+// Exit codes telling scripts how an update ended
+const (
+	exitError         = 1
+	exitUsage         = 2
+	exitUpdateClosed  = 3
+	exitUpdatePending = 4
+)
+
+// exitCodeError ends the command with a specific exit code
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e *exitCodeError) Error() string {
+	return e.msg
+}
+
+func usageError(format string, args ...any) error {
+	return &exitCodeError{code: exitUsage, msg: fmt.Sprintf(format, args...)}
+}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, ctx context.Context, args []string) error
+}
+
+// commandList is a func rather than a var, as commands refer to it for their usage
+func commandList() []command {
+	return []command{
+		{"schedule", "[-callback-url URL] [-wait DURATION] BASE QUOTE", "Schedule a rate update, optionally waiting until it's applied", (*cli).schedule},
+		{"status", "[-wait DURATION] UPDATE_ID", "Show the state of a rate update", (*cli).status},
+		{"get", "BASE QUOTE", "Show the latest rate of a pair", (*cli).get},
+		{"convert", "FROM TO AMOUNT", "Convert an amount with the latest rate", (*cli).convert},
+		{"currencies", "", "List supported currencies", (*cli).currencies},
+		{"run-job", "", "Run the update job once against the configured DB and providers (reads config.yaml and env like the server)", (*cli).runJob},
+	}
+}
+
+type cli struct {
+	client *apiClient
+	out    *printer
+	stderr io.Writer
+}
+
+// updateResponse is any of the GetByUpdateID responses, they differ by status
+type updateResponse struct {
+	UpdateID  string                  `json:"update_id"`
+	Base      string                  `json:"base"`
+	Quote     string                  `json:"quote"`
+	Status    domain.RateUpdateStatus `json:"status"`
+	Value     string                  `json:"value"`
+	Source    string                  `json:"source"`
+	Attempts  int                     `json:"attempts"`
+	Reason    string                  `json:"reason"`
+	UpdatedAt *time.Time              `json:"updated_at"`
+}
+
+func (c *cli) schedule(ctx context.Context, args []string) error {
+	fs := c.flagSet("schedule")
+	callbackURL := fs.String("callback-url", "", "URL receiving a signed POST with the applied rate")
+	wait := fs.Duration("wait", 0, "wait up to this long for the update to be applied, e.g. 1m")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	req := handler.ScheduleUpdateRequest{Base: strings.ToUpper(fs.Arg(0)), Quote: strings.ToUpper(fs.Arg(1)), CallbackURL: *callbackURL}
+	var res handler.ScheduleUpdateResponse
+	_, raw, err := c.client.do(ctx, http.MethodPost, "/rates/updates", nil, req, &res)
+	if err != nil {
+		return err
+	}
+	if *wait <= 0 {
+		return c.out.print(raw, []string{"UPDATE ID"}, [][]string{{res.UpdateID}})
+	}
+	fmt.Fprintf(c.stderr, "Update %s scheduled, waiting up to %s\n", res.UpdateID, *wait)
+	return c.showUpdate(ctx, res.UpdateID, *wait)
+}
+
+func (c *cli) status(ctx context.Context, args []string) error {
+	fs := c.flagSet("status")
+	wait := fs.Duration("wait", 0, "wait up to this long while the update is pending, e.g. 1m")
+	if err := parseArgs(fs, args, 1); err != nil {
+		return err
+	}
+	return c.showUpdate(ctx, fs.Arg(0), *wait)
+}
+
+// showUpdate prints the update once it isn't pending or the wait is over. Failed and expired updates, and updates
+// still pending after a wait, end with their own exit codes
+func (c *cli) showUpdate(ctx context.Context, updateID string, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	for {
+		res, raw, err := c.getUpdate(ctx, updateID, time.Until(deadline))
+		if err != nil {
+			return err
+		}
+		if res.Status == domain.StatusPending && time.Now().Before(deadline) {
+			continue
+		}
+
+		details := res.Source
+		switch res.Status {
+		case domain.StatusPending:
+			details = fmt.Sprintf("attempts: %d", res.Attempts)
+		case domain.StatusFailed, domain.StatusExpired:
+			details = res.Reason
+		}
+		row := []string{res.UpdateID, res.Base + "/" + res.Quote, string(res.Status), orDash(res.Value), formatTime(res.UpdatedAt), orDash(details)}
+		if err = c.out.print(raw, []string{"UPDATE ID", "PAIR", "STATUS", "VALUE", "UPDATED AT", "DETAILS"}, [][]string{row}); err != nil {
+			return err
+		}
+
+		switch {
+		case res.Status == domain.StatusFailed || res.Status == domain.StatusExpired:
+			return &exitCodeError{code: exitUpdateClosed, msg: fmt.Sprintf("update %s", res.Status)}
+		case res.Status == domain.StatusPending && wait > 0:
+			return &exitCodeError{code: exitUpdatePending, msg: fmt.Sprintf("update is still pending after %s", wait)}
+		}
+		return nil
+	}
+}
+
+// getUpdate long-polls the update for up to wait (split into requests the server accepts)
+func (c *cli) getUpdate(ctx context.Context, updateID string, wait time.Duration) (updateResponse, []byte, error) {
+	wait = min(max(wait, 0), maxWaitPerRequest).Round(time.Millisecond)
+	query := url.Values{}
+	if wait > 0 {
+		query.Set("wait", wait.String())
+	}
+
+	start := time.Now()
+	var res updateResponse
+	_, raw, err := c.client.doWithin(ctx, c.client.timeout+wait, http.MethodGet, "/rates/updates/"+url.PathEscape(updateID), query, nil, &res)
+	if err != nil {
+		return updateResponse{}, nil, err
+	}
+	// the server answered before the wait was over while the update is pending, so it doesn't long-poll
+	if elapsed := time.Since(start); res.Status == domain.StatusPending && elapsed < wait {
+		select {
+		case <-ctx.Done():
+			return updateResponse{}, nil, ctx.Err()
+		case <-time.After(min(pollInterval, wait-elapsed)):
+		}
+	}
+	return res, raw, nil
+}
+
+func (c *cli) get(ctx context.Context, args []string) error {
+	fs := c.flagSet("get")
+	if err := parseArgs(fs, args, 2); err != nil {
+		return err
+	}
+
+	base, quote := strings.ToUpper(fs.Arg(0)), strings.ToUpper(fs.Arg(1))
+	var res handler.GetByCodesResponse
+	_, raw, err := c.client.do(ctx, http.MethodGet, "/rates/"+url.PathEscape(base)+"/"+url.PathEscape(quote), nil, nil, &res)
+	if err != nil {
+		return err
+	}
+	row := []string{res.Base + "/" + res.Quote, res.Value, formatTime(&res.UpdatedAt), legsOf(res.Legs)}
+	return c.out.print(raw, []string{"PAIR", "VALUE", "UPDATED AT", "DERIVED FROM"}, [][]string{row})
+}
+
+func (c *cli) convert(ctx context.Context, args []string) error {
+	fs := c.flagSet("convert")
+	if err := parseArgs(fs, args, 3); err != nil {
+		return err
+	}
+
+	query := url.Values{"from": {strings.ToUpper(fs.Arg(0))}, "to": {strings.ToUpper(fs.Arg(1))}, "amount": {fs.Arg(2)}}
+	var res handler.ConvertResponse
+	_, raw, err := c.client.do(ctx, http.MethodGet, "/convert", query, nil, &res)
+	if err != nil {
+		return err
+	}
+	row := []string{res.Amount + " " + res.From, res.ConvertedAmount + " " + res.To, res.Rate, formatTime(&res.UpdatedAt), legsOf(res.Legs)}
+	return c.out.print(raw, []string{"AMOUNT", "CONVERTED", "RATE", "UPDATED AT", "DERIVED FROM"}, [][]string{row})
+}
+
+func (c *cli) currencies(ctx context.Context, args []string) error {
+	fs := c.flagSet("currencies")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+
+	var res handler.GetSupportedCodesResponse
+	_, raw, err := c.client.do(ctx, http.MethodGet, "/rates/supported-currencies", nil, nil, &res)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(res.Codes))
+	for _, code := range res.Codes {
+		rows = append(rows, []string{code})
+	}
+	return c.out.print(raw, []string{"CODE"}, rows)
+}
+
+func (c *cli) runJob(ctx context.Context, args []string) error {
+	fs := c.flagSet("run-job")
+	if err := parseArgs(fs, args, 0); err != nil {
+		return err
+	}
+	return app.RunUpdateJob(ctx)
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	for _, cmd := range commandList() {
+		if cmd.name == name {
+			fs.Usage = func() {
+				fmt.Fprintf(c.stderr, "Usage: fxratesctl %s %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+				fs.PrintDefaults()
+			}
+		}
+	}
+	return fs
+}
+
+// parseArgs parses flags and checks the number of positional args
+func parseArgs(fs *flag.FlagSet, args []string, n int) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return &exitCodeError{code: 0}
+		}
+		return usageError("%v", err)
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return usageError("%s expects %d argument(s), got %d", fs.Name(), n, fs.NArg())
+	}
+	return nil
+}
+
+func legsOf(legs []handler.RateLeg) string {
+	if len(legs) == 0 {
+		return "-"
+	}
+	pairs := make([]string, 0, len(legs))
+	for _, leg := range legs {
+		pairs = append(pairs, leg.Base+"/"+leg.Quote)
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,95 @@
+// Command fxratesctl is a client of the fxrates API for operators: it schedules and inspects rate updates,
+// reads rates and currencies, and can run the update job once without the server
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("fxratesctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { printUsage(fs, stderr) }
+	addr := fs.String("addr", envOr("FXRATES_ADDR", "http://localhost:8080"), "API address, $FXRATES_ADDR")
+	apiKey := fs.String("api-key", os.Getenv("FXRATES_API_KEY"), "API key, $FXRATES_API_KEY is preferred to keep it out of shell history")
+	output := fs.String("o", outputTable, "output format: table or json (the API response)")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of a request, not counting -wait")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	c := &cli{
+		client: &apiClient{baseURL: *addr, apiKey: *apiKey, timeout: *timeout, http: &http.Client{}},
+		out:    &printer{w: stdout, format: *output},
+		stderr: stderr,
+	}
+	for _, cmd := range commandList() {
+		if cmd.name == fs.Arg(0) {
+			return exitCode(cmd.run(c, ctx, fs.Args()[1:]), stderr)
+		}
+	}
+	fmt.Fprintf(stderr, "unknown command %q\n\n", fs.Arg(0))
+	fs.Usage()
+	return exitUsage
+}
+
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		if codeErr.msg != "" {
+			fmt.Fprintln(stderr, codeErr.msg)
+		}
+		return codeErr.code
+	}
+	fmt.Fprintln(stderr, "Error:", err)
+	return exitError
+}
+
+func printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "Usage: fxratesctl [flags] <command> [command flags] [args]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commandList() {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nFlags:")
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nExit codes: 0 ok, %d error, %d usage, %d update failed or expired, %d update still pending after -wait\n",
+		exitError, exitUsage, exitUpdateClosed, exitUpdatePending)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
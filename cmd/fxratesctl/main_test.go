@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const updateID = "77b5d9f5-0569-47e3-aee2-f659d59fbd97"
+
+func runCLI(t *testing.T, server *httptest.Server, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append([]string{"-addr", server.URL, "-api-key", "secret"}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestSchedule_Wait_LongPollsUntilApplied(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "secret", r.Header.Get("X-API-Key"))
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/rates/updates":
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, "USD", req["base"])
+			require.Equal(t, "EUR", req["quote"])
+			writeJSON(w, http.StatusAccepted, map[string]string{"update_id": updateID})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/rates/updates/"+updateID:
+			require.Equal(t, "30s", r.URL.Query().Get("wait"))
+			if polls.Add(1) == 1 {
+				writeJSON(w, http.StatusAccepted, map[string]any{"update_id": updateID, "base": "USD", "quote": "EUR", "status": "pending", "attempts": 1})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"update_id": updateID, "base": "USD", "quote": "EUR", "status": "applied",
+				"value": "0.92310000", "updated_at": "2025-01-02T15:04:05Z", "source": "frankfurter"})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "schedule", "-wait", "2m", "usd", "eur")
+
+	require.Equal(t, 0, code, stderr)
+	require.Equal(t, int32(2), polls.Load())
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, []string{"UPDATE", "ID", "PAIR", "STATUS", "VALUE", "UPDATED", "AT", "DETAILS"}, strings.Fields(lines[0]))
+	require.Equal(t, []string{updateID, "USD/EUR", "applied", "0.92310000", "2025-01-02T15:04:05Z", "frankfurter"}, strings.Fields(lines[1]))
+}
+
+func TestStatus_Closed_ExitsWithClosedCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusGone, map[string]any{"update_id": updateID, "base": "USD", "quote": "EUR", "status": "failed",
+			"reason": "rate wasn't fetched after 10 attempts", "attempts": 10, "updated_at": "2025-01-02T15:04:05Z"})
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "-o", "json", "status", updateID)
+
+	require.Equal(t, exitUpdateClosed, code)
+	require.Contains(t, stderr, "update failed")
+	var res map[string]any
+	require.NoError(t, json.Unmarshal([]byte(stdout), &res))
+	require.Equal(t, "rate wasn't fetched after 10 attempts", res["reason"])
+}
+
+func TestStatus_Wait_StillPendingWithoutLongPolling(t *testing.T) {
+	var polls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls.Add(1)
+		writeJSON(w, http.StatusAccepted, map[string]any{"update_id": updateID, "base": "USD", "quote": "EUR", "status": "pending", "attempts": 3})
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "status", "-wait", "100ms", updateID)
+
+	require.Equal(t, exitUpdatePending, code)
+	require.Contains(t, stderr, "still pending after 100ms")
+	require.Contains(t, stdout, "attempts: 3")
+	require.LessOrEqual(t, polls.Load(), int32(2)) // paced instead of hammering the server
+}
+
+func TestCurrencies_Table(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/rates/supported-currencies", r.URL.Path)
+		writeJSON(w, http.StatusOK, map[string]any{"codes": []string{"EUR", "USD"}})
+	}))
+	defer server.Close()
+
+	code, stdout, _ := runCLI(t, server, "currencies")
+
+	require.Equal(t, 0, code)
+	require.Equal(t, "CODE\nEUR\nUSD\n", stdout)
+}
+
+func TestConvert_APIError_ExitsWithError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "USD", r.URL.Query().Get("from"))
+		require.Equal(t, "12.5", r.URL.Query().Get("amount"))
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "rate not found"})
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCLI(t, server, "convert", "usd", "eur", "12.5")
+
+	require.Equal(t, exitError, code)
+	require.Empty(t, stdout)
+	require.Contains(t, stderr, "rate not found (HTTP 404)")
+}
+
+func TestRun_Usage(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	code, _, stderr := runCLI(t, server, "nope")
+	require.Equal(t, exitUsage, code)
+	require.Contains(t, stderr, `unknown command "nope"`)
+
+	code, _, _ = runCLI(t, server, "get", "USD")
+	require.Equal(t, exitUsage, code)
+}
@@ -4,6 +4,10 @@ package main
 // @version 1.0
 // @description API for scheduling and retrieving foreign exchange rates
 // @BasePath /api/v1
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key issued via /admin/api-keys, "Authorization: Bearer <key>" is accepted as well
 
 import (
 	"fxrates/internal/app"
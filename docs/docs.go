@@ -15,29 +15,220 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/rates/supported-currencies": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Retrieve all supported currency codes for FX requests",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List issued keys, revoked and expired ones included. Keys themselves aren't stored, only their prefixes are shown",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rates"
+                    "Admin"
                 ],
-                "summary": "List supported currencies",
+                "summary": "List API keys",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.GetSupportedCodesResponse"
+                            "$ref": "#/definitions/handler.ListKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Issue a key for the owner with the given scopes (rates:read, rates:schedule, keys:admin, currencies:admin) and optional expiry.\nThe key is returned only in this response, just its hash is stored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create API key",
+                "parameters": [
+                    {
+                        "description": "Key parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
                         }
                     }
                 }
             }
         },
-        "/rates/updates": {
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Revoke the key immediately, requests with it are rejected with 401 afterwards",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_apikey_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/currencies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "List all currencies with their ISO 4217 details, disabled ones included",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List currencies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ListCurrenciesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Schedule a rate update for a currency pair",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a currency, or enable a disabled one with the given details. It's accepted in requests right away, no restart needed",
                 "consumes": [
                     "application/json"
                 ],
@@ -45,114 +236,160 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Rates"
+                    "Admin"
                 ],
-                "summary": "Schedule rate update",
+                "summary": "Add currency",
                 "parameters": [
                     {
-                        "description": "ApplyUpdates parameters",
+                        "description": "Currency",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.ScheduleUpdateRequest"
+                            "$ref": "#/definitions/handler.AddCurrencyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "Accepted",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/handler.ScheduleUpdateResponse"
+                            "$ref": "#/definitions/handler.CurrencyResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
                         }
                     }
                 }
             }
         },
-        "/rates/updates/{id}": {
-            "get": {
-                "description": "Get the applied rate for a scheduled update ID",
+        "/admin/currencies/{code}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Stop accepting the currency in requests right away. Its rates and history are kept, adding it again enables it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rates"
+                    "Admin"
                 ],
-                "summary": "Get rate by update ID",
+                "summary": "Disable currency",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Update ID",
-                        "name": "id",
+                        "example": "SEK",
+                        "description": "Currency code",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "rate update applied",
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/handler.GetByUpdateIDApplied"
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
                         }
                     },
-                    "202": {
-                        "description": "rate update pending",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/handler.GetByUpdateIDPending"
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_currency_handler.errorResponse"
                         }
                     }
                 }
             }
         },
-        "/rates/{base}/{quote}": {
+        "/convert": {
             "get": {
-                "description": "Get the latest applied FX rate by base/quote codes",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Convert an amount using the latest stored rate. The reversed pair is used when the direct one is missing, then the rate is derived through the pivot currency. The converted amount is a decimal string rounded half away from zero to the minor units of the target currency, the rate has 8 fractional digits",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Rates"
+                    "Conversion"
                 ],
-                "summary": "Get latest rate by codes",
+                "summary": "Convert amount",
                 "parameters": [
                     {
                         "type": "string",
                         "example": "USD",
-                        "description": "Base currency code",
-                        "name": "base",
-                        "in": "path",
+                        "description": "Source currency code",
+                        "name": "from",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
                         "example": "EUR",
-                        "description": "Quote currency code",
-                        "name": "quote",
-                        "in": "path",
+                        "description": "Target currency code",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "example": 125.5,
+                        "description": "Positive amount in source currency",
+                        "name": "amount",
+                        "in": "query",
                         "required": true
                     }
                 ],
@@ -160,75 +397,754 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.GetByCodesResponse"
+                            "$ref": "#/definitions/handler.ConvertResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/handler.errorResponse"
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.RateUpdateStatus": {
-            "type": "string",
-            "enum": [
-                "pending",
-                "applied"
-            ],
-            "x-enum-varnames": [
-                "StatusPending",
-                "StatusApplied"
-            ]
         },
-        "handler.GetByCodesResponse": {
-            "type": "object",
-            "properties": {
-                "base": {
-                    "type": "string",
-                    "example": "USD"
-                },
-                "quote": {
-                    "type": "string",
-                    "example": "EUR"
-                },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2025-01-02T15:04:05Z"
-                },
-                "value": {
-                    "type": "number",
-                    "example": 0.9231
+        "/rates/stream": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of values applied for the given pairs. Every applied value is sent as a ` + "`" + `rate` + "`" + ` event\nwith RateEvent JSON data and the update ID as event ID. Comment lines are sent as heartbeats to keep the connection alive",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Stream applied rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "USD/EUR,GBP/JPY",
+                        "description": "Comma-separated pairs, up to 50",
+                        "name": "pairs",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RateEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
                 }
             }
         },
-        "handler.GetByUpdateIDApplied": {
-            "type": "object",
-            "properties": {
-                "base": {
-                    "type": "string",
-                    "example": "USD"
-                },
-                "quote": {
+        "/rates/supported-currencies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Retrieve all supported currency codes for FX requests",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "List supported currencies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetSupportedCodesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/updates": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Schedule a rate update for a currency pair. Optional callback_url gets a signed POST once the update is applied\n(retried with exponential backoff until a 2xx response), so polling the update isn't required",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Schedule rate update",
+                "parameters": [
+                    {
+                        "description": "ApplyUpdates parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/updates/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the applied rate for a scheduled update ID. Pending updates respond with 202, updates closed without a value (failed or expired) respond with 410 and a reason. Values are decimal strings with 8 fractional digits. Rates agreed by several providers carry per-provider quotes, rejected outliers included\nWith ` + "`" + `wait` + "`" + ` a pending update is long-polled: the response is held until the update is applied or closed, or the wait expires with 202",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Get rate by update ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Update ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "20s",
+                        "description": "How long to wait for a pending update, capped by the server maximum",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "rate update applied",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetByUpdateIDApplied"
+                        }
+                    },
+                    "202": {
+                        "description": "rate update pending, poll again later",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetByUpdateIDPending"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "rate update failed or expired, stop polling",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetByUpdateIDClosed"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/updates:batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Schedule rate updates for up to 100 pairs at once. Results follow the request order, each has either\nan update ID or the reason the pair was rejected. Valid pairs are scheduled even when others are rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Schedule rate updates in batch",
+                "parameters": [
+                    {
+                        "description": "Pairs to update",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleUpdatesBatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ScheduleUpdatesBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{quote}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get the latest applied FX rate by base/quote codes. Values are decimal strings with 8 fractional digits. When the pair is missing, the rate may be derived through the pivot currency (derived=true, legs are listed)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Get latest rate by codes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "USD",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Quote currency code",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetByCodesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{quote}/history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get applied FX rate values of a pair within [from, to). Values are decimal strings with 8 fractional digits. When interval is set, the last value of each interval bucket is returned",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Rates"
+                ],
+                "summary": "Get rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "USD",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Quote currency code",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-01T00:00:00Z",
+                        "description": "Range start, RFC3339 (default: 24h before 'to')",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "2025-01-02T00:00:00Z",
+                        "description": "Range end, RFC3339 (default: now)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "1h",
+                        "description": "Bucket size as Go duration, at least 1m",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GetHistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_rate_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.RateUpdateStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "applied",
+                "failed",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusApplied",
+                "StatusFailed",
+                "StatusExpired"
+            ]
+        },
+        "handler.APIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "pricing-service"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "fxr_Q2hhbmdl"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rates:read",
+                        "rates:schedule"
+                    ]
+                }
+            }
+        },
+        "handler.AddCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "SEK"
+                },
+                "minor_units": {
+                    "description": "MinorUnits defaults to the ISO 4217 minor units of the code",
+                    "type": "integer",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Swedish Krona"
+                },
+                "numeric_code": {
+                    "type": "string",
+                    "example": "752"
+                }
+            }
+        },
+        "handler.ConvertResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "125.5"
+                },
+                "converted_amount": {
+                    "type": "string",
+                    "example": "115.85"
+                },
+                "derived": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "from": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.RateLeg"
+                    }
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "0.92310000"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                }
+            }
+        },
+        "handler.CreateKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "pricing-service"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rates:read",
+                        "rates:schedule"
+                    ]
+                }
+            }
+        },
+        "handler.CreateKeyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-01-02T15:04:05Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "fxr_Q2hhbmdlIG1lIQ"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "pricing-service"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "fxr_Q2hhbmdl"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rates:read",
+                        "rates:schedule"
+                    ]
+                }
+            }
+        },
+        "handler.CurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "minor_units": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "name": {
+                    "type": "string",
+                    "example": "US Dollar"
+                },
+                "numeric_code": {
+                    "type": "string",
+                    "example": "840"
+                }
+            }
+        },
+        "handler.GetByCodesResponse": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "derived": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "legs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.RateLeg"
+                    }
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "0.92310000"
+                }
+            }
+        },
+        "handler.GetByUpdateIDApplied": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "quote": {
                     "type": "string",
                     "example": "EUR"
                 },
+                "quotes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ProviderQuote"
+                    }
+                },
+                "source": {
+                    "type": "string",
+                    "example": "consensus"
+                },
                 "status": {
                     "allOf": [
                         {
@@ -246,14 +1162,59 @@ const docTemplate = `{
                     "example": "2025-01-02T15:04:05Z"
                 },
                 "value": {
-                    "type": "number",
-                    "example": 0.9231
+                    "type": "string",
+                    "example": "0.92310000"
+                }
+            }
+        },
+        "handler.GetByUpdateIDClosed": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "rate wasn't fetched after 10 attempts"
+                },
+                "status": {
+                    "enum": [
+                        "failed",
+                        "expired"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RateUpdateStatus"
+                        }
+                    ],
+                    "example": "failed"
+                },
+                "update_id": {
+                    "type": "string",
+                    "example": "77b5d9f5-0569-47e3-aee2-f659d59fbd97"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
                 }
             }
         },
         "handler.GetByUpdateIDPending": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "base": {
                     "type": "string",
                     "example": "USD"
@@ -276,6 +1237,37 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.GetHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2025-01-01T15:04:05Z"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "1h0m0s"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.HistoryPoint"
+                    }
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                }
+            }
+        },
         "handler.GetSupportedCodesResponse": {
             "type": "object",
             "properties": {
@@ -292,6 +1284,108 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.HistoryPoint": {
+            "type": "object",
+            "properties": {
+                "recorded_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "0.92310000"
+                }
+            }
+        },
+        "handler.ListCurrenciesResponse": {
+            "type": "object",
+            "properties": {
+                "currencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.CurrencyResponse"
+                    }
+                }
+            }
+        },
+        "handler.ListKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.APIKeyResponse"
+                    }
+                }
+            }
+        },
+        "handler.ProviderQuote": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "frankfurter"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "0.92310000"
+                }
+            }
+        },
+        "handler.RateEvent": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "frankfurter"
+                },
+                "update_id": {
+                    "type": "string",
+                    "example": "77b5d9f5-0569-47e3-aee2-f659d59fbd97"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "0.92310000"
+                }
+            }
+        },
+        "handler.RateLeg": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "MXN"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-01-02T15:04:05Z"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "0.05810000"
+                }
+            }
+        },
         "handler.ScheduleUpdateRequest": {
             "type": "object",
             "properties": {
@@ -299,6 +1393,11 @@ const docTemplate = `{
                     "type": "string",
                     "example": "USD"
                 },
+                "callback_url": {
+                    "description": "CallbackURL receives a signed POST with the applied rate, see README for the payload and signature",
+                    "type": "string",
+                    "example": "https://pricing.example.com/hooks/fx"
+                },
                 "quote": {
                     "type": "string",
                     "example": "EUR"
@@ -314,7 +1413,81 @@ const docTemplate = `{
                 }
             }
         },
-        "handler.errorResponse": {
+        "handler.ScheduleUpdatesBatchPair": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                }
+            }
+        },
+        "handler.ScheduleUpdatesBatchRequest": {
+            "type": "object",
+            "properties": {
+                "pairs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ScheduleUpdatesBatchPair"
+                    }
+                }
+            }
+        },
+        "handler.ScheduleUpdatesBatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ScheduleUpdatesBatchResult"
+                    }
+                }
+            }
+        },
+        "handler.ScheduleUpdatesBatchResult": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "quote currency not supported"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "EUR"
+                },
+                "update_id": {
+                    "type": "string",
+                    "example": "77b5d9f5-0569-47e3-aee2-f659d59fbd97"
+                }
+            }
+        },
+        "internal_apikey_handler.errorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something bad happened"
+                }
+            }
+        },
+        "internal_currency_handler.errorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "something bad happened"
+                }
+            }
+        },
+        "internal_rate_handler.errorResponse": {
             "type": "object",
             "properties": {
                 "error": {
@@ -323,6 +1496,14 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key issued via /admin/api-keys, \"Authorization: Bearer \u003ckey\u003e\" is accepted as well",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        }
     }
 }`
 
@@ -3,23 +3,75 @@ package adapters
 import (
 	"context"
 	"fxrates/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type RateClient interface {
-	GetExchangeRates(ctx context.Context, code string) (map[string]float64, error)
+	GetExchangeRates(ctx context.Context, code string) (domain.ExchangeRates, error)
 }
 
 type RateRepository interface {
 	GetByCodes(ctx context.Context, base string, quote string) (domain.Rate, error)
 	GetByUpdateID(ctx context.Context, updateID uuid.UUID) (domain.Rate, domain.RateUpdateStatus, error)
+	GetHistory(ctx context.Context, base string, quote string, from time.Time, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error)
 }
 
+// RateUpdateRepository stores rate updates. Pending updates are claimed by a job run for the lease,
+// writes of the run take effect only for updates still claimed by it
 type RateUpdateRepository interface {
 	ScheduleNewOrGetExisting(ctx context.Context, base string, quote string) (uuid.UUID, error)
-	GetPending(ctx context.Context) ([]domain.PendingRateUpdate, error)
-	ApplyUpdates(ctx context.Context, rates []domain.AppliedRateUpdate) error
+	ScheduleNewOrGetExistingBatch(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error)
+	ClaimPending(ctx context.Context, claimID string, lease time.Duration) ([]domain.PendingRateUpdate, error)
+	ApplyUpdates(ctx context.Context, claimID string, rates []domain.AppliedRateUpdate) error
+	IncrementAttempts(ctx context.Context, claimID string, updateIDs []uuid.UUID) error
+	ReleaseClaims(ctx context.Context, claimID string, updateIDs []uuid.UUID) error
+	CloseUpdates(ctx context.Context, claimID string, closed []domain.ClosedRateUpdate) error
+}
+
+// RateUpdateCallbackRepository is the outbox of callbacks to deliver once their updates are applied
+type RateUpdateCallbackRepository interface {
+	Register(ctx context.Context, updateID uuid.UUID, url string) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.RateUpdateCallback, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	Reschedule(ctx context.Context, id int64, after time.Duration, lastErr string) error
+	MarkFailed(ctx context.Context, id int64, lastErr string) error
+}
+
+type CallbackSender interface {
+	Send(ctx context.Context, url string, payload []byte) error
+}
+
+// RatePublisher fans applied rate changes out to in-process subscribers, it must not block the publisher
+type RatePublisher interface {
+	Publish(changes []domain.RateChange)
+}
+
+// UpdateNotifier is told about updates finished (applied or closed) by a run, it must not block the run
+type UpdateNotifier interface {
+	NotifyFinished(updateIDs []uuid.UUID)
+}
+
+// APIKeyRepository stores issued API keys by the hash of the key
+type APIKeyRepository interface {
+	Create(ctx context.Context, keyHash string, key domain.APIKey) (domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
+
+// CurrencyRepository stores currencies, disabled ones are kept as pairs and history reference them
+type CurrencyRepository interface {
+	List(ctx context.Context) ([]domain.Currency, error)
+	Add(ctx context.Context, currency domain.Currency) (domain.Currency, error)
+	Disable(ctx context.Context, code string) error
+}
+
+// UpstreamBudget caps fetches of rates from paid upstream APIs
+type UpstreamBudget interface {
+	// Reserve takes up to n fetches from the budget and returns how many were granted
+	Reserve(ctx context.Context, n int) (int, error)
 }
 
 type RateUpdateCache interface {
@@ -3,6 +3,7 @@ package cache
 import (
 	"fmt"
 	"fxrates/internal/domain"
+	"fxrates/internal/metrics"
 
 	"github.com/dgraph-io/ristretto"
 	"github.com/google/uuid"
@@ -27,8 +28,10 @@ func NewRateUpdateCache(maxItems int64) (*RistrettoRateUpdateCache, error) {
 func (c *RistrettoRateUpdateCache) Get(pair domain.RatePair) (uuid.UUID, bool) {
 	if v, ok := c.cache.Get(toKey(pair)); ok {
 		id, ok := v.(uuid.UUID)
+		metrics.ObserveCacheLookup(ok)
 		return id, ok
 	}
+	metrics.ObserveCacheLookup(false)
 	return uuid.Nil, false
 }
 
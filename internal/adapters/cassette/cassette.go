@@ -0,0 +1,256 @@
+// Package cassette records answers of a rate client to a file and replays them later, so incidents
+// caught in production can be reproduced locally without calling the paid upstream API
+package cassette
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+// Kinds of recorded errors, replayed errors match the same domain (or context) errors the recorded ones did
+const (
+	ErrorKindAuth        = "auth"
+	ErrorKindQuota       = "quota"
+	ErrorKindUnsupported = "unsupported"
+	ErrorKindUnavailable = "unavailable"
+	ErrorKindDeadline    = "deadline"
+	ErrorKindCanceled    = "canceled"
+)
+
+// ErrExhausted is returned on replay once all interactions recorded for the base were played
+var ErrExhausted = errors.New("no more recorded interactions")
+
+// errorKinds maps kinds to the errors they match, kinds are checked in this order when recording
+var errorKinds = []struct {
+	kind string
+	errs []error
+}{
+	{ErrorKindAuth, []error{domain.ErrUpstreamAuth}},
+	{ErrorKindQuota, []error{domain.ErrUpstreamQuota, domain.ErrUpstreamUnavailable}},
+	{ErrorKindUnsupported, []error{domain.ErrUnsupportedCurrency}},
+	{ErrorKindUnavailable, []error{domain.ErrUpstreamUnavailable}},
+	{ErrorKindDeadline, []error{context.DeadlineExceeded}},
+	{ErrorKindCanceled, []error{context.Canceled}},
+}
+
+// Interaction is a single GetExchangeRates call, a line of the cassette file (JSON Lines).
+// Exactly one of Response and Error is set, ErrorKind is empty for errors of no known kind
+type Interaction struct {
+	Seq        int       `json:"seq"`
+	Base       string    `json:"base"`
+	RecordedAt time.Time `json:"recorded_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Response   *Response `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	ErrorKind  string    `json:"error_kind,omitempty"`
+}
+
+// Response is the recorded conversion table, see domain.ExchangeRates
+type Response struct {
+	Provider string                            `json:"provider"`
+	Rates    map[string]decimal.Decimal        `json:"rates"`
+	Quotes   map[string][]domain.ProviderQuote `json:"quotes,omitempty"`
+}
+
+// Recorder passes calls to the wrapped client and appends each of them to the cassette
+type Recorder struct {
+	next adapters.RateClient
+	mu   sync.Mutex
+	file *os.File
+	seq  int
+	now  func() time.Time
+}
+
+func (r *Recorder) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	start := r.now()
+	rates, err := r.next.GetExchangeRates(ctx, base)
+
+	interaction := Interaction{Base: base, RecordedAt: start.UTC(), DurationMs: r.now().Sub(start).Milliseconds()}
+	if err != nil {
+		interaction.Error, interaction.ErrorKind = err.Error(), errorKind(err)
+	} else {
+		interaction.Response = &Response{Provider: rates.Provider, Rates: rates.Rates, Quotes: rates.Quotes}
+	}
+	// the cassette is a debugging aid, failing to record doesn't fail the call
+	if recErr := r.append(interaction); recErr != nil {
+		logrus.WithError(recErr).Warn("Rate client interaction wasn't recorded")
+	}
+	return rates, err
+}
+
+func (r *Recorder) append(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	interaction.Seq = r.seq
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return fmt.Errorf("failed to encode interaction for currency %q: %w", interaction.Base, err)
+	}
+	if _, err = r.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to record interaction for currency %q: %w", interaction.Base, err)
+	}
+	return nil
+}
+
+// Close closes the cassette file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// NewRecorder wraps the client, appending its interactions to the cassette at path (created along with its
+// directory when missing).
+// Interactions already in the file are kept and numbered on
+func NewRecorder(next adapters.RateClient, path string) (*Recorder, error) {
+	recorded, err := readInteractions(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	r := &Recorder{next: next, file: file, now: time.Now}
+	for _, i := range recorded {
+		r.seq = max(r.seq, i.Seq)
+	}
+	return r, nil
+}
+
+// errorKind is the first kind err matches, empty when it matches none
+func errorKind(err error) string {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.errs[0]) {
+			return k.kind
+		}
+	}
+	return ""
+}
+
+// replayedError has the recorded message and matches the errors of the recorded kind
+type replayedError struct {
+	msg  string
+	errs []error
+}
+
+func (e *replayedError) Error() string {
+	return e.msg
+}
+
+func (e *replayedError) Unwrap() []error {
+	return e.errs
+}
+
+func replayError(interaction Interaction) error {
+	for _, k := range errorKinds {
+		if k.kind == interaction.ErrorKind {
+			return &replayedError{msg: interaction.Error, errs: k.errs}
+		}
+	}
+	return errors.New(interaction.Error)
+}
+
+// Player answers calls with the recorded interactions of the requested base, in recording order.
+// Each base has a sequence of its own, so replays don't depend on the order concurrent workers ask in.
+// Answers take as long as the recorded calls did, so deadlines of the caller expire the way they did
+// (or didn't) back then
+type Player struct {
+	mu     sync.Mutex
+	byBase map[string][]Interaction
+}
+
+func (p *Player) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ExchangeRates{}, err
+	}
+
+	p.mu.Lock()
+	queue := p.byBase[base]
+	if len(queue) == 0 {
+		p.mu.Unlock()
+		return domain.ExchangeRates{}, fmt.Errorf("%w for currency %q", ErrExhausted, base)
+	}
+	interaction := queue[0]
+	p.byBase[base] = queue[1:]
+	p.mu.Unlock()
+
+	if interaction.DurationMs > 0 {
+		timer := time.NewTimer(time.Duration(interaction.DurationMs) * time.Millisecond)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return domain.ExchangeRates{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if interaction.Response == nil {
+		return domain.ExchangeRates{}, replayError(interaction)
+	}
+	res := interaction.Response
+	return domain.ExchangeRates{Provider: res.Provider, Rates: res.Rates, Quotes: res.Quotes}, nil
+}
+
+// NewPlayer loads the cassette at path
+func NewPlayer(path string) (*Player, error) {
+	interactions, err := readInteractions(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Player{byBase: make(map[string][]Interaction)}
+	for _, i := range interactions {
+		p.byBase[i.Base] = append(p.byBase[i.Base], i)
+	}
+	return p, nil
+}
+
+func readInteractions(path string) ([]Interaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	interactions := make([]Interaction, 0, 64)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // conversion tables of a base make long lines
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var i Interaction
+		if err = json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s line %d: %w", path, line, err)
+		}
+		if (i.Response == nil) == (i.Error == "") {
+			return nil, fmt.Errorf("failed to parse cassette %s line %d: either response or error is expected", path, line)
+		}
+		interactions = append(interactions, i)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+	return interactions, nil
+}
@@ -0,0 +1,198 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedClient answers each base with its scripted results in order
+type scriptedClient struct {
+	mu      sync.Mutex
+	answers map[string][]func() (domain.ExchangeRates, error)
+}
+
+func (c *scriptedClient) GetExchangeRates(_ context.Context, base string) (domain.ExchangeRates, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	answer := c.answers[base][0]
+	c.answers[base] = c.answers[base][1:]
+	return answer()
+}
+
+func ratesOf(provider string, rates map[string]string) func() (domain.ExchangeRates, error) {
+	return func() (domain.ExchangeRates, error) {
+		res := domain.ExchangeRates{Provider: provider, Rates: make(map[string]decimal.Decimal, len(rates))}
+		for quote, v := range rates {
+			res.Rates[quote] = decimal.RequireFromString(v)
+		}
+		return res, nil
+	}
+}
+
+func TestRecorderThenPlayer_ReplaysEachBaseInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incident.jsonl")
+	consensus := func() (domain.ExchangeRates, error) {
+		return domain.ExchangeRates{
+			Provider: "consensus",
+			Rates:    map[string]decimal.Decimal{"USD": decimal.RequireFromString("1.08")},
+			Quotes: map[string][]domain.ProviderQuote{"USD": {
+				{Provider: "frankfurter", Value: decimal.RequireFromString("1.08"), Accepted: true},
+				{Provider: "open_er_api", Value: decimal.RequireFromString("1.5"), Accepted: false},
+			}},
+		}, nil
+	}
+	upstream := &scriptedClient{answers: map[string][]func() (domain.ExchangeRates, error){
+		"USD": {
+			ratesOf("exchangerate_api", map[string]string{"EUR": "0.92", "GBP": "0.79"}),
+			func() (domain.ExchangeRates, error) {
+				return domain.ExchangeRates{}, errors.New("unexpected status code 503")
+			},
+			ratesOf("exchangerate_api", map[string]string{"EUR": "0.93"}), // partial result
+		},
+		"EUR": {consensus},
+	}}
+
+	recorder, err := NewRecorder(upstream, path)
+	require.NoError(t, err)
+	ctx := context.Background()
+	var recorded []string
+	for _, base := range []string{"USD", "EUR", "USD", "USD"} {
+		res, callErr := recorder.GetExchangeRates(ctx, base)
+		recorded = append(recorded, fmt.Sprint(res, callErr))
+	}
+	require.NoError(t, recorder.Close())
+
+	player, err := NewPlayer(path)
+	require.NoError(t, err)
+	// bases are asked in another order than recorded, each base keeps its own sequence
+	var replayed []string
+	for _, base := range []string{"EUR", "USD", "USD", "USD"} {
+		res, callErr := player.GetExchangeRates(ctx, base)
+		replayed = append(replayed, fmt.Sprint(res, callErr))
+	}
+	require.Equal(t, []string{recorded[1], recorded[0], recorded[2], recorded[3]}, replayed)
+	require.Contains(t, replayed[2], "unexpected status code 503")
+
+	_, err = player.GetExchangeRates(ctx, "USD")
+	require.ErrorIs(t, err, ErrExhausted)
+	_, err = player.GetExchangeRates(ctx, "JPY")
+	require.ErrorIs(t, err, ErrExhausted)
+}
+
+func TestRecorder_AppendsToExistingCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents", "cassette.jsonl")
+	upstream := &scriptedClient{answers: map[string][]func() (domain.ExchangeRates, error){
+		"USD": {ratesOf("frankfurter", map[string]string{"EUR": "0.92"}), ratesOf("frankfurter", map[string]string{"EUR": "0.94"})},
+	}}
+
+	for range 2 {
+		recorder, err := NewRecorder(upstream, path)
+		require.NoError(t, err)
+		_, err = recorder.GetExchangeRates(context.Background(), "USD")
+		require.NoError(t, err)
+		require.NoError(t, recorder.Close())
+	}
+
+	interactions, err := readInteractions(path)
+	require.NoError(t, err)
+	require.Len(t, interactions, 2)
+	require.Equal(t, 1, interactions[0].Seq)
+	require.Equal(t, 2, interactions[1].Seq)
+	require.Equal(t, "0.94", interactions[1].Response.Rates["EUR"].String())
+	require.False(t, interactions[1].RecordedAt.IsZero())
+}
+
+func TestNewPlayer_InvalidCassette(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"garbage.jsonl": "{\"seq\":1,\"base\":\"USD\",\"error\":\"boom\"}\nnot json\n",
+		"empty.jsonl":   `{"seq":1,"base":"USD"}`,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := NewPlayer(path)
+		require.Error(t, err)
+		require.True(t, strings.Contains(err.Error(), "failed to parse cassette"), err.Error())
+	}
+
+	_, err := NewPlayer(filepath.Join(dir, "missing.jsonl"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestPlayer_ReplaysErrorKinds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "errors.jsonl")
+	failing := func(err error) func() (domain.ExchangeRates, error) {
+		return func() (domain.ExchangeRates, error) { return domain.ExchangeRates{}, err }
+	}
+	upstream := &scriptedClient{answers: map[string][]func() (domain.ExchangeRates, error){
+		"USD": {
+			failing(fmt.Errorf("status 401: %w", domain.ErrUpstreamAuth)),
+			failing(errors.Join(domain.ErrUpstreamQuota, domain.ErrUpstreamUnavailable)),
+			failing(fmt.Errorf("unsupported-code: %w", domain.ErrUnsupportedCurrency)),
+			failing(fmt.Errorf("status 503: %w", domain.ErrUpstreamUnavailable)),
+			failing(fmt.Errorf("failed to do request: %w", context.DeadlineExceeded)),
+			failing(errors.New("boom")),
+		},
+	}}
+	recorder, err := NewRecorder(upstream, path)
+	require.NoError(t, err)
+	for range 6 {
+		_, _ = recorder.GetExchangeRates(context.Background(), "USD")
+	}
+	require.NoError(t, recorder.Close())
+
+	player, err := NewPlayer(path)
+	require.NoError(t, err)
+	replay := func() error {
+		_, replayErr := player.GetExchangeRates(context.Background(), "USD")
+		require.Error(t, replayErr)
+		return replayErr
+	}
+	err = replay()
+	require.ErrorIs(t, err, domain.ErrUpstreamAuth)
+	require.Equal(t, "status 401: "+domain.ErrUpstreamAuth.Error(), err.Error())
+	err = replay()
+	require.ErrorIs(t, err, domain.ErrUpstreamQuota)
+	require.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	require.ErrorIs(t, replay(), domain.ErrUnsupportedCurrency)
+	err = replay()
+	require.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	require.NotErrorIs(t, err, domain.ErrUpstreamQuota)
+	require.ErrorIs(t, replay(), context.DeadlineExceeded)
+	err = replay()
+	require.EqualError(t, err, "boom")
+	require.NotErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestPlayer_ReplaysDurations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.jsonl")
+	content := `{"seq":1,"base":"USD","duration_ms":200,"response":{"provider":"frankfurter","rates":{"EUR":"0.92"}}}` + "\n" +
+		`{"seq":2,"base":"USD","duration_ms":20,"response":{"provider":"frankfurter","rates":{"EUR":"0.93"}}}` + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	player, err := NewPlayer(path)
+	require.NoError(t, err)
+
+	// the recorded call took longer than the deadline allows
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = player.GetExchangeRates(ctx, "USD")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	start := time.Now()
+	res, err := player.GetExchangeRates(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "0.93", res.Rates["EUR"].String())
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
@@ -0,0 +1,156 @@
+package composite
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"slices"
+	"sync"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+const ConsensusProvider = "consensus"
+
+type ConsensusMethod string
+
+const (
+	ConsensusMedian ConsensusMethod = "median"
+	// ConsensusTrimmedMean averages accepted values after dropping a quarter of them from each end
+	ConsensusTrimmedMean ConsensusMethod = "trimmed_mean"
+)
+
+type ConsensusOptions struct {
+	Method ConsensusMethod
+	// Tolerance is the max relative deviation from the median for a value to be accepted, e.g. 0.005 for 0.5%
+	Tolerance float64
+	// MinProviders is the least number of accepted values needed to publish a quote
+	MinProviders int
+}
+
+// ConsensusRateClient asks all providers for the same base and publishes only the quotes they agree on.
+// Values deviating from the median more than the tolerance are rejected, but kept in the result for audit
+type ConsensusRateClient struct {
+	providers []adapters.RateClient
+	opts      ConsensusOptions
+}
+
+func (c *ConsensusRateClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	// STEP 1: asking all providers concurrently, results keep the configured providers order
+	results := make([]domain.ExchangeRates, len(c.providers))
+	errs := make([]error, len(c.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range c.providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = provider.GetExchangeRates(ctx, base)
+		}()
+	}
+	wg.Wait()
+
+	// STEP 2: grouping values of answered providers by quote:
+	// {
+	//		"EUR": [{Provider: "frankfurter", Value: 0.92}, {Provider: "open_er_api", Value: 0.921}],
+	//		...
+	// }
+	quotes := make(map[string][]domain.ProviderQuote)
+	answered := 0
+	for i, res := range results {
+		if errs[i] != nil {
+			logrus.Warnf("Provider #%d failed for base '%s' and was left out of consensus: %s", i+1, base, errs[i])
+			continue
+		}
+		if len(res.Rates) == 0 {
+			continue
+		}
+		answered++
+		for quote, v := range res.Rates {
+			if !v.IsPositive() {
+				continue
+			}
+			quotes[quote] = append(quotes[quote], domain.ProviderQuote{Provider: res.Provider, Value: v})
+		}
+	}
+	if answered < c.opts.MinProviders {
+		return domain.ExchangeRates{}, fmt.Errorf(
+			"only %d of %d rate providers answered for currency %q, %d required: %w",
+			answered, len(c.providers), base, c.opts.MinProviders, combine(errs),
+		)
+	}
+
+	// STEP 3: agreeing on each quote, quotes without consensus are skipped
+	res := domain.ExchangeRates{
+		Provider: ConsensusProvider,
+		Rates:    make(map[string]decimal.Decimal, len(quotes)),
+		Quotes:   make(map[string][]domain.ProviderQuote, len(quotes)),
+	}
+	for quote, values := range quotes {
+		v, ok := c.agree(values)
+		if !ok {
+			logrus.Warnf("Providers didn't agree on '%s/%s', it'll be processed next time", base, quote)
+			continue
+		}
+		res.Rates[quote] = v
+		res.Quotes[quote] = values
+	}
+	if len(res.Rates) == 0 {
+		return domain.ExchangeRates{}, fmt.Errorf("rate providers reached no consensus for currency %q", base)
+	}
+	return res, nil
+}
+
+// agree marks values close enough to the median as accepted and aggregates them with the configured method
+func (c *ConsensusRateClient) agree(quotes []domain.ProviderQuote) (decimal.Decimal, bool) {
+	values := make([]decimal.Decimal, 0, len(quotes))
+	for _, q := range quotes {
+		values = append(values, q.Value)
+	}
+	mid := median(values)
+	maxDeviation := mid.Mul(decimal.NewFromFloat(c.opts.Tolerance))
+
+	accepted := make([]decimal.Decimal, 0, len(quotes))
+	for i := range quotes {
+		if quotes[i].Value.Sub(mid).Abs().LessThanOrEqual(maxDeviation) {
+			quotes[i].Accepted = true
+			accepted = append(accepted, quotes[i].Value)
+		}
+	}
+	if len(accepted) == 0 || len(accepted) < c.opts.MinProviders {
+		return decimal.Zero, false
+	}
+
+	if c.opts.Method == ConsensusTrimmedMean {
+		return trimmedMean(accepted), true
+	}
+	return median(accepted), true
+}
+
+func median(values []decimal.Decimal) decimal.Decimal {
+	sorted := sortedDecimals(values)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return sorted[n/2-1].Add(sorted[n/2]).DivRound(decimal.NewFromInt(2), domain.RateScale)
+}
+
+func trimmedMean(values []decimal.Decimal) decimal.Decimal {
+	sorted := sortedDecimals(values)
+	trim := len(sorted) / 4
+	kept := sorted[trim : len(sorted)-trim]
+	return decimal.Sum(kept[0], kept[1:]...).DivRound(decimal.NewFromInt(int64(len(kept))), domain.RateScale)
+}
+
+func sortedDecimals(values []decimal.Decimal) []decimal.Decimal {
+	sorted := slices.Clone(values)
+	slices.SortFunc(sorted, func(a, b decimal.Decimal) int { return a.Cmp(b) })
+	return sorted
+}
+
+func NewConsensusRateClient(opts ConsensusOptions, providers ...adapters.RateClient) *ConsensusRateClient {
+	return &ConsensusRateClient{providers: providers, opts: opts}
+}
@@ -0,0 +1,148 @@
+package composite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fxrates/internal/adapters"
+	"fxrates/internal/adapters/httpclient"
+	"fxrates/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProviders starts httptest servers speaking each provider's wire format and returns adapters pointed at them
+func fakeProviders(t *testing.T, exchangeRateBody, openERAPIBody, frankfurterBody string) []adapters.RateClient {
+	t.Helper()
+	serve := func(body string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if body == "" {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	exchangeRate, openERAPI, frankfurter := serve(exchangeRateBody), serve(openERAPIBody), serve(frankfurterBody)
+	return []adapters.RateClient{
+		httpclient.NewExchangeRateClient(exchangeRate.Client(), exchangeRate.URL+"/key/latest"),
+		httpclient.NewOpenERAPIClient(openERAPI.Client(), openERAPI.URL),
+		httpclient.NewFrankfurterClient(frankfurter.Client(), frankfurter.URL),
+	}
+}
+
+func TestConsensusRateClient_Median_RejectsOutlier(t *testing.T) {
+	providers := fakeProviders(t,
+		`{"result":"success","base_code":"USD","conversion_rates":{"EUR":0.9200,"GBP":0.79}}`,
+		`{"result":"success","base_code":"USD","rates":{"EUR":0.9210,"GBP":0.80}}`,
+		`{"base":"USD","rates":{"EUR":1.0500,"GBP":0.795}}`,
+	)
+	c := NewConsensusRateClient(ConsensusOptions{Method: ConsensusMedian, Tolerance: 0.01, MinProviders: 2}, providers...)
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, ConsensusProvider, res.Provider)
+	require.Equal(t, "0.9205", res.Rates["EUR"].String()) // median of the two accepted values
+	require.Equal(t, "0.795", res.Rates["GBP"].String())
+
+	expected := []struct {
+		provider string
+		value    string
+		accepted bool
+	}{
+		{httpclient.ExchangeRateProvider, "0.92", true},
+		{httpclient.OpenERAPIProvider, "0.921", true},
+		{httpclient.FrankfurterProvider, "1.05", false},
+	}
+	require.Len(t, res.Quotes["EUR"], len(expected))
+	for i, q := range res.Quotes["EUR"] {
+		require.Equal(t, expected[i].provider, q.Provider)
+		require.Equal(t, expected[i].value, q.Value.String())
+		require.Equal(t, expected[i].accepted, q.Accepted)
+	}
+}
+
+func TestConsensusRateClient_TrimmedMean(t *testing.T) {
+	providers := fakeProviders(t,
+		`{"result":"success","base_code":"USD","conversion_rates":{"EUR":0.91}}`,
+		`{"result":"success","base_code":"USD","rates":{"EUR":0.92}}`,
+		`{"base":"USD","rates":{"EUR":0.96}}`,
+	)
+	c := NewConsensusRateClient(ConsensusOptions{Method: ConsensusTrimmedMean, Tolerance: 0.05, MinProviders: 2}, providers...)
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "0.93", res.Rates["EUR"].String()) // (0.91 + 0.92 + 0.96) / 3
+	for _, q := range res.Quotes["EUR"] {
+		require.True(t, q.Accepted, q.Provider)
+	}
+}
+
+func TestConsensusRateClient_SkipsQuotesWithoutAgreement(t *testing.T) {
+	providers := fakeProviders(t,
+		`{"result":"success","base_code":"USD","conversion_rates":{"EUR":0.92,"JPY":150}}`,
+		`{"result":"success","base_code":"USD","rates":{"EUR":0.92,"JPY":160}}`,
+		"", // unavailable
+	)
+	c := NewConsensusRateClient(ConsensusOptions{Method: ConsensusMedian, Tolerance: 0.01, MinProviders: 2}, providers...)
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "0.92", res.Rates["EUR"].String())
+	require.NotContains(t, res.Rates, "JPY")
+	require.NotContains(t, res.Quotes, "JPY")
+}
+
+func TestConsensusRateClient_NotEnoughProviders(t *testing.T) {
+	providers := fakeProviders(t,
+		`{"result":"success","base_code":"USD","conversion_rates":{"EUR":0.92}}`,
+		"",
+		"",
+	)
+	c := NewConsensusRateClient(ConsensusOptions{Method: ConsensusMedian, Tolerance: 0.01, MinProviders: 2}, providers...)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "only 1 of 3 rate providers answered")
+	require.Contains(t, err.Error(), "unexpected status code 503")
+}
+
+func TestConsensusRateClient_SingleProviderRejectingKey_DoesNotFailAsAuth(t *testing.T) {
+	providers := fakeProviders(t,
+		`{"result":"error","error-type":"invalid-key"}`,
+		`{"result":"success","base_code":"USD","rates":{"EUR":0.92}}`,
+		"",
+	)
+	c := NewConsensusRateClient(ConsensusOptions{Method: ConsensusMedian, Tolerance: 0.01, MinProviders: 2}, providers...)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid-key")
+	require.NotErrorIs(t, err, domain.ErrUpstreamAuth)
+	require.NotErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestConsensusRateClient_NoConsensus(t *testing.T) {
+	providers := fakeProviders(t,
+		`{"result":"success","base_code":"USD","conversion_rates":{"EUR":0.80}}`,
+		`{"result":"success","base_code":"USD","rates":{"EUR":0.92}}`,
+		"",
+	)
+	c := NewConsensusRateClient(ConsensusOptions{Method: ConsensusMedian, Tolerance: 0.01, MinProviders: 2}, providers...)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no consensus")
+}
@@ -0,0 +1,55 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"fxrates/internal/domain"
+)
+
+// agreeable are the errors a combined failure matches only when every provider failed with them. A key rejected
+// or a quota used up by a single provider shouldn't stop the run, nor should its unsupported code fail the update
+var agreeable = []error{
+	domain.ErrUpstreamAuth,
+	domain.ErrUpstreamQuota,
+	domain.ErrUnsupportedCurrency,
+	domain.ErrUpstreamUnavailable,
+	context.Canceled,
+	context.DeadlineExceeded,
+}
+
+// providersError keeps the messages of all provider failures and matches the errors all of them agree on
+type providersError struct {
+	joined error
+	agreed []error
+}
+
+func (e *providersError) Error() string {
+	return e.joined.Error()
+}
+
+func (e *providersError) Unwrap() []error {
+	return e.agreed
+}
+
+// combine joins failures of providers, a nil one is a provider which answered and so agrees with no failure.
+// It's nil when no provider failed, as errors.Join is
+func combine(errs []error) error {
+	joined := errors.Join(errs...)
+	if joined == nil {
+		return nil
+	}
+	agreed := make([]error, 0, len(agreeable))
+	for _, target := range agreeable {
+		all := true
+		for _, err := range errs {
+			if !errors.Is(err, target) {
+				all = false
+				break
+			}
+		}
+		if all {
+			agreed = append(agreed, target)
+		}
+	}
+	return &providersError{joined: joined, agreed: agreed}
+}
@@ -0,0 +1,60 @@
+package composite
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FailoverRateClient asks providers in the configured order and returns the first successful result.
+// The served result keeps the name of the provider it came from
+type FailoverRateClient struct {
+	providers []adapters.RateClient
+	// providerTimeout bounds every provider call, so a hanging provider leaves time for the next ones
+	providerTimeout time.Duration
+}
+
+// WithProviderTimeout bounds every provider call by timeout, non-positive leaves them bound by the caller ctx only
+func (c *FailoverRateClient) WithProviderTimeout(timeout time.Duration) *FailoverRateClient {
+	c.providerTimeout = timeout
+	return c
+}
+
+func (c *FailoverRateClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	errs := make([]error, 0, len(c.providers))
+	for i, provider := range c.providers {
+		res, err := c.callProvider(ctx, provider, base)
+		if err == nil && len(res.Rates) > 0 {
+			if i > 0 {
+				logrus.Infof("Base '%s' was served by fallback provider '%s'", base, res.Provider)
+			}
+			return res, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("provider #%d returned no rates for currency %q", i+1, base)
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			// no time left to ask the remaining providers
+			break
+		}
+	}
+	return domain.ExchangeRates{}, fmt.Errorf("all rate providers failed for currency %q: %w", base, combine(errs))
+}
+
+func (c *FailoverRateClient) callProvider(ctx context.Context, provider adapters.RateClient, base string) (domain.ExchangeRates, error) {
+	if c.providerTimeout <= 0 {
+		return provider.GetExchangeRates(ctx, base)
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.providerTimeout)
+	defer cancel()
+	return provider.GetExchangeRates(ctx, base)
+}
+
+func NewFailoverRateClient(providers ...adapters.RateClient) *FailoverRateClient {
+	return &FailoverRateClient{providers: providers}
+}
@@ -0,0 +1,114 @@
+package composite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"fxrates/internal/domain"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRateClient struct{ mock.Mock }
+
+func (m *MockRateClient) GetExchangeRates(ctx context.Context, code string) (domain.ExchangeRates, error) {
+	args := m.Called(ctx, code)
+	rates, _ := args.Get(0).(domain.ExchangeRates)
+	return rates, args.Error(1)
+}
+
+func TestFailoverRateClient_FirstProviderServes(t *testing.T) {
+	first, second := new(MockRateClient), new(MockRateClient)
+	first.On("GetExchangeRates", mock.Anything, "USD").
+		Return(domain.ExchangeRates{Provider: "first", Rates: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.9")}}, nil).Once()
+
+	res, err := NewFailoverRateClient(first, second).GetExchangeRates(context.Background(), "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "first", res.Provider)
+	second.AssertNotCalled(t, "GetExchangeRates", mock.Anything, mock.Anything)
+	first.AssertExpectations(t)
+}
+
+func TestFailoverRateClient_FallsBackInOrder(t *testing.T) {
+	first, second, third := new(MockRateClient), new(MockRateClient), new(MockRateClient)
+	first.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errors.New("503")).Once()
+	second.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "second"}, nil).Once() // empty table
+	third.On("GetExchangeRates", mock.Anything, "USD").
+		Return(domain.ExchangeRates{Provider: "third", Rates: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.91")}}, nil).Once()
+
+	res, err := NewFailoverRateClient(first, second, third).GetExchangeRates(context.Background(), "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "third", res.Provider)
+	require.Equal(t, "0.91", res.Rates["EUR"].String())
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+	third.AssertExpectations(t)
+}
+
+func TestFailoverRateClient_AllFail_MatchesOnlyErrorsAllAgreeOn(t *testing.T) {
+	authErr := fmt.Errorf("invalid-key: %w", domain.ErrUpstreamAuth)
+	quotaErr := fmt.Errorf("quota-reached: %w", errors.Join(domain.ErrUpstreamQuota, domain.ErrUpstreamUnavailable))
+	outageErr := fmt.Errorf("status 503: %w", domain.ErrUpstreamUnavailable)
+	fail := func(firstErr, secondErr error) error {
+		first, second := new(MockRateClient), new(MockRateClient)
+		first.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, firstErr).Once()
+		second.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, secondErr).Once()
+		_, err := NewFailoverRateClient(first, second).GetExchangeRates(context.Background(), "USD")
+		require.Error(t, err)
+		return err
+	}
+
+	// the key of a single provider was rejected, the run goes on
+	err := fail(authErr, outageErr)
+	require.NotErrorIs(t, err, domain.ErrUpstreamAuth)
+	require.NotErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	require.Contains(t, err.Error(), "all rate providers failed for currency \"USD\"")
+	require.Contains(t, err.Error(), authErr.Error())
+	require.Contains(t, err.Error(), outageErr.Error())
+
+	err = fail(quotaErr, outageErr)
+	require.NotErrorIs(t, err, domain.ErrUpstreamQuota)
+	require.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+
+	err = fail(quotaErr, quotaErr)
+	require.ErrorIs(t, err, domain.ErrUpstreamQuota)
+	require.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestFailoverRateClient_StopsOnCanceledContext(t *testing.T) {
+	first, second := new(MockRateClient), new(MockRateClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	first.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, context.Canceled).Run(func(mock.Arguments) {
+		cancel()
+	}).Once()
+
+	_, err := NewFailoverRateClient(first, second).GetExchangeRates(ctx, "USD")
+
+	require.ErrorIs(t, err, context.Canceled)
+	second.AssertNotCalled(t, "GetExchangeRates", mock.Anything, mock.Anything)
+}
+
+func TestFailoverRateClient_HangingProvider_LeavesTimeForFallback(t *testing.T) {
+	first, second := new(MockRateClient), new(MockRateClient)
+	first.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, context.DeadlineExceeded).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Once()
+	second.On("GetExchangeRates", mock.Anything, "USD").
+		Return(domain.ExchangeRates{Provider: "second", Rates: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.91")}}, nil).Once()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	res, err := NewFailoverRateClient(first, second).WithProviderTimeout(20*time.Millisecond).GetExchangeRates(ctx, "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "second", res.Provider)
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
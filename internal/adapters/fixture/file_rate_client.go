@@ -0,0 +1,233 @@
+// Package fixture serves rates from local files, so the stack runs offline in development, demos and CI
+package fixture
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const FileProvider = "file"
+
+// FileRateClient serves conversion tables from a directory of fixtures named after their base currency,
+// USD.json or USD.csv. Bases without a fixture are cross-computed through another fixture quoting them,
+// so a single USD.json is enough for every currency it lists. Fixtures are read on every call,
+// edits are picked up without a restart
+//
+// JSON fixtures map quotes to rates, an optional drift makes a rate swing around its value over time:
+//
+//	{"rates": {"EUR": 0.92, "GBP": "0.79"}, "drift": {"EUR": {"amplitude": 0.01, "period": "1h"}}}
+//
+// CSV fixtures have a header and the same optional drift columns:
+//
+//	quote,rate,amplitude,period
+//	EUR,0.92,0.01,1h
+//	GBP,0.79
+type FileRateClient struct {
+	dir string
+	now func() time.Time
+}
+
+// drift swings a rate by up to Amplitude (relative, 0.01 is ±1%) along a sine wave with the period.
+// The wave is anchored to the Unix epoch, so every instance serves the same value at the same time
+type drift struct {
+	Amplitude float64  `json:"amplitude"`
+	Period    duration `json:"period"`
+}
+
+// duration is a time.Duration written as a string like "90s" or "1h" in fixtures
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"1h\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+type fileFixture struct {
+	Rates map[string]decimal.Decimal `json:"rates"`
+	Drift map[string]drift           `json:"drift"`
+}
+
+func (c *FileRateClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.ExchangeRates{}, err
+	}
+
+	fixture, err := c.read(base)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c.crossRates(base)
+	}
+	if err != nil {
+		return domain.ExchangeRates{}, err
+	}
+	if len(fixture.Rates) == 0 {
+		return domain.ExchangeRates{}, fmt.Errorf("fixture returned no rates for currency %q", base)
+	}
+	return domain.ExchangeRates{Provider: FileProvider, Rates: fixture.at(c.now())}, nil
+}
+
+// crossRates derives rates of the base from the first fixture (by name) quoting it
+func (c *FileRateClient) crossRates(base string) (domain.ExchangeRates, error) {
+	codes, err := c.fixtureCodes()
+	if err != nil {
+		return domain.ExchangeRates{}, err
+	}
+	for _, code := range codes {
+		fixture, readErr := c.read(code)
+		if readErr != nil {
+			return domain.ExchangeRates{}, readErr
+		}
+		rates := fixture.at(c.now())
+		via, ok := rates[base]
+		if !ok || !via.IsPositive() {
+			continue
+		}
+
+		cross := make(map[string]decimal.Decimal, len(rates))
+		cross[code] = domain.InverseRate(via)
+		for quote, v := range rates {
+			if quote != base {
+				cross[quote] = v.DivRound(via, domain.RateScale)
+			}
+		}
+		return domain.ExchangeRates{Provider: FileProvider, Rates: cross}, nil
+	}
+	return domain.ExchangeRates{}, fmt.Errorf("no fixture quotes currency %q", base)
+}
+
+// read parses the fixture of the base, fs.ErrNotExist is returned when there's none
+func (c *FileRateClient) read(base string) (fileFixture, error) {
+	for _, ext := range []string{".json", ".csv"} {
+		path := filepath.Join(c.dir, base+ext)
+		f, err := os.Open(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fileFixture{}, fmt.Errorf("failed to open fixture of currency %q: %w", base, err)
+		}
+
+		var fixture fileFixture
+		if ext == ".json" {
+			err = json.NewDecoder(f).Decode(&fixture)
+		} else {
+			fixture, err = parseCSV(f)
+		}
+		_ = f.Close()
+		if err != nil {
+			return fileFixture{}, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+		return fixture, nil
+	}
+	return fileFixture{}, fs.ErrNotExist
+}
+
+// fixtureCodes lists bases having a fixture, sorted
+func (c *FileRateClient) fixtureCodes() ([]string, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	codes := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".json" && ext != ".csv") {
+			continue
+		}
+		if code := strings.TrimSuffix(e.Name(), ext); !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+	return codes, nil
+}
+
+func parseCSV(r io.Reader) (fileFixture, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fileFixture{}, err
+	}
+	if len(records) == 0 || len(records[0]) < 2 || records[0][0] != "quote" || records[0][1] != "rate" {
+		return fileFixture{}, errors.New(`header "quote,rate[,amplitude,period]" is expected`)
+	}
+
+	fixture := fileFixture{Rates: make(map[string]decimal.Decimal, len(records)-1), Drift: make(map[string]drift)}
+	for i, rec := range records[1:] {
+		line := i + 2
+		if len(rec) != 2 && len(rec) != 4 {
+			return fileFixture{}, fmt.Errorf("line %d: 2 or 4 fields are expected, got %d", line, len(rec))
+		}
+		rate, parseErr := decimal.NewFromString(rec[1])
+		if parseErr != nil {
+			return fileFixture{}, fmt.Errorf("line %d: invalid rate %q", line, rec[1])
+		}
+		fixture.Rates[rec[0]] = rate
+		if len(rec) == 4 {
+			var d drift
+			if d.Amplitude, parseErr = strconv.ParseFloat(rec[2], 64); parseErr != nil {
+				return fileFixture{}, fmt.Errorf("line %d: invalid amplitude %q", line, rec[2])
+			}
+			period, periodErr := time.ParseDuration(rec[3])
+			if periodErr != nil {
+				return fileFixture{}, fmt.Errorf("line %d: invalid period %q", line, rec[3])
+			}
+			d.Period = duration(period)
+			fixture.Drift[rec[0]] = d
+		}
+	}
+	return fixture, nil
+}
+
+// at returns the rates drifted to the given time, rounded to domain.RateScale digits
+func (f fileFixture) at(t time.Time) map[string]decimal.Decimal {
+	rates := make(map[string]decimal.Decimal, len(f.Rates))
+	for quote, v := range f.Rates {
+		d, ok := f.Drift[quote]
+		if !ok || d.Amplitude == 0 || d.Period <= 0 {
+			rates[quote] = v
+			continue
+		}
+		phase := float64(t.UnixNano()%int64(d.Period)) / float64(d.Period)
+		factor := decimal.NewFromFloat(1 + d.Amplitude*math.Sin(2*math.Pi*phase))
+		rates[quote] = v.Mul(factor).Round(domain.RateScale)
+	}
+	return rates
+}
+
+// NewFileRateClient creates a client of the fixtures in dir, failing when there are none
+func NewFileRateClient(dir string) (*FileRateClient, error) {
+	c := &FileRateClient{dir: dir, now: time.Now}
+	codes, err := c.fixtureCodes()
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no .json or .csv fixtures in %q", dir)
+	}
+	return c, nil
+}
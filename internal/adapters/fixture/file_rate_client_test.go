@@ -0,0 +1,105 @@
+package fixture
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFixtures(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestFileRateClient_JSON(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{"USD.json": `{"rates": {"EUR": 0.92, "GBP": "0.79"}}`})
+	c, err := NewFileRateClient(dir)
+	require.NoError(t, err)
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, FileProvider, res.Provider)
+	require.Len(t, res.Rates, 2)
+	require.Equal(t, "0.92", res.Rates["EUR"].String())
+	require.Equal(t, "0.79", res.Rates["GBP"].String())
+}
+
+func TestFileRateClient_CSV_WithDrift(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{"EUR.csv": "quote,rate,amplitude,period\nUSD,1.1,0.01,1h\nGBP,0.85\n"})
+	c, err := NewFileRateClient(dir)
+	require.NoError(t, err)
+
+	// a quarter of the period is the top of the wave, three quarters the bottom
+	c.now = func() time.Time { return time.Unix(15*60, 0) }
+	res, err := c.GetExchangeRates(context.Background(), "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "1.111", res.Rates["USD"].String())
+	require.Equal(t, "0.85", res.Rates["GBP"].String())
+
+	c.now = func() time.Time { return time.Unix(45*60, 0) }
+	res, err = c.GetExchangeRates(context.Background(), "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "1.089", res.Rates["USD"].String())
+}
+
+func TestFileRateClient_CrossRatesOfBaseWithoutFixture(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{"USD.json": `{"rates": {"EUR": 0.8, "GBP": 0.5}}`})
+	c, err := NewFileRateClient(dir)
+	require.NoError(t, err)
+
+	res, err := c.GetExchangeRates(context.Background(), "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "1.25", res.Rates["USD"].String())
+	require.Equal(t, "0.625", res.Rates["GBP"].String())
+	require.NotContains(t, res.Rates, "EUR")
+
+	_, err = c.GetExchangeRates(context.Background(), "JPY")
+	require.EqualError(t, err, `no fixture quotes currency "JPY"`)
+}
+
+func TestFileRateClient_EditsArePickedUp(t *testing.T) {
+	dir := writeFixtures(t, map[string]string{"USD.json": `{"rates": {"EUR": 0.92}}`})
+	c, err := NewFileRateClient(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "USD.json"), []byte(`{"rates": {"EUR": 0.95}}`), 0o600))
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "0.95", res.Rates["EUR"].String())
+}
+
+func TestFileRateClient_InvalidFixtures(t *testing.T) {
+	for name, content := range map[string]string{
+		"USD.json": `{"rates": {"EUR": "abc"}}`,
+		"USD.csv":  "quote,rate\nEUR,0.9,0.01\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewFileRateClient(writeFixtures(t, map[string]string{name: content}))
+			require.NoError(t, err)
+
+			_, err = c.GetExchangeRates(context.Background(), "USD")
+			require.ErrorContains(t, err, "failed to parse fixture")
+		})
+	}
+
+	c, err := NewFileRateClient(writeFixtures(t, map[string]string{"USD.json": `{"rates": {}}`}))
+	require.NoError(t, err)
+	_, err = c.GetExchangeRates(context.Background(), "USD")
+	require.EqualError(t, err, `fixture returned no rates for currency "USD"`)
+}
+
+func TestNewFileRateClient_NoFixtures(t *testing.T) {
+	_, err := NewFileRateClient(writeFixtures(t, map[string]string{"README.md": "rates"}))
+	require.ErrorContains(t, err, "no .json or .csv fixtures")
+
+	_, err = NewFileRateClient(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to read fixtures")
+}
@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+	"net/http"
+)
+
+// maxErrorBodySize caps how much of a non-2xx body is read looking for an error-type
+const maxErrorBodySize = 64 << 10
+
+// Error types of ExchangeRate-API and Open ER-API failures, see https://www.exchangerate-api.com/docs/standard-requests
+const (
+	ErrorTypeInvalidKey      = "invalid-key"
+	ErrorTypeInactiveAccount = "inactive-account"
+	ErrorTypeQuotaReached    = "quota-reached"
+	ErrorTypeUnsupportedCode = "unsupported-code"
+	ErrorTypeMalformed       = "malformed-request"
+)
+
+// APIError is a failure the provider explained with its error-type. It matches domain.ErrUpstreamAuth,
+// domain.ErrUpstreamQuota (and domain.ErrUpstreamUnavailable) or domain.ErrUnsupportedCurrency by the type
+type APIError struct {
+	Base   string
+	Result string
+	Type   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api returned non-success result for currency %q: %s (%s)", e.Base, e.Result, e.Type)
+}
+
+func (e *APIError) Unwrap() []error {
+	switch e.Type {
+	case ErrorTypeInvalidKey, ErrorTypeInactiveAccount:
+		return []error{domain.ErrUpstreamAuth}
+	case ErrorTypeQuotaReached:
+		return []error{domain.ErrUpstreamQuota, domain.ErrUpstreamUnavailable}
+	case ErrorTypeUnsupportedCode:
+		return []error{domain.ErrUnsupportedCurrency}
+	default:
+		return nil
+	}
+}
+
+// resultFailed turns a non-success result into an APIError, results without an error-type stay plain errors
+func resultFailed(base, result, errorType string) error {
+	if errorType == "" {
+		return fmt.Errorf("api returned non-success result for currency %q: %s", base, result)
+	}
+	return &APIError{Base: base, Result: result, Type: errorType}
+}
+
+// unavailableError marks err as domain.ErrUpstreamUnavailable keeping its message
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unavailableError) Unwrap() []error {
+	return []error{e.err, domain.ErrUpstreamUnavailable}
+}
+
+func unavailable(err error) error {
+	return &unavailableError{err: err}
+}
+
+// requestFailed classifies a failed request, everything but the caller giving up may pass on a later attempt
+func requestFailed(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+	return unavailable(err)
+}
+
+// statusFailed classifies a non-2xx answer, server errors and throttling may pass on a later attempt
+func statusFailed(code int, err error) error {
+	if code >= http.StatusInternalServerError || code == http.StatusTooManyRequests {
+		return unavailable(err)
+	}
+	return err
+}
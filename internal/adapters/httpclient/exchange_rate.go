@@ -4,56 +4,68 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"fxrates/internal/domain"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/shopspring/decimal"
 )
 
+const ExchangeRateProvider = "exchangerate_api"
+
 type ExchangeRateClient struct {
 	http    *http.Client
 	baseURL string
 }
 
 type apiResponse struct {
-	Result          string             `json:"result"`
-	BaseCode        string             `json:"base_code"`
-	ConversionRates map[string]float64 `json:"conversion_rates"`
+	Result          string                     `json:"result"`
+	ErrorType       string                     `json:"error-type"`
+	BaseCode        string                     `json:"base_code"`
+	ConversionRates map[string]decimal.Decimal `json:"conversion_rates"`
 }
 
-func (c *ExchangeRateClient) GetExchangeRates(ctx context.Context, base string) (map[string]float64, error) {
+func (c *ExchangeRateClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+		return domain.ExchangeRates{}, fmt.Errorf("failed to parse base URL: %w", err)
 	}
 
 	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + base
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request for currency %q: %w", base, err)
+		return domain.ExchangeRates{}, fmt.Errorf("failed to create request for currency %q: %w", base, err)
 	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request for currency %q: %w", base, err)
+		return domain.ExchangeRates{}, requestFailed(fmt.Errorf("failed to execute request for currency %q: %w", base, err))
 	}
 
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("unexpected status code %d for currency %q: %s", resp.StatusCode, base, resp.Status)
+		// failures are explained by an error-type in the body when there's one
+		var body apiResponse
+		if json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body) == nil && body.ErrorType != "" {
+			return domain.ExchangeRates{}, resultFailed(base, body.Result, body.ErrorType)
+		}
+		return domain.ExchangeRates{}, statusFailed(resp.StatusCode, fmt.Errorf("unexpected status code %d for currency %q: %s", resp.StatusCode, base, resp.Status))
 	}
 
 	var body apiResponse
 	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		return nil, fmt.Errorf("failed to decode response for currency %q: %w", base, err)
+		return domain.ExchangeRates{}, fmt.Errorf("failed to decode response for currency %q: %w", base, err)
 	}
 
 	if body.Result != "success" {
-		return nil, fmt.Errorf("api returned non-success result for currency %q: %s", base, body.Result)
+		return domain.ExchangeRates{}, resultFailed(base, body.Result, body.ErrorType)
 	}
 
-	return body.ConversionRates, nil
+	return domain.ExchangeRates{Provider: ExchangeRateProvider, Rates: body.ConversionRates}, nil
 }
 
 func NewExchangeRateClient(httpClient *http.Client, baseURL string) *ExchangeRateClient {
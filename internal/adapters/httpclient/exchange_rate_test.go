@@ -2,8 +2,11 @@ package httpclient
 
 import (
 	"context"
+	"errors"
+	"fxrates/internal/domain"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -26,12 +29,27 @@ func TestExchangeRateClient_Success(t *testing.T) {
 	baseURL := srv.URL + "/api/latest/"
 	c := NewExchangeRateClient(srv.Client(), baseURL)
 
-	ratesMap, err := c.GetExchangeRates(context.Background(), "USD")
+	res, err := c.GetExchangeRates(context.Background(), "USD")
 	require.NoError(t, err)
 	require.Equal(t, "/api/latest/USD", gotPath)
-	require.Len(t, ratesMap, 2)
-	require.InDelta(t, 0.92, ratesMap["EUR"], 1e-9)
-	require.InDelta(t, 150.0, ratesMap["JPY"], 1e-9)
+	require.Equal(t, ExchangeRateProvider, res.Provider)
+	require.Len(t, res.Rates, 2)
+	require.Equal(t, "0.92", res.Rates["EUR"].String())
+	require.Equal(t, "150", res.Rates["JPY"].String())
+}
+
+func TestExchangeRateClient_KeepsExactDecimals(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"result": "success", "base_code": "USD", "conversion_rates": {"EUR": 0.12345678901234567890}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewExchangeRateClient(srv.Client(), srv.URL)
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "0.1234567890123456789", res.Rates["EUR"].String())
 }
 
 func TestExchangeRateClient_StatusCodeError(t *testing.T) {
@@ -46,6 +64,31 @@ func TestExchangeRateClient_StatusCodeError(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "unexpected status code 503")
 	require.Contains(t, err.Error(), "USD")
+	require.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestExchangeRateClient_ClientErrorIsNotRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewExchangeRateClient(srv.Client(), srv.URL+"/latest")
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+	require.ErrorContains(t, err, "unexpected status code 404")
+	require.NotErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestExchangeRateClient_UnreachableIsRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	c := NewExchangeRateClient(srv.Client(), srv.URL+"/latest")
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+	require.ErrorContains(t, err, "failed to execute request for currency \"USD\"")
+	require.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
 }
 
 func TestExchangeRateClient_JSONDecodeError(t *testing.T) {
@@ -75,6 +118,41 @@ func TestExchangeRateClient_NonSuccessResult(t *testing.T) {
 	_, err := c.GetExchangeRates(context.Background(), "USD")
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "api returned non-success result for currency \"USD\": error")
+	require.NotErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestExchangeRateClient_TypedErrors(t *testing.T) {
+	tests := []struct {
+		errorType string
+		status    int
+		want      []error
+	}{
+		{errorType: "invalid-key", status: http.StatusForbidden, want: []error{domain.ErrUpstreamAuth}},
+		{errorType: "inactive-account", status: http.StatusOK, want: []error{domain.ErrUpstreamAuth}},
+		{errorType: "quota-reached", status: http.StatusOK, want: []error{domain.ErrUpstreamQuota, domain.ErrUpstreamUnavailable}},
+		{errorType: "unsupported-code", status: http.StatusNotFound, want: []error{domain.ErrUnsupportedCurrency}},
+		{errorType: "malformed-request", status: http.StatusBadRequest},
+	}
+	typed := []error{domain.ErrUpstreamAuth, domain.ErrUpstreamQuota, domain.ErrUpstreamUnavailable, domain.ErrUnsupportedCurrency}
+	for _, tt := range tests {
+		t.Run(tt.errorType, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"result": "error", "error-type": "` + tt.errorType + `"}`))
+			}))
+			t.Cleanup(srv.Close)
+
+			_, err := NewExchangeRateClient(srv.Client(), srv.URL+"/latest").GetExchangeRates(context.Background(), "USD")
+
+			require.EqualError(t, err, "api returned non-success result for currency \"USD\": error ("+tt.errorType+")")
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, tt.errorType, apiErr.Type)
+			for _, target := range typed {
+				require.Equal(t, slices.Contains(tt.want, target), errors.Is(err, target), target.Error())
+			}
+		})
+	}
 }
 
 func TestExchangeRateClient_BaseURLParseError(t *testing.T) {
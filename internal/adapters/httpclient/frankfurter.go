@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fxrates/internal/domain"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const FrankfurterProvider = "frankfurter"
+
+// FrankfurterClient fetches ECB reference rates from the Frankfurter API (api.frankfurter.app)
+type FrankfurterClient struct {
+	http    *http.Client
+	baseURL string
+}
+
+type frankfurterResponse struct {
+	Base  string                     `json:"base"`
+	Date  string                     `json:"date"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+func (c *FrankfurterClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return domain.ExchangeRates{}, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/latest"
+	u.RawQuery = url.Values{"base": []string{base}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return domain.ExchangeRates{}, fmt.Errorf("failed to create request for currency %q: %w", base, err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return domain.ExchangeRates{}, requestFailed(fmt.Errorf("failed to execute request for currency %q: %w", base, err))
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return domain.ExchangeRates{}, statusFailed(resp.StatusCode, fmt.Errorf("unexpected status code %d for currency %q: %s", resp.StatusCode, base, resp.Status))
+	}
+
+	var body frankfurterResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return domain.ExchangeRates{}, fmt.Errorf("failed to decode response for currency %q: %w", base, err)
+	}
+
+	if body.Base != base || len(body.Rates) == 0 {
+		return domain.ExchangeRates{}, fmt.Errorf("api returned no rates for currency %q", base)
+	}
+
+	return domain.ExchangeRates{Provider: FrankfurterProvider, Rates: body.Rates}, nil
+}
+
+func NewFrankfurterClient(httpClient *http.Client, baseURL string) *FrankfurterClient {
+	return &FrankfurterClient{http: httpClient, baseURL: baseURL}
+}
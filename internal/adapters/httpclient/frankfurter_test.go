@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrankfurterClient_Success(t *testing.T) {
+	var gotPath, gotBase string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotBase = r.URL.Query().Get("base")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"amount": 1.0, "base": "USD", "date": "2025-01-02", "rates": {"EUR": 0.93, "GBP": 0.79}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewFrankfurterClient(srv.Client(), srv.URL+"/")
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "/latest", gotPath)
+	require.Equal(t, "USD", gotBase)
+	require.Equal(t, FrankfurterProvider, res.Provider)
+	require.Equal(t, "0.93", res.Rates["EUR"].String())
+	require.Equal(t, "0.79", res.Rates["GBP"].String())
+}
+
+func TestFrankfurterClient_StatusCodeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewFrankfurterClient(srv.Client(), srv.URL)
+
+	_, err := c.GetExchangeRates(context.Background(), "XXX")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected status code 404")
+}
+
+func TestFrankfurterClient_EmptyRates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"amount": 1.0, "base": "USD", "date": "2025-01-02", "rates": {}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewFrankfurterClient(srv.Client(), srv.URL)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "api returned no rates for currency \"USD\"")
+}
@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fxrates/internal/domain"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const OpenERAPIProvider = "open_er_api"
+
+// OpenERAPIClient fetches rates from the keyless open access endpoint of ExchangeRate-API (open.er-api.com)
+type OpenERAPIClient struct {
+	http    *http.Client
+	baseURL string
+}
+
+type openERAPIResponse struct {
+	Result    string                     `json:"result"`
+	ErrorType string                     `json:"error-type"`
+	BaseCode  string                     `json:"base_code"`
+	Rates     map[string]decimal.Decimal `json:"rates"`
+}
+
+func (c *OpenERAPIClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	u, err := url.Parse(c.baseURL)
+	if err != nil {
+		return domain.ExchangeRates{}, fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/latest/" + base
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return domain.ExchangeRates{}, fmt.Errorf("failed to create request for currency %q: %w", base, err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return domain.ExchangeRates{}, requestFailed(fmt.Errorf("failed to execute request for currency %q: %w", base, err))
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// failures are explained by an error-type in the body when there's one
+		var body openERAPIResponse
+		if json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body) == nil && body.ErrorType != "" {
+			return domain.ExchangeRates{}, resultFailed(base, body.Result, body.ErrorType)
+		}
+		return domain.ExchangeRates{}, statusFailed(resp.StatusCode, fmt.Errorf("unexpected status code %d for currency %q: %s", resp.StatusCode, base, resp.Status))
+	}
+
+	var body openERAPIResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return domain.ExchangeRates{}, fmt.Errorf("failed to decode response for currency %q: %w", base, err)
+	}
+
+	if body.Result != "success" {
+		return domain.ExchangeRates{}, resultFailed(base, body.Result, body.ErrorType)
+	}
+
+	return domain.ExchangeRates{Provider: OpenERAPIProvider, Rates: body.Rates}, nil
+}
+
+func NewOpenERAPIClient(httpClient *http.Client, baseURL string) *OpenERAPIClient {
+	return &OpenERAPIClient{http: httpClient, baseURL: baseURL}
+}
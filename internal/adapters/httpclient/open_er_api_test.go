@@ -0,0 +1,76 @@
+package httpclient
+
+import (
+	"context"
+	"fxrates/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenERAPIClient_Success(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+            "result": "success",
+            "base_code": "USD",
+            "rates": {"EUR": 0.92, "JPY": 150.0}
+        }`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewOpenERAPIClient(srv.Client(), srv.URL+"/v6/")
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "/v6/latest/USD", gotPath)
+	require.Equal(t, OpenERAPIProvider, res.Provider)
+	require.Len(t, res.Rates, 2)
+	require.Equal(t, "0.92", res.Rates["EUR"].String())
+}
+
+func TestOpenERAPIClient_StatusCodeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewOpenERAPIClient(srv.Client(), srv.URL)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected status code 502")
+}
+
+func TestOpenERAPIClient_NonSuccessResult(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"result": "error"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewOpenERAPIClient(srv.Client(), srv.URL)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "api returned non-success result for currency \"USD\": error")
+}
+
+func TestOpenERAPIClient_UnsupportedCode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"result": "error", "error-type": "unsupported-code"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewOpenERAPIClient(srv.Client(), srv.URL)
+
+	_, err := c.GetExchangeRates(context.Background(), "XAU")
+	require.ErrorIs(t, err, domain.ErrUnsupportedCurrency)
+	require.Contains(t, err.Error(), "api returned non-success result for currency \"XAU\": error (unsupported-code)")
+}
@@ -0,0 +1,67 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Fxrates-Signature"
+	TimestampHeader = "X-Fxrates-Timestamp"
+)
+
+// WebhookClient posts JSON callbacks signed with the shared secret. Receivers verify SignatureHeader
+// against Sign(secret, TimestampHeader value, body) and may reject stale timestamps to prevent replays
+type WebhookClient struct {
+	http   *http.Client
+	secret []byte
+}
+
+func (c *WebhookClient) Send(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create callback request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(c.secret, timestamp, payload))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute callback request: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10)) // let the connection be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected callback status code %d: %s", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns "sha256=" followed by hex encoded HMAC-SHA256 of "<timestamp>.<payload>"
+func Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewWebhookClient creates a callbacks sender. Redirects aren't followed, so they count as failed deliveries
+func NewWebhookClient(httpClient *http.Client, secret string) *WebhookClient {
+	client := *httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return &WebhookClient{http: &client, secret: []byte(secret)}
+}
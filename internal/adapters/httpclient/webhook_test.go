@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookClient_Send_Signed(t *testing.T) {
+	var gotMethod, gotContentType, gotTimestamp, gotSignature string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotContentType = r.Header.Get("Content-Type")
+		gotTimestamp = r.Header.Get(TimestampHeader)
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewWebhookClient(srv.Client(), "s3cret")
+	payload := []byte(`{"update_id":"77b5d9f5-0569-47e3-aee2-f659d59fbd97"}`)
+
+	err := c.Send(context.Background(), srv.URL+"/hooks/fx", payload)
+	require.NoError(t, err)
+	require.Equal(t, http.MethodPost, gotMethod)
+	require.Equal(t, "application/json", gotContentType)
+	require.Equal(t, payload, gotBody)
+	require.NotEmpty(t, gotTimestamp)
+	require.Equal(t, Sign([]byte("s3cret"), gotTimestamp, payload), gotSignature)
+	require.NotEqual(t, Sign([]byte("other"), gotTimestamp, payload), gotSignature)
+}
+
+func TestWebhookClient_Send_StatusCodeError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewWebhookClient(srv.Client(), "s3cret")
+
+	err := c.Send(context.Background(), srv.URL, []byte(`{}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected callback status code 500")
+}
+
+func TestWebhookClient_Send_RedirectNotFollowed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := NewWebhookClient(srv.Client(), "s3cret")
+
+	err := c.Send(context.Background(), srv.URL, []byte(`{}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected callback status code 302")
+}
+
+func TestSign_KnownValue(t *testing.T) {
+	// echo -n '1700000000.{}' | openssl dgst -sha256 -hmac key
+	require.Equal(t,
+		"sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae",
+		Sign([]byte("key"), "1700000000", []byte(`{}`)),
+	)
+}
@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/domain"
+	"slices"
+)
+
+type APIKeyRepository struct {
+	store *Store
+}
+
+// Create stores the key by its hash and returns it with the generated ID and creation time
+func (r *APIKeyRepository) Create(ctx context.Context, keyHash string, key domain.APIKey) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to create api key of %q: %w", key.Owner, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.apiKeysHash[keyHash]; ok {
+		return domain.APIKey{}, fmt.Errorf("failed to create api key of %q: %w", key.Owner, errDuplicateKey)
+	}
+	key.ID = int64(len(r.store.apiKeys) + 1)
+	key.CreatedAt = r.store.now()
+	key.RevokedAt = nil
+	r.store.apiKeys = append(r.store.apiKeys, &apiKeyRecord{hash: keyHash, key: copyAPIKey(key)})
+	r.store.apiKeysHash[keyHash] = key.ID
+	return copyAPIKey(key), nil
+}
+
+// GetByHash returns the key including revoked and expired ones, so callers decide whether it's still active
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to select api key: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id, ok := r.store.apiKeysHash[keyHash]
+	if !ok {
+		return domain.APIKey{}, domain.ErrAPIKeyNotFound
+	}
+	return copyAPIKey(r.store.apiKeys[id-1].key), nil
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select api keys: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	keys := make([]domain.APIKey, 0, len(r.store.apiKeys))
+	for _, rec := range r.store.apiKeys {
+		keys = append(keys, copyAPIKey(rec.key))
+	}
+	return keys, nil
+}
+
+// Revoke revokes an active key, revoking an unknown or already revoked key returns domain.ErrAPIKeyNotFound
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to revoke api key %d: %w", id, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if id < 1 || id > int64(len(r.store.apiKeys)) || r.store.apiKeys[id-1].key.RevokedAt != nil {
+		return domain.ErrAPIKeyNotFound
+	}
+	now := r.store.now()
+	r.store.apiKeys[id-1].key.RevokedAt = &now
+	return nil
+}
+
+// copyAPIKey keeps callers from changing stored keys through shared slices and pointers
+func copyAPIKey(key domain.APIKey) domain.APIKey {
+	key.Scopes = slices.Clone(key.Scopes)
+	if key.ExpiresAt != nil {
+		expiresAt := *key.ExpiresAt
+		key.ExpiresAt = &expiresAt
+	}
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		key.RevokedAt = &revokedAt
+	}
+	return key
+}
+
+func NewAPIKeyRepository(store *Store) *APIKeyRepository {
+	return &APIKeyRepository{store: store}
+}
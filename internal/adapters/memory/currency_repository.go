@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"fxrates/internal/domain"
+	"slices"
+)
+
+type CurrencyRepository struct {
+	store *Store
+}
+
+// List returns all currencies including disabled ones ordered by code
+func (r *CurrencyRepository) List(ctx context.Context) ([]domain.Currency, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select currencies: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	currencies := make([]domain.Currency, 0, len(r.store.currencies))
+	for _, c := range r.store.currencies {
+		currencies = append(currencies, c)
+	}
+	slices.SortFunc(currencies, func(a, b domain.Currency) int { return cmp.Compare(a.Code, b.Code) })
+	return currencies, nil
+}
+
+// Add stores a new currency or enables a disabled one with the given details,
+// adding an enabled currency returns domain.ErrCurrencyExists
+func (r *CurrencyRepository) Add(ctx context.Context, currency domain.Currency) (domain.Currency, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Currency{}, fmt.Errorf("failed to add currency %s: %w", currency.Code, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if existing, ok := r.store.currencies[currency.Code]; ok && existing.Enabled {
+		return domain.Currency{}, domain.ErrCurrencyExists
+	}
+	currency.Enabled = true
+	r.store.currencies[currency.Code] = currency
+	return currency, nil
+}
+
+// Disable stops accepting the currency, disabling an unknown or already disabled one returns domain.ErrCurrencyNotFound
+func (r *CurrencyRepository) Disable(ctx context.Context, code string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to disable currency %s: %w", code, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	c, ok := r.store.currencies[code]
+	if !ok || !c.Enabled {
+		return domain.ErrCurrencyNotFound
+	}
+	c.Enabled = false
+	r.store.currencies[code] = c
+	return nil
+}
+
+func NewCurrencyRepository(store *Store) *CurrencyRepository {
+	return &CurrencyRepository{store: store}
+}
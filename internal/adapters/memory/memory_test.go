@@ -0,0 +1,110 @@
+package memory_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"fxrates/internal/adapters/memory"
+	"fxrates/internal/adapters/repotest"
+	"fxrates/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newRepositories(*testing.T) repotest.Repositories {
+	store := memory.NewStore()
+	return repotest.Repositories{
+		Rates:      memory.NewRateRepository(store),
+		Updates:    memory.NewRateUpdateRepository(store),
+		Callbacks:  memory.NewRateUpdateCallbackRepository(store),
+		APIKeys:    memory.NewAPIKeyRepository(store),
+		Currencies: memory.NewCurrencyRepository(store),
+	}
+}
+
+func TestRepositories_Contract(t *testing.T) {
+	repotest.Run(t, newRepositories)
+}
+
+func TestNewStore_SeedsCurrencies(t *testing.T) {
+	store := memory.NewStore(memory.DefaultCurrencies...)
+
+	currencies, err := memory.NewCurrencyRepository(store).List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, currencies, len(memory.DefaultCurrencies))
+	require.Equal(t, "AUD", currencies[0].Code)
+
+	_, err = memory.NewRateUpdateRepository(store).ScheduleNewOrGetExisting(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+}
+
+func TestRateUpdateRepository_ConcurrentSchedulesAndClaims(t *testing.T) {
+	store := memory.NewStore(memory.DefaultCurrencies...)
+	repo := memory.NewRateUpdateRepository(store)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	ids := make([]uuid.UUID, 16)
+	for i := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id, err := repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+			require.NoError(t, err)
+			ids[i] = id
+		}()
+	}
+	wg.Wait()
+	for _, id := range ids {
+		require.Equal(t, ids[0], id) // one pending update per pair
+	}
+
+	claimed := make([]int, 8)
+	for i := range claimed {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pending, err := repo.ClaimPending(ctx, uuid.NewString(), time.Minute)
+			require.NoError(t, err)
+			claimed[i] = len(pending)
+		}()
+	}
+	wg.Wait()
+	total := 0
+	for _, n := range claimed {
+		total += n
+	}
+	require.Equal(t, 1, total)
+}
+
+func TestRateUpdateRepository_WithNotify_WakesOnNewUpdatesOnly(t *testing.T) {
+	repo := memory.NewRateUpdateRepository(memory.NewStore(memory.DefaultCurrencies...)).WithNotify()
+	ctx := context.Background()
+
+	_, err := repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	_, err = repo.ScheduleNewOrGetExistingBatch(ctx, []domain.RatePair{{Base: "GBP", Quote: "JPY"}})
+	require.NoError(t, err)
+	select {
+	case <-repo.Wakeups():
+	default:
+		t.Fatal("expected a wakeup")
+	}
+	select {
+	case <-repo.Wakeups():
+		t.Fatal("wakeups must be coalesced")
+	default:
+	}
+
+	// the existing pending update is returned without a wakeup
+	_, err = repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	select {
+	case <-repo.Wakeups():
+		t.Fatal("unexpected wakeup for an existing update")
+	default:
+	}
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/domain"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type RateRepository struct {
+	store *Store
+}
+
+func (r *RateRepository) GetByCodes(ctx context.Context, base string, quote string) (domain.Rate, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Rate{}, fmt.Errorf("failed to select rate for pair %q/%q: %w", base, quote, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	pairID, ok := r.store.pairs[domain.RatePair{Base: base, Quote: quote}]
+	if !ok {
+		return domain.Rate{}, domain.ErrRateNotFound
+	}
+	last, ok := r.store.lastRates[pairID]
+	if !ok {
+		return domain.Rate{}, domain.ErrRateNotFound
+	}
+	return domain.Rate{PairID: pairID, Base: base, Quote: quote, Value: last.value, UpdatedAt: last.updatedAt}, nil
+}
+
+func (r *RateRepository) GetByUpdateID(ctx context.Context, updateID uuid.UUID) (domain.Rate, domain.RateUpdateStatus, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Rate{}, "", fmt.Errorf("failed to select rate for update ID %q: %w", updateID, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	upd, ok := r.store.updates[updateID]
+	if !ok {
+		return domain.Rate{}, "", domain.ErrRateNotFound
+	}
+	pair := r.store.pairsByID[upd.pairID]
+	rate := domain.Rate{
+		PairID:    upd.pairID,
+		Base:      pair.Base,
+		Quote:     pair.Quote,
+		Value:     decimal.NewFromInt(-1), // explicitly set bad value
+		UpdatedAt: upd.updatedAt,
+		Source:    upd.source,
+		Quotes:    append(make([]domain.ProviderQuote, 0, len(upd.quotes)), upd.quotes...),
+		Attempts:  upd.attempts,
+		Reason:    upd.reason,
+	}
+	if upd.status == domain.StatusApplied {
+		rate.Value = upd.value
+	}
+	return rate, upd.status, nil
+}
+
+// GetHistory returns applied values of the pair recorded within [from, to) ordered by time.
+// When interval is positive, points are bucketed from the Unix epoch like date_bin and the last value of each bucket is returned
+func (r *RateRepository) GetHistory(ctx context.Context, base string, quote string, from time.Time, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query history for pair %q/%q: %w", base, quote, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	points := make([]domain.RateHistoryPoint, 0, 64)
+	pairID, ok := r.store.pairs[domain.RatePair{Base: base, Quote: quote}]
+	if !ok {
+		return points, nil
+	}
+	for _, p := range r.store.history[pairID] {
+		if !p.RecordedAt.Before(from) && p.RecordedAt.Before(to) {
+			points = append(points, p)
+		}
+	}
+	slices.SortStableFunc(points, func(a, b domain.RateHistoryPoint) int { return a.RecordedAt.Compare(b.RecordedAt) })
+	if interval <= 0 {
+		return points, nil
+	}
+
+	buckets := make([]domain.RateHistoryPoint, 0, len(points))
+	for _, p := range points {
+		bucket := binTime(p.RecordedAt, interval)
+		if n := len(buckets); n > 0 && buckets[n-1].RecordedAt.Equal(bucket) {
+			buckets[n-1].Value = p.Value // points are ordered, the last one of the bucket wins
+			continue
+		}
+		buckets = append(buckets, domain.RateHistoryPoint{Value: p.Value, RecordedAt: bucket})
+	}
+	return buckets, nil
+}
+
+// binTime returns the start of the interval t falls in, intervals are counted from the Unix epoch
+func binTime(t time.Time, interval time.Duration) time.Time {
+	n, stride := t.UnixNano(), interval.Nanoseconds()
+	offset := n % stride
+	if offset < 0 {
+		offset += stride
+	}
+	return time.Unix(0, n-offset).UTC()
+}
+
+func NewRateRepository(store *Store) *RateRepository {
+	return &RateRepository{store: store}
+}
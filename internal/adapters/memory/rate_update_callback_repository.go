@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/domain"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	callbackPending   = "pending"
+	callbackDelivered = "delivered"
+	callbackFailed    = "failed"
+)
+
+type RateUpdateCallbackRepository struct {
+	store *Store
+}
+
+// Register adds a callback to the update, registering the same URL twice is a no-op
+func (r *RateUpdateCallbackRepository) Register(ctx context.Context, updateID uuid.UUID, url string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to register callback for update ID %q: %w", updateID, err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.updates[updateID]; !ok {
+		return fmt.Errorf("failed to register callback for update ID %q: %w", updateID, errUnknownUpdate)
+	}
+	for _, c := range r.store.callbacks {
+		if c.updateID == updateID && c.url == url {
+			return nil
+		}
+	}
+	r.store.callbacks = append(r.store.callbacks, &rateUpdateCallback{
+		updateID:      updateID,
+		url:           url,
+		status:        callbackPending,
+		nextAttemptAt: r.store.now(),
+	})
+	return nil
+}
+
+// ClaimDue returns pending callbacks of finished (applied, failed or expired) updates whose next attempt is due and postpones them by the lease
+func (r *RateUpdateCallbackRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.RateUpdateCallback, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim due callbacks: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now()
+	due := make([]int64, 0, limit)
+	for i, c := range r.store.callbacks {
+		if c.status == callbackPending && !c.nextAttemptAt.After(now) && r.store.updates[c.updateID].status != domain.StatusPending {
+			due = append(due, int64(i+1))
+		}
+	}
+	slices.SortStableFunc(due, func(a, b int64) int {
+		return r.store.callbacks[a-1].nextAttemptAt.Compare(r.store.callbacks[b-1].nextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	callbacks := make([]domain.RateUpdateCallback, 0, len(due))
+	for _, id := range due {
+		c := r.store.callbacks[id-1]
+		c.nextAttemptAt = now.Add(lease)
+		upd := r.store.updates[c.updateID]
+		pair := r.store.pairsByID[upd.pairID]
+		callbacks = append(callbacks, domain.RateUpdateCallback{
+			ID:        id,
+			UpdateID:  c.updateID,
+			URL:       c.url,
+			Attempts:  c.attempts,
+			Base:      pair.Base,
+			Quote:     pair.Quote,
+			Status:    upd.status,
+			Value:     upd.value,
+			Reason:    upd.reason,
+			UpdatedAt: upd.updatedAt,
+		})
+	}
+	return callbacks, nil
+}
+
+func (r *RateUpdateCallbackRepository) MarkDelivered(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to mark callback %d delivered: %w", id, err)
+	}
+	r.update(id, func(c *rateUpdateCallback) {
+		c.status, c.lastErr = callbackDelivered, ""
+		c.attempts++
+	})
+	return nil
+}
+
+// Reschedule counts the failed attempt and postpones the next one by the given backoff
+func (r *RateUpdateCallbackRepository) Reschedule(ctx context.Context, id int64, after time.Duration, lastErr string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to reschedule callback %d: %w", id, err)
+	}
+	r.update(id, func(c *rateUpdateCallback) {
+		c.nextAttemptAt, c.lastErr = r.store.now().Add(after), lastErr
+		c.attempts++
+	})
+	return nil
+}
+
+// MarkFailed counts the last failed attempt and stops delivering the callback
+func (r *RateUpdateCallbackRepository) MarkFailed(ctx context.Context, id int64, lastErr string) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to mark callback %d failed: %w", id, err)
+	}
+	r.update(id, func(c *rateUpdateCallback) {
+		c.status, c.lastErr = callbackFailed, lastErr
+		c.attempts++
+	})
+	return nil
+}
+
+// update changes the callback under the lock, unknown IDs are ignored as updates of no rows are
+func (r *RateUpdateCallbackRepository) update(id int64, change func(c *rateUpdateCallback)) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if id >= 1 && id <= int64(len(r.store.callbacks)) {
+		change(r.store.callbacks[id-1])
+	}
+}
+
+func NewRateUpdateCallbackRepository(store *Store) *RateUpdateCallbackRepository {
+	return &RateUpdateCallbackRepository{store: store}
+}
@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RateUpdateRepository struct {
+	store *Store
+	// wakeups gets a value once new pending updates are inserted, nil disables it
+	wakeups chan struct{}
+}
+
+func (r *RateUpdateRepository) ScheduleNewOrGetExisting(ctx context.Context, base string, quote string) (uuid.UUID, error) {
+	if err := ctx.Err(); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to ensure an update for '%s/%s': %w", base, quote, err)
+	}
+	r.store.mu.Lock()
+	updateID, inserted, err := r.scheduleLocked(base, quote)
+	r.store.mu.Unlock()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to ensure an update for '%s/%s': %w", base, quote, err)
+	}
+	if inserted {
+		r.notify()
+	}
+	return updateID, nil
+}
+
+// ScheduleNewOrGetExistingBatch does the same as ScheduleNewOrGetExisting for many pairs at once,
+// none of them is scheduled when one of the pairs is invalid
+func (r *RateUpdateRepository) ScheduleNewOrGetExistingBatch(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error) {
+	if len(pairs) == 0 {
+		return map[domain.RatePair]uuid.UUID{}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to ensure updates for %d pairs: %w", len(pairs), err)
+	}
+
+	r.store.mu.Lock()
+	for _, p := range pairs {
+		if _, err := r.store.ensurePair(p.Base, p.Quote); err != nil {
+			r.store.mu.Unlock()
+			return nil, fmt.Errorf("failed to ensure updates for %d pairs: %w", len(pairs), err)
+		}
+	}
+	updateIDs := make(map[domain.RatePair]uuid.UUID, len(pairs))
+	anyInserted := false
+	for _, p := range pairs {
+		updateID, inserted, _ := r.scheduleLocked(p.Base, p.Quote) // pairs exist already
+		updateIDs[p] = updateID
+		anyInserted = anyInserted || inserted
+	}
+	r.store.mu.Unlock()
+
+	if anyInserted {
+		r.notify()
+	}
+	return updateIDs, nil
+}
+
+// scheduleLocked returns the pending update of the pair, inserting one when there's none. Callers hold the lock
+func (r *RateUpdateRepository) scheduleLocked(base string, quote string) (uuid.UUID, bool, error) {
+	pairID, err := r.store.ensurePair(base, quote)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	if updateID, ok := r.store.pendingByPair[pairID]; ok {
+		return updateID, false, nil
+	}
+
+	now := r.store.now()
+	upd := &rateUpdate{
+		pairID:    pairID,
+		updateID:  uuid.New(),
+		status:    domain.StatusPending,
+		createdAt: now,
+		updatedAt: now,
+	}
+	r.store.updates[upd.updateID] = upd
+	r.store.updateOrder = append(r.store.updateOrder, upd.updateID)
+	r.store.pendingByPair[pairID] = upd.updateID
+	return upd.updateID, true, nil
+}
+
+// ClaimPending claims pending updates not claimed by another run (or whose lease expired) for the lease
+func (r *RateUpdateRepository) ClaimPending(ctx context.Context, claimID string, lease time.Duration) ([]domain.PendingRateUpdate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim pending rates: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now()
+	pending := make([]domain.PendingRateUpdate, 0, len(r.store.pendingByPair))
+	for _, updateID := range r.store.updateOrder {
+		upd := r.store.updates[updateID]
+		if upd.status != domain.StatusPending || upd.claimedUntil.After(now) {
+			continue
+		}
+		upd.claimedBy, upd.claimedUntil = claimID, now.Add(lease)
+		pair := r.store.pairsByID[upd.pairID]
+		pending = append(pending, domain.PendingRateUpdate{
+			UpdateID:  upd.updateID,
+			PairID:    upd.pairID,
+			Base:      pair.Base,
+			Quote:     pair.Quote,
+			Attempts:  upd.attempts,
+			CreatedAt: upd.createdAt,
+		})
+	}
+	return pending, nil
+}
+
+// ApplyUpdates applies values of updates still claimed by claimID all at once along with last rates and history.
+// Updates reclaimed by another run meanwhile are left to it and reported by a *domain.ClaimLostError
+func (r *RateUpdateRepository) ApplyUpdates(ctx context.Context, claimID string, applied []domain.AppliedRateUpdate) error {
+	if len(applied) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// validate everything first, so a failure leaves the store untouched like a rolled back transaction
+	values := make([]domain.AppliedRateUpdate, 0, len(applied))
+	var lost []uuid.UUID
+	for _, a := range applied {
+		upd, ok := r.store.updates[a.UpdateID]
+		if !ok || upd.status != domain.StatusPending || upd.claimedBy != claimID {
+			lost = append(lost, a.UpdateID)
+			continue
+		}
+		value, err := rateValue(a.Value)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		quotes := make([]domain.ProviderQuote, 0, len(a.Quotes))
+		for _, q := range a.Quotes {
+			if q.Value, err = rateValue(q.Value); err != nil {
+				return fmt.Errorf("failed to execute query: %w", err)
+			}
+			quotes = append(quotes, q)
+		}
+		a.Value, a.Quotes = value, quotes
+		values = append(values, a)
+	}
+	now := r.store.now()
+	for _, a := range values {
+		upd := r.store.updates[a.UpdateID]
+		upd.status, upd.value, upd.source, upd.quotes = domain.StatusApplied, a.Value, a.Source, a.Quotes
+		upd.updatedAt, upd.claimedUntil = now, time.Time{}
+		delete(r.store.pendingByPair, upd.pairID)
+
+		r.store.history[upd.pairID] = append(r.store.history[upd.pairID], domain.RateHistoryPoint{Value: a.Value, RecordedAt: now})
+		r.store.lastRates[upd.pairID] = lastRate{value: a.Value, updatedAt: now}
+	}
+	if len(lost) > 0 {
+		return &domain.ClaimLostError{UpdateIDs: lost}
+	}
+	return nil
+}
+
+// IncrementAttempts counts one more failed fetch attempt of still pending updates claimed by claimID
+// and releases them, so the next run retries them
+func (r *RateUpdateRepository) IncrementAttempts(ctx context.Context, claimID string, updateIDs []uuid.UUID) error {
+	if len(updateIDs) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to increment attempts: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range updateIDs {
+		if upd := r.claimedLocked(id, claimID); upd != nil {
+			upd.attempts++
+			upd.claimedBy, upd.claimedUntil = "", time.Time{}
+		}
+	}
+	return nil
+}
+
+// ReleaseClaims releases still pending updates claimed by claimID without counting an attempt,
+// as their rates weren't even asked for. The next run picks them up
+func (r *RateUpdateRepository) ReleaseClaims(ctx context.Context, claimID string, updateIDs []uuid.UUID) error {
+	if len(updateIDs) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to release claims: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, id := range updateIDs {
+		if upd := r.claimedLocked(id, claimID); upd != nil {
+			upd.claimedBy, upd.claimedUntil = "", time.Time{}
+		}
+	}
+	return nil
+}
+
+// CloseUpdates moves still pending updates claimed by claimID to failed or expired status with a reason, counting the last attempt
+func (r *RateUpdateRepository) CloseUpdates(ctx context.Context, claimID string, closed []domain.ClosedRateUpdate) error {
+	if len(closed) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to close updates: %w", err)
+	}
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := r.store.now()
+	for _, c := range closed {
+		if upd := r.claimedLocked(c.UpdateID, claimID); upd != nil {
+			upd.status, upd.reason = c.Status, c.Reason
+			upd.attempts++
+			upd.updatedAt, upd.claimedUntil = now, time.Time{}
+			delete(r.store.pendingByPair, upd.pairID)
+		}
+	}
+	return nil
+}
+
+// claimedLocked returns the update when it's still pending and claimed by claimID. Callers hold the lock
+func (r *RateUpdateRepository) claimedLocked(updateID uuid.UUID, claimID string) *rateUpdate {
+	upd, ok := r.store.updates[updateID]
+	if !ok || upd.status != domain.StatusPending || upd.claimedBy != claimID {
+		return nil
+	}
+	return upd
+}
+
+func (r *RateUpdateRepository) notify() {
+	if r.wakeups == nil {
+		return
+	}
+	select {
+	case r.wakeups <- struct{}{}:
+	default:
+	}
+}
+
+func NewRateUpdateRepository(store *Store) *RateUpdateRepository {
+	return &RateUpdateRepository{store: store}
+}
+
+// WithNotify makes newly scheduled updates send a value to Wakeups, the in-process counterpart of postgres.UpdateListener
+func (r *RateUpdateRepository) WithNotify() *RateUpdateRepository {
+	r.wakeups = make(chan struct{}, 1)
+	return r
+}
+
+// Wakeups receives a value after updates were scheduled, wakeups arriving before it's read are coalesced
+func (r *RateUpdateRepository) Wakeups() <-chan struct{} {
+	return r.wakeups
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	errUnknownCurrency = errors.New("currency doesn't exist")
+	errSameCurrency    = errors.New("base and quote must differ")
+	errUnknownUpdate   = errors.New("rate update doesn't exist")
+	errValueOutOfRange = errors.New("value doesn't fit numeric(16,8)")
+	errDuplicateKey    = errors.New("api key hash already exists")
+)
+
+// maxRateValue bounds values the same way numeric(16,8) columns do, 8 integer digits at most
+var maxRateValue = decimal.New(1, 16-domain.RateScale)
+
+// DefaultCurrencies are the currencies seeded by the migrations, used to seed stores of local runs
+var DefaultCurrencies = []domain.Currency{
+	{Code: "USD", Name: "US Dollar", NumericCode: "840", MinorUnits: 2, Enabled: true},
+	{Code: "EUR", Name: "Euro", NumericCode: "978", MinorUnits: 2, Enabled: true},
+	{Code: "MXN", Name: "Mexican Peso", NumericCode: "484", MinorUnits: 2, Enabled: true},
+	{Code: "GBP", Name: "Pound Sterling", NumericCode: "826", MinorUnits: 2, Enabled: true},
+	{Code: "JPY", Name: "Yen", NumericCode: "392", MinorUnits: 0, Enabled: true},
+	{Code: "CHF", Name: "Swiss Franc", NumericCode: "756", MinorUnits: 2, Enabled: true},
+	{Code: "AUD", Name: "Australian Dollar", NumericCode: "036", MinorUnits: 2, Enabled: true},
+	{Code: "CAD", Name: "Canadian Dollar", NumericCode: "124", MinorUnits: 2, Enabled: true},
+}
+
+// Store keeps the data of all in-memory repositories, the counterpart of the Postgres database.
+// A single lock guards it, so every repository call is atomic the way a transaction is
+type Store struct {
+	mu  sync.Mutex
+	now func() time.Time
+
+	currencies map[string]domain.Currency
+
+	pairs      map[domain.RatePair]int64
+	pairsByID  map[int64]domain.RatePair
+	nextPairID int64
+
+	// updates are kept in scheduling order, pendingByPair holds the single pending update of a pair
+	updates       map[uuid.UUID]*rateUpdate
+	updateOrder   []uuid.UUID
+	pendingByPair map[int64]uuid.UUID
+
+	lastRates map[int64]lastRate
+	history   map[int64][]domain.RateHistoryPoint
+
+	// callbacks and apiKeys are indexed by ID-1
+	callbacks   []*rateUpdateCallback
+	apiKeys     []*apiKeyRecord
+	apiKeysHash map[string]int64
+}
+
+type rateUpdate struct {
+	pairID       int64
+	updateID     uuid.UUID
+	status       domain.RateUpdateStatus
+	value        decimal.Decimal
+	source       string
+	quotes       []domain.ProviderQuote
+	attempts     int
+	reason       string
+	createdAt    time.Time
+	updatedAt    time.Time
+	claimedBy    string
+	claimedUntil time.Time
+}
+
+type lastRate struct {
+	value     decimal.Decimal
+	updatedAt time.Time
+}
+
+type rateUpdateCallback struct {
+	updateID      uuid.UUID
+	url           string
+	status        string
+	attempts      int
+	nextAttemptAt time.Time
+	lastErr       string
+}
+
+type apiKeyRecord struct {
+	hash string
+	key  domain.APIKey
+}
+
+// NewStore creates an empty store with the given currencies
+func NewStore(currencies ...domain.Currency) *Store {
+	s := &Store{
+		now:           time.Now,
+		currencies:    make(map[string]domain.Currency, len(currencies)),
+		pairs:         make(map[domain.RatePair]int64),
+		pairsByID:     make(map[int64]domain.RatePair),
+		updates:       make(map[uuid.UUID]*rateUpdate),
+		pendingByPair: make(map[int64]uuid.UUID),
+		lastRates:     make(map[int64]lastRate),
+		history:       make(map[int64][]domain.RateHistoryPoint),
+		apiKeysHash:   make(map[string]int64),
+	}
+	for _, c := range currencies {
+		s.currencies[c.Code] = c
+	}
+	return s
+}
+
+// ensurePair returns the ID of the pair, creating it when both currencies exist. Callers hold the lock
+func (s *Store) ensurePair(base string, quote string) (int64, error) {
+	pair := domain.RatePair{Base: base, Quote: quote}
+	if id, ok := s.pairs[pair]; ok {
+		return id, nil
+	}
+	if base == quote {
+		return 0, errSameCurrency
+	}
+	for _, code := range []string{base, quote} {
+		if _, ok := s.currencies[code]; !ok {
+			return 0, fmt.Errorf("%w: %s", errUnknownCurrency, code)
+		}
+	}
+	s.nextPairID++
+	s.pairs[pair] = s.nextPairID
+	s.pairsByID[s.nextPairID] = pair
+	return s.nextPairID, nil
+}
+
+// rateValue rounds the value to the stored scale, rejecting values that don't fit the column
+func rateValue(v decimal.Decimal) (decimal.Decimal, error) {
+	rounded := v.Round(domain.RateScale)
+	if rounded.Abs().GreaterThanOrEqual(maxRateValue) {
+		return decimal.Decimal{}, fmt.Errorf("%w: %s", errValueOutOfRange, v)
+	}
+	return rounded, nil
+}
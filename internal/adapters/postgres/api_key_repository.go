@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// Create stores the key by its hash and returns it with the generated ID and creation time
+func (r *APIKeyRepository) Create(ctx context.Context, keyHash string, key domain.APIKey) (domain.APIKey, error) {
+	const q = `
+		insert into api_keys(key_hash, key_prefix, owner, scopes, expires_at)
+		values ($1, $2, $3, $4, $5)
+		returning id, created_at;
+	`
+
+	if err := r.pool.QueryRow(ctx, q, keyHash, key.Prefix, key.Owner, key.Scopes, key.ExpiresAt).Scan(&key.ID, &key.CreatedAt); err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to create api key of %q: %w", key.Owner, err)
+	}
+	return key, nil
+}
+
+// GetByHash returns the key including revoked and expired ones, so callers decide whether it's still active
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	const q = `
+		select id, key_prefix, owner, scopes, expires_at, revoked_at, created_at
+		from api_keys
+		where key_hash = $1;
+	`
+
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, q, keyHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrAPIKeyNotFound
+		}
+		return domain.APIKey{}, fmt.Errorf("failed to select api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	const q = `
+		select id, key_prefix, owner, scopes, expires_at, revoked_at, created_at
+		from api_keys
+		order by id;
+	`
+
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]domain.APIKey, 0)
+	for rows.Next() {
+		key, scanErr := scanAPIKey(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", scanErr)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke revokes an active key, revoking an unknown or already revoked key returns domain.ErrAPIKeyNotFound
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	const q = `
+		update api_keys set revoked_at = now()
+		where id = $1 and revoked_at is null;
+	`
+
+	tag, err := r.pool.Exec(ctx, q, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key %d: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(&key.ID, &key.Prefix, &key.Owner, &key.Scopes, &key.ExpiresAt, &key.RevokedAt, &key.CreatedAt)
+	return key, err
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool}
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CurrencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// List returns all currencies including disabled ones ordered by code
+func (r *CurrencyRepository) List(ctx context.Context) ([]domain.Currency, error) {
+	const q = `
+		select code, name, numeric_code, minor_units, enabled
+		from currencies
+		order by code;
+	`
+
+	rows, err := r.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select currencies: %w", err)
+	}
+	defer rows.Close()
+
+	currencies := make([]domain.Currency, 0)
+	for rows.Next() {
+		var c domain.Currency
+		if err = rows.Scan(&c.Code, &c.Name, &c.NumericCode, &c.MinorUnits, &c.Enabled); err != nil {
+			return nil, fmt.Errorf("failed to scan currency: %w", err)
+		}
+		currencies = append(currencies, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating currencies: %w", err)
+	}
+	return currencies, nil
+}
+
+// Add stores a new currency or enables a disabled one with the given details,
+// adding an enabled currency returns domain.ErrCurrencyExists
+func (r *CurrencyRepository) Add(ctx context.Context, currency domain.Currency) (domain.Currency, error) {
+	const q = `
+		insert into currencies(code, name, numeric_code, minor_units, enabled)
+		values ($1, $2, $3, $4, true)
+		on conflict (code) do update
+		set name = excluded.name, numeric_code = excluded.numeric_code, minor_units = excluded.minor_units, enabled = true
+		where not currencies.enabled
+		returning code, name, numeric_code, minor_units, enabled;
+	`
+
+	var c domain.Currency
+	err := r.pool.QueryRow(ctx, q, currency.Code, currency.Name, currency.NumericCode, currency.MinorUnits).
+		Scan(&c.Code, &c.Name, &c.NumericCode, &c.MinorUnits, &c.Enabled)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.Currency{}, domain.ErrCurrencyExists
+		}
+		return domain.Currency{}, fmt.Errorf("failed to add currency %s: %w", currency.Code, err)
+	}
+	return c, nil
+}
+
+// Disable stops accepting the currency, disabling an unknown or already disabled one returns domain.ErrCurrencyNotFound
+func (r *CurrencyRepository) Disable(ctx context.Context, code string) error {
+	const q = `
+		update currencies set enabled = false
+		where code = $1 and enabled;
+	`
+
+	tag, err := r.pool.Exec(ctx, q, code)
+	if err != nil {
+		return fmt.Errorf("failed to disable currency %s: %w", code, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrCurrencyNotFound
+	}
+	return nil
+}
+
+func NewCurrencyRepository(pool *pgxpool.Pool) *CurrencyRepository {
+	return &CurrencyRepository{pool: pool}
+}
@@ -2,19 +2,20 @@ package postgres_test
 
 import (
 	"context"
-	"math"
 	"os"
 	"sync"
 	"testing"
 	"time"
 
 	"fxrates/internal/adapters/postgres"
+	"fxrates/internal/adapters/repotest"
 	"fxrates/internal/domain"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	tcpg "github.com/testcontainers/testcontainers-go/modules/postgres"
 )
@@ -84,12 +85,25 @@ func startPostgres(t *testing.T) {
 }
 
 func resetDatabase(ctx context.Context, pool *pgxpool.Pool) error {
-	if _, err := pool.Exec(ctx, `truncate table fx_rate_updates, fx_last_rates, fx_pairs, currencies restart identity cascade`); err != nil {
+	if _, err := pool.Exec(ctx, `truncate table upstream_budget, api_keys, fx_rate_update_callbacks, fx_rate_update_quotes, fx_rate_history, fx_rate_updates, fx_last_rates, fx_pairs, currencies restart identity cascade`); err != nil {
 		return err
 	}
 	return nil
 }
 
+func TestRepositories_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		pool := setupPostgres(t)
+		return repotest.Repositories{
+			Rates:      postgres.NewRateRepository(pool),
+			Updates:    postgres.NewRateUpdateRepository(pool),
+			Callbacks:  postgres.NewRateUpdateCallbackRepository(pool),
+			APIKeys:    postgres.NewAPIKeyRepository(pool),
+			Currencies: postgres.NewCurrencyRepository(pool),
+		}
+	})
+}
+
 // ---------- RateRepository tests ----------
 
 func TestRateRepository_GetByCodes_NotFound(t *testing.T) {
@@ -121,7 +135,7 @@ func TestRateRepository_GetByCodes_Success(t *testing.T) {
 	require.Equal(t, pairID, rate.PairID)
 	require.Equal(t, "USD", rate.Base)
 	require.Equal(t, "EUR", rate.Quote)
-	require.InDelta(t, 1.2346, rate.Value, 0.00001) // rounded to 4 decimals
+	require.Equal(t, "1.23456", rate.Value.String()) // served with full stored precision
 	require.False(t, rate.UpdatedAt.IsZero())
 }
 
@@ -165,7 +179,7 @@ func TestRateRepository_GetByUpdateID_Pending_NoValue(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, pairID, rate.PairID)
 	require.Equal(t, domain.StatusPending, status)
-	require.Equal(t, -1.0, rate.Value) // explicitly set bad value when pending
+	require.Equal(t, "-1", rate.Value.String()) // explicitly set bad value when pending
 }
 
 func TestRateRepository_GetByUpdateID_Applied_WithValue(t *testing.T) {
@@ -180,7 +194,7 @@ func TestRateRepository_GetByUpdateID_Applied_WithValue(t *testing.T) {
 	err = pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values($1,$2) returning id`, "GBP", "USD").Scan(&pairID)
 	require.NoError(t, err)
 	updID := uuid.New()
-	// Use a value with more decimals to verify rounding happens in query (4 decimals)
+	// Use a value with more decimals to verify the stored precision is kept
 	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status, value) values ($1,$2,'applied',$3)`, pairID, updID, 0.999949)
 	require.NoError(t, err)
 
@@ -188,7 +202,7 @@ func TestRateRepository_GetByUpdateID_Applied_WithValue(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, pairID, rate.PairID)
 	require.Equal(t, domain.StatusApplied, status)
-	require.InDelta(t, 0.9999, rate.Value, 0.00001)
+	require.Equal(t, "0.999949", rate.Value.String())
 }
 
 func TestRateRepository_GetByUpdateID_DBError(t *testing.T) {
@@ -202,6 +216,67 @@ func TestRateRepository_GetByUpdateID_DBError(t *testing.T) {
 	require.NotErrorIs(t, err, domain.ErrRateNotFound)
 }
 
+func TestRateRepository_GetHistory_RawOrderedWithinRange(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('EUR')`)
+	require.NoError(t, err)
+
+	var pairID int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','EUR') returning id`).Scan(&pairID))
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, v := range []float64{0.91, 0.92, 0.93} {
+		_, err = pool.Exec(ctx, `insert into fx_rate_history(pair_id, value, recorded_at) values ($1,$2,$3)`, pairID, v, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	points, err := repo.GetHistory(ctx, "USD", "EUR", start, start.Add(2*time.Hour), 0)
+	require.NoError(t, err)
+	require.Len(t, points, 2) // upper bound is exclusive
+	require.Equal(t, "0.91", points[0].Value.String())
+	require.Equal(t, "0.92", points[1].Value.String())
+	require.True(t, points[0].RecordedAt.Equal(start))
+}
+
+func TestRateRepository_GetHistory_BucketedTakesLastValue(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('JPY')`)
+	require.NoError(t, err)
+
+	var pairID int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','JPY') returning id`).Scan(&pairID))
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, v := range []float64{150, 151, 152, 153} {
+		_, err = pool.Exec(ctx, `insert into fx_rate_history(pair_id, value, recorded_at) values ($1,$2,$3)`, pairID, v, start.Add(time.Duration(i)*30*time.Minute))
+		require.NoError(t, err)
+	}
+
+	points, err := repo.GetHistory(ctx, "USD", "JPY", start, start.Add(2*time.Hour), time.Hour)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	require.Equal(t, "151", points[0].Value.String())
+	require.True(t, points[0].RecordedAt.Equal(start))
+	require.Equal(t, "153", points[1].Value.String())
+	require.True(t, points[1].RecordedAt.Equal(start.Add(time.Hour)))
+}
+
+func TestRateRepository_GetHistory_UnknownPair_Empty(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateRepository(pool)
+	ctx := context.Background()
+
+	points, err := repo.GetHistory(ctx, "USD", "EUR", time.Now().Add(-time.Hour), time.Now(), 0)
+	require.NoError(t, err)
+	require.Empty(t, points)
+}
+
 // ---------- RateUpdateRepository tests ----------
 
 func TestRateUpdateRepository_ScheduleNewOrGetExisting_NewAndExisting(t *testing.T) {
@@ -229,6 +304,75 @@ func TestRateUpdateRepository_ScheduleNewOrGetExisting_NewAndExisting(t *testing
 	require.Equal(t, upd1, upd2)
 }
 
+func TestRateUpdateRepository_ScheduleNewOrGetExistingBatch_NewAndExisting(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('EUR'),('GBP'),('JPY')`)
+	require.NoError(t, err)
+
+	existing, err := repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+
+	usdEUR := domain.RatePair{Base: "USD", Quote: "EUR"}
+	gbpJPY := domain.RatePair{Base: "GBP", Quote: "JPY"}
+	ids, err := repo.ScheduleNewOrGetExistingBatch(ctx, []domain.RatePair{usdEUR, gbpJPY})
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.Equal(t, existing, ids[usdEUR])
+	require.NotEqual(t, uuid.Nil, ids[gbpJPY])
+
+	var pending int
+	require.NoError(t, pool.QueryRow(ctx, `select count(*) from fx_rate_updates where status='pending'`).Scan(&pending))
+	require.Equal(t, 2, pending)
+
+	// Scheduling again is idempotent while the updates are pending.
+	again, err := repo.ScheduleNewOrGetExistingBatch(ctx, []domain.RatePair{gbpJPY, usdEUR})
+	require.NoError(t, err)
+	require.Equal(t, ids, again)
+}
+
+func TestRateUpdateRepository_WithNotify_WakesListenerOnNewUpdatesOnly(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool).WithNotify(postgres.RateUpdatesChannel)
+	listener := postgres.NewUpdateListener(pool, postgres.RateUpdatesChannel)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('EUR')`)
+	require.NoError(t, err)
+	go listener.Listen(ctx)
+
+	// LISTEN is issued asynchronously, so keep scheduling new updates until a wakeup arrives
+	require.Eventually(t, func() bool {
+		_, _ = pool.Exec(ctx, `delete from fx_rate_updates`)
+		_, scheduleErr := repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+		require.NoError(t, scheduleErr)
+		select {
+		case <-listener.Wakeups():
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	// a late wakeup of the retries above mustn't be taken for the one checked below
+	time.Sleep(200 * time.Millisecond)
+	select {
+	case <-listener.Wakeups():
+	default:
+	}
+
+	// the existing pending update is returned without a notification
+	_, err = repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	select {
+	case <-listener.Wakeups():
+		t.Fatal("unexpected wakeup for an existing update")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
 func TestRateUpdateRepository_ScheduleNewOrGetExisting_InvalidCurrency_Error(t *testing.T) {
 	pool := setupPostgres(t)
 	repo := postgres.NewRateUpdateRepository(pool)
@@ -252,17 +396,25 @@ func TestRateUpdateRepository_ScheduleNewOrGetExisting_DBError(t *testing.T) {
 	require.Error(t, err)
 }
 
-func TestRateUpdateRepository_GetPending_Empty(t *testing.T) {
+// claimPending claims all pending updates for claimID
+func claimPending(t *testing.T, repo *postgres.RateUpdateRepository, claimID string) []domain.PendingRateUpdate {
+	t.Helper()
+	pending, err := repo.ClaimPending(context.Background(), claimID, time.Minute)
+	require.NoError(t, err)
+	return pending
+}
+
+func TestRateUpdateRepository_ClaimPending_Empty(t *testing.T) {
 	pool := setupPostgres(t)
 	repo := postgres.NewRateUpdateRepository(pool)
 	ctx := context.Background()
 
-	pending, err := repo.GetPending(ctx)
+	pending, err := repo.ClaimPending(ctx, "run-1", time.Minute)
 	require.NoError(t, err)
 	require.Empty(t, pending)
 }
 
-func TestRateUpdateRepository_GetPending_OnlyPendingReturned(t *testing.T) {
+func TestRateUpdateRepository_ClaimPending_OnlyPendingReturned(t *testing.T) {
 	pool := setupPostgres(t)
 	repo := postgres.NewRateUpdateRepository(pool)
 	ctx := context.Background()
@@ -282,7 +434,7 @@ func TestRateUpdateRepository_GetPending_OnlyPendingReturned(t *testing.T) {
 	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status, value) values ($1,$2,'applied', 3.14)`, p2, u2)
 	require.NoError(t, err)
 
-	pending, err := repo.GetPending(ctx)
+	pending, err := repo.ClaimPending(ctx, "run-1", time.Minute)
 	require.NoError(t, err)
 	require.Len(t, pending, 1)
 	require.Equal(t, u1, pending[0].UpdateID)
@@ -291,13 +443,47 @@ func TestRateUpdateRepository_GetPending_OnlyPendingReturned(t *testing.T) {
 	require.Equal(t, "MXN", pending[0].Quote)
 }
 
-func TestRateUpdateRepository_GetPending_DBError(t *testing.T) {
+func TestRateUpdateRepository_ClaimPending_SkipsClaimedUntilLeaseExpires(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('EUR')`)
+	require.NoError(t, err)
+	upd, err := repo.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+
+	require.Len(t, claimPending(t, repo, "run-1"), 1)
+	require.Empty(t, claimPending(t, repo, "run-2"))
+
+	// run-1 died, its lease expired and run-2 claims the update
+	_, err = pool.Exec(ctx, `update fx_rate_updates set claimed_until = now() - interval '1 second' where update_id = $1`, upd)
+	require.NoError(t, err)
+	require.Len(t, claimPending(t, repo, "run-2"), 1)
+
+	// late writes of run-1 don't take effect
+	var pairID int64
+	require.NoError(t, pool.QueryRow(ctx, `select pair_id from fx_rate_updates where update_id = $1`, upd).Scan(&pairID))
+	err = repo.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: decimal.RequireFromString("0.9")}})
+	require.ErrorIs(t, err, domain.ErrClaimLost)
+	require.NoError(t, repo.IncrementAttempts(ctx, "run-1", []uuid.UUID{upd}))
+
+	var attempts int
+	var claimedBy string
+	require.NoError(t, pool.QueryRow(ctx, `select attempts, claimed_by from fx_rate_updates where update_id = $1`, upd).Scan(&attempts, &claimedBy))
+	require.Zero(t, attempts)
+	require.Equal(t, "run-2", claimedBy)
+
+	require.NoError(t, repo.ApplyUpdates(ctx, "run-2", []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: decimal.RequireFromString("0.9")}}))
+}
+
+func TestRateUpdateRepository_ClaimPending_DBError(t *testing.T) {
 	pool := setupPostgres(t)
 	repo := postgres.NewRateUpdateRepository(pool)
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, err := repo.GetPending(ctx)
+	_, err := repo.ClaimPending(ctx, "run-1", time.Minute)
 	require.Error(t, err)
 }
 
@@ -306,9 +492,9 @@ func TestRateUpdateRepository_ApplyUpdates_EmptyNoop(t *testing.T) {
 	repo := postgres.NewRateUpdateRepository(pool)
 	ctx := context.Background()
 
-	err1 := repo.ApplyUpdates(ctx, nil)
+	err1 := repo.ApplyUpdates(ctx, "run-1", nil)
 	require.NoError(t, err1)
-	err2 := repo.ApplyUpdates(ctx, make([]domain.AppliedRateUpdate, 0))
+	err2 := repo.ApplyUpdates(ctx, "run-1", make([]domain.AppliedRateUpdate, 0))
 	require.NoError(t, err2)
 }
 
@@ -328,22 +514,71 @@ func TestRateUpdateRepository_ApplyUpdates_ApplyAndPropagateToLastRates(t *testi
 	require.NoError(t, err)
 
 	// Apply update.
-	err = repo.ApplyUpdates(ctx, []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: 123.4567}})
+	claimPending(t, repo, "run-1")
+	err = repo.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: decimal.RequireFromString("123.4567"), Source: "frankfurter"}})
 	require.NoError(t, err)
 
-	// Verify fx_rate_updates changed to applied with value.
+	// Verify fx_rate_updates changed to applied with value and source.
 	var status domain.RateUpdateStatus
 	var value float64
-	err = pool.QueryRow(ctx, `select status, value from fx_rate_updates where update_id = $1`, upd).Scan(&status, &value)
+	var source string
+	err = pool.QueryRow(ctx, `select status, value, source from fx_rate_updates where update_id = $1`, upd).Scan(&status, &value, &source)
 	require.NoError(t, err)
 	require.Equal(t, domain.StatusApplied, status)
 	require.InDelta(t, 123.4567, value, 0.0000001)
+	require.Equal(t, "frankfurter", source)
+
+	// Verify source is exposed by update ID.
+	rate, _, err := postgres.NewRateRepository(pool).GetByUpdateID(ctx, upd)
+	require.NoError(t, err)
+	require.Equal(t, "frankfurter", rate.Source)
 
 	// Verify fx_last_rates upserted.
 	var lr float64
 	err = pool.QueryRow(ctx, `select value from fx_last_rates where pair_id = $1`, pairID).Scan(&lr)
 	require.NoError(t, err)
 	require.InDelta(t, 123.4567, lr, 0.0000001)
+
+	// Verify fx_rate_history appended.
+	var hv float64
+	err = pool.QueryRow(ctx, `select value from fx_rate_history where pair_id = $1`, pairID).Scan(&hv)
+	require.NoError(t, err)
+	require.InDelta(t, 123.4567, hv, 0.0000001)
+}
+
+func TestRateUpdateRepository_ApplyUpdates_StoresConsensusQuotes(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('EUR')`)
+	require.NoError(t, err)
+
+	var pairID int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','EUR') returning id`).Scan(&pairID))
+	upd := uuid.New()
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status) values ($1,$2,'pending')`, pairID, upd)
+	require.NoError(t, err)
+
+	quotes := []domain.ProviderQuote{
+		{Provider: "exchangerate_api", Value: decimal.RequireFromString("0.92"), Accepted: true},
+		{Provider: "open_er_api", Value: decimal.RequireFromString("0.921"), Accepted: true},
+		{Provider: "frankfurter", Value: decimal.RequireFromString("1.05"), Accepted: false},
+	}
+	claimPending(t, repo, "run-1")
+	err = repo.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: decimal.RequireFromString("0.9205"), Source: "consensus", Quotes: quotes}})
+	require.NoError(t, err)
+
+	rate, status, err := postgres.NewRateRepository(pool).GetByUpdateID(ctx, upd)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusApplied, status)
+	require.Equal(t, "consensus", rate.Source)
+	require.Len(t, rate.Quotes, len(quotes))
+	for i, q := range rate.Quotes {
+		require.Equal(t, quotes[i].Provider, q.Provider)
+		require.True(t, quotes[i].Value.Equal(q.Value), "expected %s, got %s", quotes[i].Value, q.Value)
+		require.Equal(t, quotes[i].Accepted, q.Accepted)
+	}
 }
 
 func TestRateUpdateRepository_ApplyUpdates_PartialApply(t *testing.T) {
@@ -365,7 +600,8 @@ func TestRateUpdateRepository_ApplyUpdates_PartialApply(t *testing.T) {
 	require.NoError(t, err)
 
 	// Apply only one of them.
-	err = repo.ApplyUpdates(ctx, []domain.AppliedRateUpdate{{UpdateID: u1, PairID: p1, Value: 1.5}})
+	claimPending(t, repo, "run-1")
+	err = repo.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: u1, PairID: p1, Value: decimal.RequireFromString("1.5")}})
 	require.NoError(t, err)
 
 	// u1 should be applied, u2 should remain pending.
@@ -376,7 +612,7 @@ func TestRateUpdateRepository_ApplyUpdates_PartialApply(t *testing.T) {
 	require.Equal(t, domain.StatusPending, s2)
 }
 
-func TestRateUpdateRepository_ApplyUpdates_JSONMarshalError_NaN(t *testing.T) {
+func TestRateUpdateRepository_ApplyUpdates_ValueOverflow(t *testing.T) {
 	pool := setupPostgres(t)
 	repo := postgres.NewRateUpdateRepository(pool)
 	ctx := context.Background()
@@ -384,15 +620,20 @@ func TestRateUpdateRepository_ApplyUpdates_JSONMarshalError_NaN(t *testing.T) {
 	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('GBP')`)
 	require.NoError(t, err)
 
-	// Prepare a pending update so that only JSON marshal fails (due to NaN) before touching DB.
+	// Prepare a pending update so that only the value, too large for numeric(16,8), fails.
 	var pairID int64
 	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','GBP') returning id`).Scan(&pairID))
 	upd := uuid.New()
 	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status) values ($1,$2,'pending')`, pairID, upd)
 	require.NoError(t, err)
 
-	err = repo.ApplyUpdates(ctx, []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: math.NaN()}})
+	claimPending(t, repo, "run-1")
+	err = repo.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: upd, PairID: pairID, Value: decimal.RequireFromString("123456789")}})
 	require.Error(t, err)
+
+	var status domain.RateUpdateStatus
+	require.NoError(t, pool.QueryRow(ctx, `select status from fx_rate_updates where update_id = $1`, upd).Scan(&status))
+	require.Equal(t, domain.StatusPending, status) // transaction rolled back
 }
 
 func TestRateUpdateRepository_ApplyUpdates_DBError_BeginTx(t *testing.T) {
@@ -401,6 +642,267 @@ func TestRateUpdateRepository_ApplyUpdates_DBError_BeginTx(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	err := repo.ApplyUpdates(ctx, []domain.AppliedRateUpdate{{UpdateID: uuid.New(), PairID: 1, Value: 1.0}})
+	err := repo.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: uuid.New(), PairID: 1, Value: decimal.NewFromInt(1)}})
 	require.Error(t, err)
 }
+
+func TestRateUpdateRepository_IncrementAttempts_OnlyPending(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('MXN'),('EUR'),('GBP')`)
+	require.NoError(t, err)
+
+	var p1, p2 int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','MXN') returning id`).Scan(&p1))
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('EUR','GBP') returning id`).Scan(&p2))
+	pending, applied := uuid.New(), uuid.New()
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status) values ($1,$2,'pending')`, p1, pending)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status, value) values ($1,$2,'applied', 3.14)`, p2, applied)
+	require.NoError(t, err)
+
+	claimPending(t, repo, "run-1")
+	require.NoError(t, repo.IncrementAttempts(ctx, "run-1", []uuid.UUID{pending, applied}))
+	// released by the first run, so the next one claims it again
+	claimPending(t, repo, "run-2")
+	require.NoError(t, repo.IncrementAttempts(ctx, "run-2", []uuid.UUID{pending}))
+
+	got := claimPending(t, repo, "run-3")
+	require.Len(t, got, 1)
+	require.Equal(t, 2, got[0].Attempts)
+	require.False(t, got[0].CreatedAt.IsZero())
+
+	var appliedAttempts int
+	require.NoError(t, pool.QueryRow(ctx, `select attempts from fx_rate_updates where update_id = $1`, applied).Scan(&appliedAttempts))
+	require.Zero(t, appliedAttempts)
+}
+
+func TestRateUpdateRepository_CloseUpdates_FailsAndExpiresPending(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool)
+	rateRepo := postgres.NewRateRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('MXN'),('EUR'),('GBP')`)
+	require.NoError(t, err)
+
+	var p1, p2 int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','MXN') returning id`).Scan(&p1))
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('EUR','GBP') returning id`).Scan(&p2))
+	failed, expired := uuid.New(), uuid.New()
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status, attempts) values ($1,$2,'pending',9),($3,$4,'pending',0)`, p1, failed, p2, expired)
+	require.NoError(t, err)
+
+	claimPending(t, repo, "run-1")
+	err = repo.CloseUpdates(ctx, "run-1", []domain.ClosedRateUpdate{
+		{UpdateID: failed, Status: domain.StatusFailed, Reason: "rate wasn't fetched after 10 attempts"},
+		{UpdateID: expired, Status: domain.StatusExpired, Reason: "rate wasn't fetched within 1h0m0s"},
+	})
+	require.NoError(t, err)
+
+	pending, err := repo.ClaimPending(ctx, "run-2", time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	rate, status, err := rateRepo.GetByUpdateID(ctx, failed)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusFailed, status)
+	require.Equal(t, 10, rate.Attempts)
+	require.Equal(t, "rate wasn't fetched after 10 attempts", rate.Reason)
+	require.Equal(t, "-1", rate.Value.String())
+
+	rate, status, err = rateRepo.GetByUpdateID(ctx, expired)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusExpired, status)
+	require.Equal(t, "rate wasn't fetched within 1h0m0s", rate.Reason)
+
+	// a closed pair can be scheduled again
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status) values ($1,$2,'pending')`, p1, uuid.New())
+	require.NoError(t, err)
+}
+
+func TestRateUpdateRepository_CloseUpdates_EmptyNoop(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateRepository(pool)
+
+	require.NoError(t, repo.CloseUpdates(context.Background(), "run-1", nil))
+}
+
+func TestRateUpdateCallbackRepository_ClaimDue_OnlyAppliedAndLeased(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateCallbackRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('USD'),('MXN'),('EUR'),('GBP')`)
+	require.NoError(t, err)
+
+	var p1, p2 int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('USD','MXN') returning id`).Scan(&p1))
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('EUR','GBP') returning id`).Scan(&p2))
+	pending, applied := uuid.New(), uuid.New()
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status) values ($1,$2,'pending')`, p1, pending)
+	require.NoError(t, err)
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status, value) values ($1,$2,'applied', 0.8512)`, p2, applied)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Register(ctx, pending, "https://a.example.com"))
+	require.NoError(t, repo.Register(ctx, applied, "https://b.example.com"))
+	require.NoError(t, repo.Register(ctx, applied, "https://b.example.com")) // duplicate is a no-op
+
+	due, err := repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, applied, due[0].UpdateID)
+	require.Equal(t, "https://b.example.com", due[0].URL)
+	require.Equal(t, "EUR", due[0].Base)
+	require.Equal(t, "GBP", due[0].Quote)
+	require.Equal(t, "0.8512", due[0].Value.String())
+	require.Zero(t, due[0].Attempts)
+
+	// leased callbacks aren't claimed again
+	again, err := repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, again)
+}
+
+func TestRateUpdateCallbackRepository_Register_UnknownUpdate_Error(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateCallbackRepository(pool)
+
+	err := repo.Register(context.Background(), uuid.New(), "https://a.example.com")
+	require.Error(t, err)
+}
+
+func TestRateUpdateCallbackRepository_RescheduleDeliverAndFail(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewRateUpdateCallbackRepository(pool)
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, `insert into currencies(code) values ('EUR'),('GBP')`)
+	require.NoError(t, err)
+	var pairID int64
+	require.NoError(t, pool.QueryRow(ctx, `insert into fx_pairs(base, quote) values('EUR','GBP') returning id`).Scan(&pairID))
+	upd := uuid.New()
+	_, err = pool.Exec(ctx, `insert into fx_rate_updates(pair_id, update_id, status, value) values ($1,$2,'applied', 0.85)`, pairID, upd)
+	require.NoError(t, err)
+	require.NoError(t, repo.Register(ctx, upd, "https://a.example.com"))
+	require.NoError(t, repo.Register(ctx, upd, "https://b.example.com"))
+
+	due, err := repo.ClaimDue(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+
+	// rescheduled with no backoff is due right away with the attempt counted
+	require.NoError(t, repo.Reschedule(ctx, due[0].ID, 0, "status 503"))
+	require.NoError(t, repo.MarkFailed(ctx, due[1].ID, "status 500"))
+
+	due, err = repo.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, 1, due[0].Attempts)
+
+	require.NoError(t, repo.MarkDelivered(ctx, due[0].ID))
+
+	rows, err := pool.Query(ctx, `select url, status, attempts, coalesce(last_error, '') from fx_rate_update_callbacks order by url`)
+	require.NoError(t, err)
+	defer rows.Close()
+	type row struct {
+		url, status string
+		attempts    int
+		lastErr     string
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		require.NoError(t, rows.Scan(&r.url, &r.status, &r.attempts, &r.lastErr))
+		got = append(got, r)
+	}
+	require.Equal(t, []row{
+		{url: "https://a.example.com", status: "delivered", attempts: 2},
+		{url: "https://b.example.com", status: "failed", attempts: 1, lastErr: "status 500"},
+	}, got)
+}
+
+// ---------- APIKeyRepository tests ----------
+
+func TestAPIKeyRepository_CreateGetListRevoke(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewAPIKeyRepository(pool)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	created, err := repo.Create(ctx, "hash-1", domain.APIKey{Prefix: "fxr_abcd", Owner: "pricing", Scopes: []string{domain.ScopeRatesRead}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+	require.False(t, created.CreatedAt.IsZero())
+
+	found, err := repo.GetByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, "pricing", found.Owner)
+	require.Equal(t, []string{domain.ScopeRatesRead}, found.Scopes)
+	require.True(t, expiresAt.Equal(*found.ExpiresAt))
+	require.Nil(t, found.RevokedAt)
+
+	_, err = repo.GetByHash(ctx, "hash-unknown")
+	require.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+
+	require.NoError(t, repo.Revoke(ctx, created.ID))
+	require.ErrorIs(t, repo.Revoke(ctx, created.ID), domain.ErrAPIKeyNotFound)
+
+	keys, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.NotNil(t, keys[0].RevokedAt)
+}
+
+// ---------- UpstreamBudget tests ----------
+
+func TestUpstreamBudget_Reserve_GrantsUpToLimitAcrossInstances(t *testing.T) {
+	pool := setupPostgres(t)
+	ctx := context.Background()
+	first := postgres.NewUpstreamBudget(pool, 5, 0)
+	second := postgres.NewUpstreamBudget(pool, 5, 0)
+
+	granted, err := first.Reserve(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, granted)
+
+	granted, err = second.Reserve(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, 2, granted)
+
+	granted, err = first.Reserve(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, 0, granted)
+}
+
+// ---------- CurrencyRepository tests ----------
+
+func TestCurrencyRepository_AddDisableReEnable(t *testing.T) {
+	pool := setupPostgres(t)
+	repo := postgres.NewCurrencyRepository(pool)
+	ctx := context.Background()
+	sek := domain.Currency{Code: "SEK", Name: "Swedish Krona", NumericCode: "752", MinorUnits: 2}
+
+	added, err := repo.Add(ctx, sek)
+	require.NoError(t, err)
+	require.True(t, added.Enabled)
+
+	_, err = repo.Add(ctx, sek)
+	require.ErrorIs(t, err, domain.ErrCurrencyExists)
+
+	require.NoError(t, repo.Disable(ctx, "SEK"))
+	require.ErrorIs(t, repo.Disable(ctx, "SEK"), domain.ErrCurrencyNotFound)
+
+	sek.Name = "Krona"
+	readded, err := repo.Add(ctx, sek)
+	require.NoError(t, err)
+	require.Equal(t, domain.Currency{Code: "SEK", Name: "Krona", NumericCode: "752", MinorUnits: 2, Enabled: true}, readded)
+
+	currencies, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Currency{readded}, currencies)
+}
@@ -2,14 +2,15 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"fxrates/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
 )
 
 type RateRepository struct {
@@ -18,7 +19,7 @@ type RateRepository struct {
 
 func (r *RateRepository) GetByCodes(ctx context.Context, base string, quote string) (domain.Rate, error) {
 	const q = `
-        select fp.id, fp.base, fp.quote, round(flr.value, 4) as value, flr.updated_at
+        select fp.id, fp.base, fp.quote, flr.value, flr.updated_at
         from fx_last_rates flr join fx_pairs fp on flr.pair_id = fp.id
         where fp.base = $1 and fp.quote = $2;
     `
@@ -45,16 +46,24 @@ func (r *RateRepository) GetByUpdateID(ctx context.Context, updateID uuid.UUID)
             select fp.id, 
                fp.base, 
                fp.quote, 
-               case when fru.status = 'applied' then round(fru.value, 4) end as value,
+               case when fru.status = 'applied' then fru.value end as value,
                fru.updated_at, 
-               fru.status
+               fru.status,
+               coalesce(fru.source, '') as source,
+               fru.attempts,
+               coalesce(fru.reason, '') as reason,
+               coalesce((
+                 select json_agg(json_build_object('provider', q.provider, 'value', q.value, 'accepted', q.accepted) order by q.id)
+                 from fx_rate_update_quotes q
+                 where q.update_id = fru.update_id
+               ), '[]') as quotes
             from fx_rate_updates fru join fx_pairs fp on fru.pair_id = fp.id
             where fru.update_id = $1;
         `
 
 	var rate domain.Rate
 	var status domain.RateUpdateStatus
-	var value sql.NullFloat64
+	var value decimal.NullDecimal
 
 	if err := r.pool.QueryRow(ctx, q, updateID).Scan(
 		&rate.PairID,
@@ -63,6 +72,10 @@ func (r *RateRepository) GetByUpdateID(ctx context.Context, updateID uuid.UUID)
 		&value,
 		&rate.UpdatedAt,
 		&status,
+		&rate.Source,
+		&rate.Attempts,
+		&rate.Reason,
+		&rate.Quotes,
 	); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return domain.Rate{}, "", domain.ErrRateNotFound
@@ -70,13 +83,62 @@ func (r *RateRepository) GetByUpdateID(ctx context.Context, updateID uuid.UUID)
 		return domain.Rate{}, "", fmt.Errorf("failed to select rate for update ID %q: %w", updateID, err)
 	}
 	if value.Valid {
-		rate.Value = value.Float64
+		rate.Value = value.Decimal
 	} else {
-		rate.Value = -1 // explicitly set bad value
+		rate.Value = decimal.NewFromInt(-1) // explicitly set bad value
 	}
 	return rate, status, nil
 }
 
+// GetHistory returns applied values of the pair recorded within [from, to) ordered by time.
+// When interval is positive, points are bucketed and the last value of each bucket is returned
+func (r *RateRepository) GetHistory(ctx context.Context, base string, quote string, from time.Time, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error) {
+	const rawQ = `
+        select h.value, h.recorded_at
+        from fx_rate_history h join fx_pairs fp on h.pair_id = fp.id
+        where fp.base = $1 and fp.quote = $2 and h.recorded_at >= $3 and h.recorded_at < $4
+        order by h.recorded_at;
+    `
+	const bucketedQ = `
+        select distinct on (b.bucket) b.value, b.bucket
+        from (
+            select date_bin($5::float8 * interval '1 second', h.recorded_at, timestamptz 'epoch') as bucket,
+                   h.value,
+                   h.recorded_at
+            from fx_rate_history h join fx_pairs fp on h.pair_id = fp.id
+            where fp.base = $1 and fp.quote = $2 and h.recorded_at >= $3 and h.recorded_at < $4
+        ) b
+        order by b.bucket, b.recorded_at desc;
+    `
+
+	var (
+		rows pgx.Rows
+		err  error
+	)
+	if interval > 0 {
+		rows, err = r.pool.Query(ctx, bucketedQ, base, quote, from, to, interval.Seconds())
+	} else {
+		rows, err = r.pool.Query(ctx, rawQ, base, quote, from, to)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history for pair %q/%q: %w", base, quote, err)
+	}
+	defer rows.Close()
+
+	points := make([]domain.RateHistoryPoint, 0, 64)
+	for rows.Next() {
+		var p domain.RateHistoryPoint
+		if err = rows.Scan(&p.Value, &p.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan history point: %w", err)
+		}
+		points = append(points, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history points: %w", err)
+	}
+	return points, nil
+}
+
 func NewRateRepository(pool *pgxpool.Pool) *RateRepository {
 	return &RateRepository{pool: pool}
 }
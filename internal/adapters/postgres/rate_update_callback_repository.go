@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type RateUpdateCallbackRepository struct {
+	pool *pgxpool.Pool
+}
+
+// Register adds a callback to the update, registering the same URL twice is a no-op
+func (r *RateUpdateCallbackRepository) Register(ctx context.Context, updateID uuid.UUID, url string) error {
+	const q = `
+		insert into fx_rate_update_callbacks(update_id, url) values ($1, $2)
+		on conflict (update_id, url) do nothing;
+	`
+
+	if _, err := r.pool.Exec(ctx, q, updateID, url); err != nil {
+		return fmt.Errorf("failed to register callback for update ID %q: %w", updateID, err)
+	}
+	return nil
+}
+
+// ClaimDue returns pending callbacks of finished (applied, failed or expired) updates whose next attempt is due and postpones them by the lease,
+// so concurrent runs (or instances) skip them while they're being delivered. Unfinished deliveries are retried after the lease
+func (r *RateUpdateCallbackRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.RateUpdateCallback, error) {
+	const q = `
+		with due as (
+		  select c.id
+		  from fx_rate_update_callbacks c join fx_rate_updates fru on fru.update_id = c.update_id
+		  where c.status = 'pending' and c.next_attempt_at <= now() and fru.status in ('applied', 'failed', 'expired')
+		  order by c.next_attempt_at
+		  limit $1
+		  for update of c skip locked
+		)
+		update fx_rate_update_callbacks c
+		set next_attempt_at = now() + $2::double precision * interval '1 second', updated_at = now()
+		from due, fx_rate_updates fru, fx_pairs fp
+		where c.id = due.id and fru.update_id = c.update_id and fp.id = fru.pair_id
+		returning c.id, c.update_id, c.url, c.attempts, fp.base, fp.quote, fru.status, fru.value, coalesce(fru.reason, ''), fru.updated_at;
+	`
+
+	rows, err := r.pool.Query(ctx, q, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due callbacks: %w", err)
+	}
+	defer rows.Close()
+
+	callbacks := make([]domain.RateUpdateCallback, 0, limit)
+	for rows.Next() {
+		var c domain.RateUpdateCallback
+		var value decimal.NullDecimal // closed updates have no value
+		if err = rows.Scan(&c.ID, &c.UpdateID, &c.URL, &c.Attempts, &c.Base, &c.Quote, &c.Status, &value, &c.Reason, &c.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan callback: %w", err)
+		}
+		c.Value = value.Decimal
+		callbacks = append(callbacks, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating callbacks: %w", err)
+	}
+	return callbacks, nil
+}
+
+func (r *RateUpdateCallbackRepository) MarkDelivered(ctx context.Context, id int64) error {
+	const q = `
+		update fx_rate_update_callbacks
+		set status = 'delivered', attempts = attempts + 1, last_error = null, updated_at = now()
+		where id = $1;
+	`
+
+	if _, err := r.pool.Exec(ctx, q, id); err != nil {
+		return fmt.Errorf("failed to mark callback %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+// Reschedule counts the failed attempt and postpones the next one by the given backoff
+func (r *RateUpdateCallbackRepository) Reschedule(ctx context.Context, id int64, after time.Duration, lastErr string) error {
+	const q = `
+		update fx_rate_update_callbacks
+		set attempts = attempts + 1, next_attempt_at = now() + $2::double precision * interval '1 second', last_error = $3, updated_at = now()
+		where id = $1;
+	`
+
+	if _, err := r.pool.Exec(ctx, q, id, after.Seconds(), lastErr); err != nil {
+		return fmt.Errorf("failed to reschedule callback %d: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed counts the last failed attempt and stops delivering the callback
+func (r *RateUpdateCallbackRepository) MarkFailed(ctx context.Context, id int64, lastErr string) error {
+	const q = `
+		update fx_rate_update_callbacks
+		set status = 'failed', attempts = attempts + 1, last_error = $2, updated_at = now()
+		where id = $1;
+	`
+
+	if _, err := r.pool.Exec(ctx, q, id, lastErr); err != nil {
+		return fmt.Errorf("failed to mark callback %d failed: %w", id, err)
+	}
+	return nil
+}
+
+func NewRateUpdateCallbackRepository(pool *pgxpool.Pool) *RateUpdateCallbackRepository {
+	return &RateUpdateCallbackRepository{pool: pool}
+}
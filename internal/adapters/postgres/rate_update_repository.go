@@ -5,14 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"fxrates/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
 )
 
 type RateUpdateRepository struct {
 	pool *pgxpool.Pool
+	// notifyChannel gets a notification once new pending updates are inserted, empty disables it
+	notifyChannel string
 }
 
 func (r *RateUpdateRepository) ScheduleNewOrGetExisting(ctx context.Context, base string, quote string) (uuid.UUID, error) {
@@ -29,34 +33,115 @@ func (r *RateUpdateRepository) ScheduleNewOrGetExisting(ctx context.Context, bas
         select p.id, $3, 'pending', now() from pair p
 		on conflict (pair_id) where status = 'pending'
 		do update set updated_at = fx_rate_updates.updated_at
-        returning update_id;
+        returning update_id, xmax = 0 as inserted;
 	`
 
 	var updateID uuid.UUID
-	err := r.pool.QueryRow(ctx, q, base, quote, uuid.New()).Scan(&updateID)
+	var inserted bool
+	err := r.pool.QueryRow(ctx, q, base, quote, uuid.New()).Scan(&updateID, &inserted)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to ensure an update for '%s/%s': %w", base, quote, err)
 	}
+	if inserted {
+		r.notify(ctx)
+	}
 	return updateID, nil
 }
 
-func (r *RateUpdateRepository) GetPending(ctx context.Context) ([]domain.PendingRateUpdate, error) {
+// ScheduleNewOrGetExistingBatch does the same as ScheduleNewOrGetExisting for many pairs in a single round trip.
+// Pairs must be unique, as a statement can't upsert the same row twice
+func (r *RateUpdateRepository) ScheduleNewOrGetExistingBatch(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error) {
+	if len(pairs) == 0 {
+		return map[domain.RatePair]uuid.UUID{}, nil
+	}
+
 	const q = `
-		select fru.update_id, fru.pair_id, fp.base, fp.quote
-		from fx_rate_updates fru join fx_pairs fp on fp.id = fru.pair_id
-		where fru.status = 'pending';
+		with
+		input_rows as (select * from unnest($1::text[], $2::text[], $3::uuid[]) as i(base, quote, update_id)),
+
+		-- 1) ensure pairs exist and get their ids
+		pair as (
+		  insert into fx_pairs(base, quote) select base, quote from input_rows
+		  on conflict (base, quote) do update
+		    set base = excluded.base   -- no-op, just to return id
+		  returning id, base, quote
+		),
+
+		-- 2) insert pending updates or fetch existing update_ids
+		upd as (
+		  insert into fx_rate_updates (pair_id, update_id, status, updated_at)
+		  select p.id, ir.update_id, 'pending', now() from pair p join input_rows ir on ir.base = p.base and ir.quote = p.quote
+		  on conflict (pair_id) where status = 'pending'
+		  do update set updated_at = fx_rate_updates.updated_at
+		  returning pair_id, update_id, xmax = 0 as inserted  -- xmax is zero for inserted rows only
+		)
+		select p.base, p.quote, u.update_id, u.inserted from upd u join pair p on p.id = u.pair_id;
 	`
 
-	rows, err := r.pool.Query(ctx, q)
+	bases := make([]string, 0, len(pairs))
+	quotes := make([]string, 0, len(pairs))
+	newIDs := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		bases = append(bases, p.Base)
+		quotes = append(quotes, p.Quote)
+		newIDs = append(newIDs, uuid.NewString())
+	}
+
+	rows, err := r.pool.Query(ctx, q, bases, quotes, newIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query pending rates: %w", err)
+		return nil, fmt.Errorf("failed to ensure updates for %d pairs: %w", len(pairs), err)
+	}
+	defer rows.Close()
+
+	updateIDs := make(map[domain.RatePair]uuid.UUID, len(pairs))
+	anyInserted := false
+	for rows.Next() {
+		var p domain.RatePair
+		var updateID uuid.UUID
+		var inserted bool
+		if err = rows.Scan(&p.Base, &p.Quote, &updateID, &inserted); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled update: %w", err)
+		}
+		updateIDs[p] = updateID
+		anyInserted = anyInserted || inserted
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to ensure updates for %d pairs: %w", len(pairs), err)
+	}
+	if anyInserted {
+		r.notify(ctx)
+	}
+	return updateIDs, nil
+}
+
+// ClaimPending claims pending updates not claimed by another run (or whose lease expired) for the lease.
+// Locked rows are skipped, so concurrent runs of replicas never get the same updates; updates of a run
+// that died mid-way are claimed again once its lease expires
+func (r *RateUpdateRepository) ClaimPending(ctx context.Context, claimID string, lease time.Duration) ([]domain.PendingRateUpdate, error) {
+	const q = `
+		with claimable as (
+		  select id
+		  from fx_rate_updates
+		  where status = 'pending' and (claimed_until is null or claimed_until <= now())
+		  for update skip locked
+		)
+		update fx_rate_updates fru
+		set claimed_by = $1, claimed_until = now() + $2::double precision * interval '1 second'
+		from claimable c, fx_pairs fp
+		where fru.id = c.id and fp.id = fru.pair_id
+		returning fru.update_id, fru.pair_id, fp.base, fp.quote, fru.attempts, fru.created_at;
+	`
+
+	rows, err := r.pool.Query(ctx, q, claimID, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pending rates: %w", err)
 	}
 	defer rows.Close()
 
 	pending := make([]domain.PendingRateUpdate, 0, 64)
 	for rows.Next() {
 		var pr domain.PendingRateUpdate
-		if err = rows.Scan(&pr.UpdateID, &pr.PairID, &pr.Base, &pr.Quote); err != nil {
+		if err = rows.Scan(&pr.UpdateID, &pr.PairID, &pr.Base, &pr.Quote, &pr.Attempts, &pr.CreatedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan pending rate: %w", err)
 		}
 		pending = append(pending, pr)
@@ -67,7 +152,9 @@ func (r *RateUpdateRepository) GetPending(ctx context.Context) ([]domain.Pending
 	return pending, nil
 }
 
-func (r *RateUpdateRepository) ApplyUpdates(ctx context.Context, applied []domain.AppliedRateUpdate) error {
+// ApplyUpdates applies values of updates still claimed by claimID all at once. Updates reclaimed by another run
+// meanwhile are left to it and reported by a *domain.ClaimLostError once the rest is committed
+func (r *RateUpdateRepository) ApplyUpdates(ctx context.Context, claimID string, applied []domain.AppliedRateUpdate) error {
 	if len(applied) == 0 {
 		return nil
 	}
@@ -81,22 +168,42 @@ func (r *RateUpdateRepository) ApplyUpdates(ctx context.Context, applied []domai
 		with
 		
 		-- step 1: parsing input
-		input_rows as (select * from json_to_recordset($1::json) as r(update_id uuid, pair_id bigint, value numeric)),
+		input_rows as (select * from json_to_recordset($1::json) as r(update_id uuid, pair_id bigint, value numeric, source text, quotes json)),
 		
 		-- step 2: updating fx_rate_updates records and get updated
 		update_fru as (
 		  update fx_rate_updates fru
-		  set value = ir.value, updated_at = now(), status = 'applied'
+		  set value = ir.value, source = nullif(ir.source, ''), updated_at = now(), status = 'applied', claimed_until = null
 		  from input_rows ir 
-		  where fru.update_id = ir.update_id
-		  returning fru.pair_id, fru.value
+		  where fru.update_id = ir.update_id and fru.status = 'pending' and fru.claimed_by = $2
+		  returning fru.update_id, fru.pair_id, fru.value
+		),
+		
+		-- step 3: appending applied values to fx_rate_history
+		insert_history as (
+		  insert into fx_rate_history(pair_id, value, recorded_at)
+		  select pair_id, value, now() from update_fru
+		),
+		
+		-- step 4: keeping per-provider quotes of consensus values
+		insert_quotes as (
+		  insert into fx_rate_update_quotes(update_id, provider, value, accepted)
+		  select ufru.update_id, q.provider, q.value, q.accepted
+		  from update_fru ufru
+		    join input_rows ir on ir.update_id = ufru.update_id
+		    cross join lateral json_to_recordset(coalesce(ir.quotes, '[]'::json)) as q(provider text, value numeric, accepted boolean)
+		),
+		
+		-- step 5: updating fx_last_rates records
+		upsert_last_rates as (
+		  insert into fx_last_rates(pair_id, value, updated_at)
+		  select pair_id, value, now() from update_fru
+		  on conflict (pair_id) do update
+		  set value = excluded.value, updated_at = now()
 		)
 		
-		-- step 3: updating fx_last_rates records
-		insert into fx_last_rates(pair_id, value, updated_at)
-		select pair_id, value, now() from update_fru
-		on conflict (pair_id) do update
-		set value = excluded.value, updated_at = now();
+		-- step 6: returning applied updates, the rest was reclaimed
+		select update_id from update_fru;
 	`
 
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
@@ -105,16 +212,127 @@ func (r *RateUpdateRepository) ApplyUpdates(ctx context.Context, applied []domai
 	}
 	defer func() { _ = tx.Rollback(ctx) }()
 
-	_, err = tx.Exec(ctx, q, json.RawMessage(payloadJSON))
+	rows, err := tx.Query(ctx, q, json.RawMessage(payloadJSON), claimID)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
+	updated := make(map[uuid.UUID]struct{}, len(applied))
+	for rows.Next() {
+		var updateID uuid.UUID
+		if err = rows.Scan(&updateID); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan applied update: %w", err)
+		}
+		updated[updateID] = struct{}{}
+	}
+	// rows hold the connection of the transaction, they're closed before the commit
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
 	if err = tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+
+	if len(updated) == len(applied) {
+		return nil
+	}
+	lost := make([]uuid.UUID, 0, len(applied)-len(updated))
+	for _, a := range applied {
+		if _, ok := updated[a.UpdateID]; !ok {
+			lost = append(lost, a.UpdateID)
+		}
+	}
+	return &domain.ClaimLostError{UpdateIDs: lost}
+}
+
+// IncrementAttempts counts one more failed fetch attempt of still pending updates claimed by claimID
+// and releases them, so the next run of any replica retries them
+func (r *RateUpdateRepository) IncrementAttempts(ctx context.Context, claimID string, updateIDs []uuid.UUID) error {
+	if len(updateIDs) == 0 {
+		return nil
+	}
+
+	const q = `
+		update fx_rate_updates
+		set attempts = attempts + 1, claimed_by = null, claimed_until = null
+		where update_id = any($1::uuid[]) and status = 'pending' and claimed_by = $2;
+	`
+
+	ids := make([]string, 0, len(updateIDs))
+	for _, id := range updateIDs {
+		ids = append(ids, id.String())
+	}
+	if _, err := r.pool.Exec(ctx, q, ids, claimID); err != nil {
+		return fmt.Errorf("failed to increment attempts: %w", err)
+	}
+	return nil
+}
+
+// ReleaseClaims releases still pending updates claimed by claimID without counting an attempt,
+// as their rates weren't even asked for. The next run of any replica picks them up
+func (r *RateUpdateRepository) ReleaseClaims(ctx context.Context, claimID string, updateIDs []uuid.UUID) error {
+	if len(updateIDs) == 0 {
+		return nil
+	}
+
+	const q = `
+		update fx_rate_updates
+		set claimed_by = null, claimed_until = null
+		where update_id = any($1::uuid[]) and status = 'pending' and claimed_by = $2;
+	`
+
+	ids := make([]string, 0, len(updateIDs))
+	for _, id := range updateIDs {
+		ids = append(ids, id.String())
+	}
+	if _, err := r.pool.Exec(ctx, q, ids, claimID); err != nil {
+		return fmt.Errorf("failed to release claims: %w", err)
+	}
+	return nil
+}
+
+// CloseUpdates moves still pending updates claimed by claimID to failed or expired status with a reason, counting the last attempt
+func (r *RateUpdateRepository) CloseUpdates(ctx context.Context, claimID string, closed []domain.ClosedRateUpdate) error {
+	if len(closed) == 0 {
+		return nil
+	}
+
+	payloadJSON, err := json.Marshal(closed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal closed updates: %w", err)
+	}
+
+	const q = `
+		update fx_rate_updates fru
+		set status = ir.status, reason = ir.reason, attempts = fru.attempts + 1, updated_at = now(), claimed_until = null
+		from json_to_recordset($1::json) as ir(update_id uuid, status text, reason text)
+		where fru.update_id = ir.update_id and fru.status = 'pending' and fru.claimed_by = $2;
+	`
+
+	if _, err = r.pool.Exec(ctx, q, json.RawMessage(payloadJSON), claimID); err != nil {
+		return fmt.Errorf("failed to close updates: %w", err)
+	}
 	return nil
 }
 
+// notify wakes listeners of the channel up. The update is stored already and the periodic run picks it up anyway,
+// so a failed notification is only logged
+func (r *RateUpdateRepository) notify(ctx context.Context) {
+	if r.notifyChannel == "" {
+		return
+	}
+	if _, err := r.pool.Exec(ctx, `select pg_notify($1, '')`, r.notifyChannel); err != nil {
+		logrus.Warnf("Notification of scheduled updates wasn't sent: %v", err)
+	}
+}
+
 func NewRateUpdateRepository(pool *pgxpool.Pool) *RateUpdateRepository {
 	return &RateUpdateRepository{pool: pool}
 }
+
+// WithNotify makes newly scheduled updates notify the channel, see UpdateListener
+func (r *RateUpdateRepository) WithNotify(channel string) *RateUpdateRepository {
+	r.notifyChannel = channel
+	return r
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// RateUpdatesChannel is notified by RateUpdateRepository.WithNotify once new pending updates are inserted
+const RateUpdatesChannel = "fx_rate_updates"
+
+const (
+	listenInitialBackoff = time.Second
+	listenMaxBackoff     = 30 * time.Second
+)
+
+// UpdateListener listens to notifications of scheduled updates on a dedicated connection (outside the pool,
+// as LISTEN needs a connection of its own for as long as it runs) and turns them into wakeups
+type UpdateListener struct {
+	connConfig *pgx.ConnConfig
+	channel    string
+	// wakeups has room for a single wakeup, notifications arriving before it's read are coalesced
+	wakeups chan struct{}
+}
+
+// Wakeups receives a value after notifications
+func (l *UpdateListener) Wakeups() <-chan struct{} {
+	return l.wakeups
+}
+
+// Listen blocks until ctx is done, reconnecting with backoff when the connection is lost.
+// Notifications sent while disconnected are missed, the periodic run picks their updates up
+func (l *UpdateListener) Listen(ctx context.Context) {
+	backoff := listenInitialBackoff
+	for {
+		start := time.Now()
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(start) > listenMaxBackoff {
+			backoff = listenInitialBackoff // the connection was fine for a while
+		}
+		logrus.Warnf("Listening to %q failed, reconnecting in %s: %v", l.channel, backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, listenMaxBackoff)
+	}
+}
+
+func (l *UpdateListener) listen(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err = conn.Exec(ctx, "listen "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		if _, err = conn.WaitForNotification(ctx); err != nil {
+			return err
+		}
+		select {
+		case l.wakeups <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// NewUpdateListener creates a listener of the channel connecting with the settings of the pool
+func NewUpdateListener(pool *pgxpool.Pool, channel string) *UpdateListener {
+	return &UpdateListener{
+		connConfig: pool.Config().ConnConfig.Copy(),
+		channel:    channel,
+		wakeups:    make(chan struct{}, 1),
+	}
+}
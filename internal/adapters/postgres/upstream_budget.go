@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/ratelimit"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UpstreamBudget is an upstream budget shared by all instances, counted in fixed minute and day windows (UTC)
+type UpstreamBudget struct {
+	pool      *pgxpool.Pool
+	perMinute int
+	perDay    int
+}
+
+// Reserve takes up to n fetches from the budget and returns how many were granted.
+// Current windows are locked for the transaction, so concurrent instances never overspend
+func (b *UpstreamBudget) Reserve(ctx context.Context, n int) (int, error) {
+	// "do update" locks existing windows as well as creates new ones
+	const lockWindows = `
+		insert into upstream_budget(window_kind, window_start)
+		values ('minute', date_trunc('minute', now(), 'UTC')), ('day', date_trunc('day', now(), 'UTC'))
+		on conflict (window_kind, window_start) do update set used = upstream_budget.used
+		returning window_kind, used;
+	`
+	const spend = `
+		update upstream_budget set used = used + $1
+		where (window_kind = 'minute' and window_start = date_trunc('minute', now(), 'UTC'))
+		   or (window_kind = 'day' and window_start = date_trunc('day', now(), 'UTC'));
+	`
+	const cleanUp = `
+		delete from upstream_budget
+		where (window_kind = 'minute' and window_start < now() - interval '1 hour')
+		   or (window_kind = 'day' and window_start < now() - interval '2 days');
+	`
+
+	granted := 0
+	err := pgx.BeginFunc(ctx, b.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, lockWindows)
+		if err != nil {
+			return err
+		}
+		used := make(map[string]int, 2)
+		for rows.Next() {
+			var kind string
+			var u int
+			if err = rows.Scan(&kind, &u); err != nil {
+				rows.Close()
+				return err
+			}
+			used[kind] = u
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+
+		granted = ratelimit.Grant(n, b.perMinute, used["minute"], b.perDay, used["day"])
+		if granted > 0 {
+			if _, err = tx.Exec(ctx, spend, granted); err != nil {
+				return err
+			}
+		}
+		_, err = tx.Exec(ctx, cleanUp)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reserve upstream budget: %w", err)
+	}
+	return granted, nil
+}
+
+// NewUpstreamBudget creates a budget of perMinute and perDay fetches, a non-positive limit means unlimited
+func NewUpstreamBudget(pool *pgxpool.Pool, perMinute int, perDay int) *UpstreamBudget {
+	return &UpstreamBudget{pool: pool, perMinute: perMinute, perDay: perDay}
+}
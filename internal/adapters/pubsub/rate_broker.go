@@ -0,0 +1,93 @@
+package pubsub
+
+import (
+	"fxrates/internal/domain"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RateBroker is an in-process pub/sub of applied rate changes. Each subscriber gets changes of its pairs only
+// through a buffered channel; changes not fitting into a slow subscriber's buffer are dropped rather than blocking the publisher
+type RateBroker struct {
+	mu         sync.RWMutex
+	subs       map[*subscription]struct{}
+	bufferSize int
+	closed     bool
+}
+
+type subscription struct {
+	pairs map[domain.RatePair]struct{}
+	ch    chan domain.RateChange
+}
+
+func (b *RateBroker) Publish(changes []domain.RateChange) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subs {
+		for _, change := range changes {
+			if _, ok := sub.pairs[domain.RatePair{Base: change.Base, Quote: change.Quote}]; !ok {
+				continue
+			}
+			select {
+			case sub.ch <- change:
+			default:
+				logrus.Warnf("Rate change of '%s/%s' was dropped for a slow subscriber", change.Base, change.Quote)
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel of changes of the given pairs and a func to unsubscribe.
+// The channel is closed on unsubscribe or when the broker is closed
+func (b *RateBroker) Subscribe(pairs []domain.RatePair) (<-chan domain.RateChange, func()) {
+	sub := &subscription{
+		pairs: make(map[domain.RatePair]struct{}, len(pairs)),
+		ch:    make(chan domain.RateChange, b.bufferSize),
+	}
+	for _, p := range pairs {
+		sub.pairs[p] = struct{}{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	b.subs[sub] = struct{}{}
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			if _, ok := b.subs[sub]; ok {
+				delete(b.subs, sub)
+				close(sub.ch)
+			}
+		})
+	}
+}
+
+// Close ends all subscriptions, so streaming clients are disconnected on shutdown
+func (b *RateBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		close(sub.ch)
+	}
+	b.subs = nil
+}
+
+func NewRateBroker(bufferSize int) *RateBroker {
+	if bufferSize <= 0 {
+		bufferSize = 64
+	}
+	return &RateBroker{subs: make(map[*subscription]struct{}), bufferSize: bufferSize}
+}
@@ -0,0 +1,68 @@
+package pubsub
+
+import (
+	"testing"
+
+	"fxrates/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+func change(base, quote, value string) domain.RateChange {
+	return domain.RateChange{UpdateID: uuid.New(), Base: base, Quote: quote, Value: decimal.RequireFromString(value)}
+}
+
+func TestRateBroker_FiltersBySubscribedPairs(t *testing.T) {
+	b := NewRateBroker(8)
+	usdEUR, unsubscribe := b.Subscribe([]domain.RatePair{{Base: "USD", Quote: "EUR"}})
+	defer unsubscribe()
+	gbpJPY, unsubscribe2 := b.Subscribe([]domain.RatePair{{Base: "GBP", Quote: "JPY"}, {Base: "USD", Quote: "EUR"}})
+	defer unsubscribe2()
+
+	b.Publish([]domain.RateChange{change("USD", "EUR", "0.92"), change("GBP", "JPY", "190.5"), change("EUR", "USD", "1.08")})
+
+	require.Len(t, usdEUR, 1)
+	require.Equal(t, "EUR", (<-usdEUR).Quote)
+	require.Len(t, gbpJPY, 2)
+	require.Equal(t, "EUR", (<-gbpJPY).Quote)
+	require.Equal(t, "JPY", (<-gbpJPY).Quote)
+}
+
+func TestRateBroker_SlowSubscriber_DropsInsteadOfBlocking(t *testing.T) {
+	b := NewRateBroker(1)
+	ch, unsubscribe := b.Subscribe([]domain.RatePair{{Base: "USD", Quote: "EUR"}})
+	defer unsubscribe()
+
+	b.Publish([]domain.RateChange{change("USD", "EUR", "0.91"), change("USD", "EUR", "0.92")})
+
+	require.Len(t, ch, 1)
+	require.Equal(t, "0.91", (<-ch).Value.String())
+}
+
+func TestRateBroker_Unsubscribe_ClosesChannel(t *testing.T) {
+	b := NewRateBroker(8)
+	ch, unsubscribe := b.Subscribe([]domain.RatePair{{Base: "USD", Quote: "EUR"}})
+
+	unsubscribe()
+	unsubscribe() // idempotent
+
+	_, ok := <-ch
+	require.False(t, ok)
+	b.Publish([]domain.RateChange{change("USD", "EUR", "0.92")}) // no panic on closed subscription
+}
+
+func TestRateBroker_Close_EndsSubscriptions(t *testing.T) {
+	b := NewRateBroker(8)
+	ch, unsubscribe := b.Subscribe([]domain.RatePair{{Base: "USD", Quote: "EUR"}})
+
+	b.Close()
+	_, ok := <-ch
+	require.False(t, ok)
+	unsubscribe() // safe after close
+
+	late, _ := b.Subscribe([]domain.RatePair{{Base: "USD", Quote: "EUR"}})
+	_, ok = <-late
+	require.False(t, ok)
+}
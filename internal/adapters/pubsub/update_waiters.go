@@ -0,0 +1,98 @@
+package pubsub
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// ErrTooManyWaiters is returned by Wait once the limit of concurrent waiters is reached
+var ErrTooManyWaiters = errors.New("too many update waiters")
+
+// UpdateWaiters wakes requests waiting for pending updates once update runs apply or close them.
+// The number of concurrent waiters is bounded, so long-polling clients can't pile up goroutines
+type UpdateWaiters struct {
+	mu      sync.Mutex
+	waiters map[uuid.UUID]map[chan struct{}]struct{}
+	count   int
+	limit   int
+	closed  bool
+}
+
+// Wait returns a channel closed when the update is finished and a func to stop waiting.
+// The channel is closed right away when the hub is closed, so waiters don't hold shutdown
+func (w *UpdateWaiters) Wait(updateID uuid.UUID) (<-chan struct{}, func(), error) {
+	ch := make(chan struct{})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	if w.count >= w.limit {
+		return nil, nil, ErrTooManyWaiters
+	}
+	if w.waiters[updateID] == nil {
+		w.waiters[updateID] = make(map[chan struct{}]struct{})
+	}
+	w.waiters[updateID][ch] = struct{}{}
+	w.count++
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if _, ok := w.waiters[updateID][ch]; ok {
+				w.remove(updateID, ch)
+			}
+		})
+	}, nil
+}
+
+// NotifyFinished wakes all requests waiting for the given updates
+func (w *UpdateWaiters) NotifyFinished(updateIDs []uuid.UUID) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range updateIDs {
+		for ch := range w.waiters[id] {
+			close(ch)
+			w.remove(id, ch)
+		}
+	}
+}
+
+// Close wakes all waiters, so long-polling requests end on shutdown
+func (w *UpdateWaiters) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	for _, chans := range w.waiters {
+		for ch := range chans {
+			close(ch)
+		}
+	}
+	w.waiters = nil
+	w.count = 0
+}
+
+// remove forgets the waiter, w.mu must be held
+func (w *UpdateWaiters) remove(updateID uuid.UUID, ch chan struct{}) {
+	delete(w.waiters[updateID], ch)
+	if len(w.waiters[updateID]) == 0 {
+		delete(w.waiters, updateID)
+	}
+	w.count--
+}
+
+func NewUpdateWaiters(limit int) *UpdateWaiters {
+	if limit <= 0 {
+		limit = 1000
+	}
+	return &UpdateWaiters{waiters: make(map[uuid.UUID]map[chan struct{}]struct{}), limit: limit}
+}
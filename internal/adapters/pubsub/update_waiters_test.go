@@ -0,0 +1,87 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestUpdateWaiters_NotifyFinished_WakesWaitersOfThatUpdate(t *testing.T) {
+	w := NewUpdateWaiters(8)
+	id, other := uuid.New(), uuid.New()
+	first, stop1, err := w.Wait(id)
+	require.NoError(t, err)
+	defer stop1()
+	second, stop2, err := w.Wait(id)
+	require.NoError(t, err)
+	defer stop2()
+	unrelated, stop3, err := w.Wait(other)
+	require.NoError(t, err)
+	defer stop3()
+
+	w.NotifyFinished([]uuid.UUID{id})
+
+	require.True(t, isClosed(first))
+	require.True(t, isClosed(second))
+	require.False(t, isClosed(unrelated))
+	require.Equal(t, 1, w.count)
+}
+
+func TestUpdateWaiters_Limit_FreedByStopAndNotify(t *testing.T) {
+	w := NewUpdateWaiters(2)
+	id := uuid.New()
+	_, stop, err := w.Wait(id)
+	require.NoError(t, err)
+	_, _, err = w.Wait(id)
+	require.NoError(t, err)
+
+	_, _, err = w.Wait(id)
+	require.ErrorIs(t, err, ErrTooManyWaiters)
+
+	stop()
+	stop() // idempotent
+	_, _, err = w.Wait(uuid.New())
+	require.NoError(t, err)
+
+	w.NotifyFinished([]uuid.UUID{id})
+	_, _, err = w.Wait(uuid.New())
+	require.NoError(t, err)
+}
+
+func TestUpdateWaiters_StopAfterNotify_DoesNotFreeTwice(t *testing.T) {
+	w := NewUpdateWaiters(1)
+	id := uuid.New()
+	_, stop, err := w.Wait(id)
+	require.NoError(t, err)
+
+	w.NotifyFinished([]uuid.UUID{id})
+	stop()
+
+	require.Equal(t, 0, w.count)
+}
+
+func TestUpdateWaiters_Close_WakesAll(t *testing.T) {
+	w := NewUpdateWaiters(8)
+	ch, stop, err := w.Wait(uuid.New())
+	require.NoError(t, err)
+
+	w.Close()
+	w.Close() // idempotent
+	stop()
+
+	require.True(t, isClosed(ch))
+	late, _, err := w.Wait(uuid.New())
+	require.NoError(t, err)
+	require.True(t, isClosed(late))
+	w.NotifyFinished([]uuid.UUID{uuid.New()}) // no panic after close
+}
@@ -0,0 +1,581 @@
+// Package repotest is the contract every storage backend of the repositories must pass,
+// so the in-memory and Postgres adapters stay interchangeable
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+// Repositories are the repositories of a single backend sharing the same data
+type Repositories struct {
+	Rates      adapters.RateRepository
+	Updates    adapters.RateUpdateRepository
+	Callbacks  adapters.RateUpdateCallbackRepository
+	APIKeys    adapters.APIKeyRepository
+	Currencies adapters.CurrencyRepository
+}
+
+// Run runs the contract against repositories made by newRepos, which must return empty ones on every call
+func Run(t *testing.T, newRepos func(t *testing.T) Repositories) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, r Repositories)
+	}{
+		{"Rates/NotFound", testRatesNotFound},
+		{"Rates/DoneContext", testRatesDoneContext},
+		{"Updates/ScheduleIdempotentWhilePending", testScheduleIdempotentWhilePending},
+		{"Updates/ScheduleBatch", testScheduleBatch},
+		{"Updates/ScheduleUnknownCurrency", testScheduleUnknownCurrency},
+		{"Updates/ClaimSkipsClaimedUntilLeaseExpires", testClaimSkipsClaimedUntilLeaseExpires},
+		{"Updates/ApplyPropagatesToLastRatesAndHistory", testApplyPropagates},
+		{"Updates/ApplyReportsLostClaims", testApplyReportsLostClaims},
+		{"Updates/ApplyValueOutOfRange", testApplyValueOutOfRange},
+		{"Updates/IncrementAttemptsReleasesPending", testIncrementAttemptsReleasesPending},
+		{"Updates/ReleaseClaimsKeepsAttempts", testReleaseClaimsKeepsAttempts},
+		{"Updates/CloseFailsAndExpires", testCloseFailsAndExpires},
+		{"Callbacks/ClaimDueOnlyAppliedAndLeased", testCallbacksClaimDue},
+		{"Callbacks/RescheduleDeliverAndFail", testCallbacksRescheduleDeliverAndFail},
+		{"APIKeys/CreateGetListRevoke", testAPIKeys},
+		{"Currencies/AddDisableReEnable", testCurrencies},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newRepos(t))
+		})
+	}
+}
+
+func addCurrencies(t *testing.T, r Repositories, codes ...string) {
+	t.Helper()
+	for _, code := range codes {
+		_, err := r.Currencies.Add(context.Background(), domain.Currency{Code: code, Name: code, NumericCode: "000", MinorUnits: 2})
+		require.NoError(t, err)
+	}
+}
+
+func claim(t *testing.T, r Repositories, claimID string) []domain.PendingRateUpdate {
+	t.Helper()
+	pending, err := r.Updates.ClaimPending(context.Background(), claimID, time.Minute)
+	require.NoError(t, err)
+	return pending
+}
+
+// applyValue schedules an update of the pair, claims and applies it with the value
+func applyValue(t *testing.T, r Repositories, base string, quote string, value string) uuid.UUID {
+	t.Helper()
+	ctx := context.Background()
+	updateID, err := r.Updates.ScheduleNewOrGetExisting(ctx, base, quote)
+	require.NoError(t, err)
+	var pairID int64
+	for _, p := range claim(t, r, "apply-"+updateID.String()) {
+		if p.UpdateID == updateID {
+			pairID = p.PairID
+		}
+	}
+	require.NotZero(t, pairID)
+	err = r.Updates.ApplyUpdates(ctx, "apply-"+updateID.String(), []domain.AppliedRateUpdate{{UpdateID: updateID, PairID: pairID, Value: decimal.RequireFromString(value), Source: "test"}})
+	require.NoError(t, err)
+	return updateID
+}
+
+func testRatesNotFound(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "EUR")
+
+	_, err := r.Rates.GetByCodes(ctx, "USD", "EUR")
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+	_, _, err = r.Rates.GetByUpdateID(ctx, uuid.New())
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+
+	// a pending update doesn't make a rate
+	_, err = r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	_, err = r.Rates.GetByCodes(ctx, "USD", "EUR")
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+
+	points, err := r.Rates.GetHistory(ctx, "USD", "EUR", time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 0)
+	require.NoError(t, err)
+	require.Empty(t, points)
+}
+
+func testRatesDoneContext(t *testing.T, r Repositories) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := r.Rates.GetByCodes(ctx, "USD", "EUR")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, domain.ErrRateNotFound)
+	_, _, err = r.Rates.GetByUpdateID(ctx, uuid.New())
+	require.Error(t, err)
+	require.NotErrorIs(t, err, domain.ErrRateNotFound)
+	_, err = r.Updates.ClaimPending(ctx, "run-1", time.Minute)
+	require.Error(t, err)
+}
+
+func testScheduleIdempotentWhilePending(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "JPY")
+
+	first, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "JPY")
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, first)
+	second, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "JPY")
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	rate, status, err := r.Rates.GetByUpdateID(ctx, first)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusPending, status)
+	require.Equal(t, "USD", rate.Base)
+	require.Equal(t, "JPY", rate.Quote)
+	require.NotZero(t, rate.PairID)
+	require.Equal(t, "-1", rate.Value.String()) // explicitly set bad value when pending
+	require.Zero(t, rate.Attempts)
+	require.Empty(t, rate.Quotes)
+
+	// the reversed pair is a pair of its own
+	reversed, err := r.Updates.ScheduleNewOrGetExisting(ctx, "JPY", "USD")
+	require.NoError(t, err)
+	require.NotEqual(t, first, reversed)
+}
+
+func testScheduleBatch(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "EUR", "GBP", "JPY")
+
+	existing, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+
+	usdEUR := domain.RatePair{Base: "USD", Quote: "EUR"}
+	gbpJPY := domain.RatePair{Base: "GBP", Quote: "JPY"}
+	ids, err := r.Updates.ScheduleNewOrGetExistingBatch(ctx, []domain.RatePair{usdEUR, gbpJPY})
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	require.Equal(t, existing, ids[usdEUR])
+	require.NotEqual(t, uuid.Nil, ids[gbpJPY])
+	require.Len(t, claim(t, r, "run-1"), 2)
+
+	again, err := r.Updates.ScheduleNewOrGetExistingBatch(ctx, []domain.RatePair{gbpJPY, usdEUR})
+	require.NoError(t, err)
+	require.Equal(t, ids, again)
+
+	empty, err := r.Updates.ScheduleNewOrGetExistingBatch(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
+
+func testScheduleUnknownCurrency(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD")
+
+	_, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "FOO")
+	require.Error(t, err)
+	_, err = r.Updates.ScheduleNewOrGetExistingBatch(ctx, []domain.RatePair{{Base: "FOO", Quote: "BAR"}})
+	require.Error(t, err)
+	require.Empty(t, claim(t, r, "run-1"))
+}
+
+func testClaimSkipsClaimedUntilLeaseExpires(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "EUR")
+	updateID, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+
+	pending, err := r.Updates.ClaimPending(ctx, "run-1", 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, updateID, pending[0].UpdateID)
+	require.Equal(t, "USD", pending[0].Base)
+	require.Equal(t, "EUR", pending[0].Quote)
+	require.Zero(t, pending[0].Attempts)
+	require.False(t, pending[0].CreatedAt.IsZero())
+	require.Empty(t, claim(t, r, "run-2"))
+
+	// run-1 died, its lease expired and run-2 claims the update
+	time.Sleep(100 * time.Millisecond)
+	require.Len(t, claim(t, r, "run-2"), 1)
+
+	// late writes of run-1 don't take effect
+	applied := []domain.AppliedRateUpdate{{UpdateID: updateID, PairID: pending[0].PairID, Value: decimal.RequireFromString("0.9")}}
+	require.ErrorIs(t, r.Updates.ApplyUpdates(ctx, "run-1", applied), domain.ErrClaimLost)
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-1", []uuid.UUID{updateID}))
+	require.NoError(t, r.Updates.CloseUpdates(ctx, "run-1", []domain.ClosedRateUpdate{{UpdateID: updateID, Status: domain.StatusFailed, Reason: "late"}}))
+
+	rate, status, err := r.Rates.GetByUpdateID(ctx, updateID)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusPending, status)
+	require.Zero(t, rate.Attempts)
+
+	require.NoError(t, r.Updates.ApplyUpdates(ctx, "run-2", applied))
+}
+
+func testApplyPropagates(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "EUR")
+	before := time.Now().Add(-time.Minute)
+
+	updateID, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	pending := claim(t, r, "run-1")
+	require.Len(t, pending, 1)
+	quotes := []domain.ProviderQuote{
+		{Provider: "exchangerate_api", Value: decimal.RequireFromString("0.92"), Accepted: true},
+		{Provider: "frankfurter", Value: decimal.RequireFromString("1.05"), Accepted: false},
+	}
+	err = r.Updates.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{
+		{UpdateID: updateID, PairID: pending[0].PairID, Value: decimal.RequireFromString("0.123456789"), Source: "consensus", Quotes: quotes},
+	})
+	require.NoError(t, err)
+
+	rate, status, err := r.Rates.GetByUpdateID(ctx, updateID)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusApplied, status)
+	require.Equal(t, "0.12345679", rate.Value.String()) // rounded to the stored scale
+	require.Equal(t, "consensus", rate.Source)
+	require.Len(t, rate.Quotes, len(quotes))
+	for i, q := range rate.Quotes {
+		require.Equal(t, quotes[i].Provider, q.Provider)
+		require.True(t, quotes[i].Value.Equal(q.Value), "expected %s, got %s", quotes[i].Value, q.Value)
+		require.Equal(t, quotes[i].Accepted, q.Accepted)
+	}
+
+	last, err := r.Rates.GetByCodes(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, pending[0].PairID, last.PairID)
+	require.Equal(t, "0.12345679", last.Value.String())
+	require.False(t, last.UpdatedAt.IsZero())
+	_, err = r.Rates.GetByCodes(ctx, "EUR", "USD")
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+
+	// a new update is scheduled once the previous one is applied, and replaces the last rate
+	next := applyValue(t, r, "USD", "EUR", "0.93")
+	require.NotEqual(t, updateID, next)
+	last, err = r.Rates.GetByCodes(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.93", last.Value.String())
+
+	after := time.Now().Add(time.Minute)
+	points, err := r.Rates.GetHistory(ctx, "USD", "EUR", before, after, 0)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	require.Equal(t, "0.12345679", points[0].Value.String())
+	require.Equal(t, "0.93", points[1].Value.String())
+	require.False(t, points[1].RecordedAt.Before(points[0].RecordedAt))
+
+	// the upper bound is exclusive
+	if points[1].RecordedAt.After(points[0].RecordedAt) {
+		bounded, boundedErr := r.Rates.GetHistory(ctx, "USD", "EUR", before, points[1].RecordedAt, 0)
+		require.NoError(t, boundedErr)
+		require.Len(t, bounded, 1)
+	}
+
+	// a bucket wider than the range keeps its last value, stamped with the bucket start
+	bucketed, err := r.Rates.GetHistory(ctx, "USD", "EUR", before, after, 1000*time.Hour)
+	require.NoError(t, err)
+	require.NotEmpty(t, bucketed)
+	require.Equal(t, "0.93", bucketed[len(bucketed)-1].Value.String())
+	require.False(t, bucketed[0].RecordedAt.After(points[0].RecordedAt))
+}
+
+func testApplyReportsLostClaims(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "EUR", "JPY", "GBP")
+
+	claimed, err := r.Updates.ScheduleNewOrGetExisting(ctx, "EUR", "JPY")
+	require.NoError(t, err)
+	pending := claim(t, r, "run-1")
+	require.Len(t, pending, 1)
+	unclaimed, err := r.Updates.ScheduleNewOrGetExisting(ctx, "GBP", "EUR")
+	require.NoError(t, err)
+
+	require.NoError(t, r.Updates.ApplyUpdates(ctx, "run-1", nil))
+	// the still claimed update is applied, the other one is reported and stays pending
+	err = r.Updates.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{
+		{UpdateID: claimed, PairID: pending[0].PairID, Value: decimal.RequireFromString("160.5")},
+		{UpdateID: unclaimed, PairID: pending[0].PairID + 1, Value: decimal.RequireFromString("1.17")},
+	})
+	require.ErrorIs(t, err, domain.ErrClaimLost)
+	var lostErr *domain.ClaimLostError
+	require.ErrorAs(t, err, &lostErr)
+	require.Equal(t, []uuid.UUID{unclaimed}, lostErr.UpdateIDs)
+
+	_, status, err := r.Rates.GetByUpdateID(ctx, claimed)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusApplied, status)
+	rate, err := r.Rates.GetByCodes(ctx, "EUR", "JPY")
+	require.NoError(t, err)
+	require.Equal(t, "160.5", rate.Value.String())
+	_, status, err = r.Rates.GetByUpdateID(ctx, unclaimed)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusPending, status)
+	_, err = r.Rates.GetByCodes(ctx, "GBP", "EUR")
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+
+	// the unclaimed update is still there for the next run
+	require.Len(t, claim(t, r, "run-2"), 1)
+}
+
+func testApplyValueOutOfRange(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "GBP")
+
+	updateID, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "GBP")
+	require.NoError(t, err)
+	pending := claim(t, r, "run-1")
+	err = r.Updates.ApplyUpdates(ctx, "run-1", []domain.AppliedRateUpdate{{UpdateID: updateID, PairID: pending[0].PairID, Value: decimal.RequireFromString("123456789")}})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, domain.ErrClaimLost)
+
+	_, status, err := r.Rates.GetByUpdateID(ctx, updateID)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusPending, status)
+	_, err = r.Rates.GetByCodes(ctx, "USD", "GBP")
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+}
+
+func testIncrementAttemptsReleasesPending(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "MXN", "EUR", "GBP")
+
+	applied := applyValue(t, r, "EUR", "GBP", "0.85")
+	pending, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "MXN")
+	require.NoError(t, err)
+
+	require.Len(t, claim(t, r, "run-1"), 1)
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-1", []uuid.UUID{pending, applied}))
+	// released by the first run, so the next one claims it again
+	require.Len(t, claim(t, r, "run-2"), 1)
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-2", []uuid.UUID{pending}))
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-2", nil))
+
+	got := claim(t, r, "run-3")
+	require.Len(t, got, 1)
+	require.Equal(t, pending, got[0].UpdateID)
+	require.Equal(t, 2, got[0].Attempts)
+
+	rate, _, err := r.Rates.GetByUpdateID(ctx, applied)
+	require.NoError(t, err)
+	require.Zero(t, rate.Attempts)
+}
+
+func testReleaseClaimsKeepsAttempts(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "MXN")
+
+	pending, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "MXN")
+	require.NoError(t, err)
+
+	require.Len(t, claim(t, r, "run-1"), 1)
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-1", []uuid.UUID{pending}))
+	require.Len(t, claim(t, r, "run-2"), 1)
+	require.NoError(t, r.Updates.ReleaseClaims(ctx, "run-1", []uuid.UUID{pending})) // not claimed by run-1 anymore
+	require.Empty(t, claim(t, r, "run-3"))
+	require.NoError(t, r.Updates.ReleaseClaims(ctx, "run-2", []uuid.UUID{pending}))
+	require.NoError(t, r.Updates.ReleaseClaims(ctx, "run-2", nil))
+
+	got := claim(t, r, "run-3")
+	require.Len(t, got, 1)
+	require.Equal(t, 1, got[0].Attempts)
+}
+
+func testCloseFailsAndExpires(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "MXN", "EUR", "GBP")
+
+	failed, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "MXN")
+	require.NoError(t, err)
+	expired, err := r.Updates.ScheduleNewOrGetExisting(ctx, "EUR", "GBP")
+	require.NoError(t, err)
+
+	require.Len(t, claim(t, r, "run-1"), 2)
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-1", []uuid.UUID{failed}))
+	require.Len(t, claim(t, r, "run-2"), 1)
+	require.NoError(t, r.Updates.CloseUpdates(ctx, "run-1", nil))
+	require.NoError(t, r.Updates.CloseUpdates(ctx, "run-2", []domain.ClosedRateUpdate{{UpdateID: failed, Status: domain.StatusFailed, Reason: "rate wasn't fetched after 2 attempts"}}))
+	require.NoError(t, r.Updates.CloseUpdates(ctx, "run-1", []domain.ClosedRateUpdate{{UpdateID: expired, Status: domain.StatusExpired, Reason: "rate wasn't fetched within 1h0m0s"}}))
+	require.Empty(t, claim(t, r, "run-3"))
+
+	rate, status, err := r.Rates.GetByUpdateID(ctx, failed)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusFailed, status)
+	require.Equal(t, 2, rate.Attempts)
+	require.Equal(t, "rate wasn't fetched after 2 attempts", rate.Reason)
+	require.Equal(t, "-1", rate.Value.String())
+
+	rate, status, err = r.Rates.GetByUpdateID(ctx, expired)
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusExpired, status)
+	require.Equal(t, 1, rate.Attempts)
+	require.Equal(t, "rate wasn't fetched within 1h0m0s", rate.Reason)
+
+	// a closed pair can be scheduled again
+	again, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "MXN")
+	require.NoError(t, err)
+	require.NotEqual(t, failed, again)
+}
+
+func testCallbacksClaimDue(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "USD", "MXN", "EUR", "GBP", "JPY")
+
+	applied := applyValue(t, r, "EUR", "GBP", "0.8512")
+	pending, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "MXN")
+	require.NoError(t, err)
+	failed, err := r.Updates.ScheduleNewOrGetExisting(ctx, "USD", "JPY")
+	require.NoError(t, err)
+
+	require.Error(t, r.Callbacks.Register(ctx, uuid.New(), "https://a.example.com"))
+	require.NoError(t, r.Callbacks.Register(ctx, pending, "https://a.example.com"))
+	require.NoError(t, r.Callbacks.Register(ctx, applied, "https://b.example.com"))
+	require.NoError(t, r.Callbacks.Register(ctx, applied, "https://b.example.com")) // duplicate is a no-op
+	require.NoError(t, r.Callbacks.Register(ctx, failed, "https://c.example.com"))
+
+	claim(t, r, "run-1")
+	require.NoError(t, r.Updates.CloseUpdates(ctx, "run-1", []domain.ClosedRateUpdate{{UpdateID: failed, Status: domain.StatusFailed, Reason: "rate wasn't fetched after 1 attempts"}}))
+	require.NoError(t, r.Updates.IncrementAttempts(ctx, "run-1", []uuid.UUID{pending}))
+
+	due, err := r.Callbacks.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	byURL := map[string]domain.RateUpdateCallback{due[0].URL: due[0], due[1].URL: due[1]}
+
+	got := byURL["https://b.example.com"]
+	require.NotZero(t, got.ID)
+	require.Equal(t, applied, got.UpdateID)
+	require.Equal(t, "EUR", got.Base)
+	require.Equal(t, "GBP", got.Quote)
+	require.Equal(t, domain.StatusApplied, got.Status)
+	require.Equal(t, "0.8512", got.Value.String())
+	require.False(t, got.UpdatedAt.IsZero())
+	require.Zero(t, got.Attempts)
+
+	// callbacks of closed updates are delivered too, with the reason
+	got = byURL["https://c.example.com"]
+	require.Equal(t, failed, got.UpdateID)
+	require.Equal(t, domain.StatusFailed, got.Status)
+	require.Equal(t, "rate wasn't fetched after 1 attempts", got.Reason)
+
+	// leased callbacks aren't claimed again
+	again, err := r.Callbacks.ClaimDue(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Empty(t, again)
+}
+
+func testCallbacksRescheduleDeliverAndFail(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	addCurrencies(t, r, "EUR", "GBP")
+
+	applied := applyValue(t, r, "EUR", "GBP", "0.85")
+	require.NoError(t, r.Callbacks.Register(ctx, applied, "https://a.example.com"))
+	require.NoError(t, r.Callbacks.Register(ctx, applied, "https://b.example.com"))
+
+	due, err := r.Callbacks.ClaimDue(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, due, 1) // limited
+	due, err = r.Callbacks.ClaimDue(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	byURL := map[string]domain.RateUpdateCallback{due[0].URL: due[0], due[1].URL: due[1]}
+
+	// rescheduled with no backoff is due right away with the attempt counted
+	require.NoError(t, r.Callbacks.Reschedule(ctx, byURL["https://a.example.com"].ID, 0, "status 503"))
+	require.NoError(t, r.Callbacks.MarkFailed(ctx, byURL["https://b.example.com"].ID, "status 500"))
+
+	due, err = r.Callbacks.ClaimDue(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	require.Equal(t, "https://a.example.com", due[0].URL)
+	require.Equal(t, 1, due[0].Attempts)
+
+	require.NoError(t, r.Callbacks.Reschedule(ctx, due[0].ID, time.Hour, "timeout"))
+	due, err = r.Callbacks.ClaimDue(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Empty(t, due) // backing off
+
+	require.NoError(t, r.Callbacks.MarkDelivered(ctx, byURL["https://a.example.com"].ID))
+	due, err = r.Callbacks.ClaimDue(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Empty(t, due)
+}
+
+func testAPIKeys(t *testing.T, r Repositories) {
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	created, err := r.APIKeys.Create(ctx, "hash-1", domain.APIKey{Prefix: "fxr_abcd", Owner: "pricing", Scopes: []string{domain.ScopeRatesRead}, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+	require.False(t, created.CreatedAt.IsZero())
+	_, err = r.APIKeys.Create(ctx, "hash-1", domain.APIKey{Prefix: "fxr_efgh", Owner: "other", Scopes: []string{domain.ScopeRatesRead}})
+	require.Error(t, err)
+
+	found, err := r.APIKeys.GetByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, "fxr_abcd", found.Prefix)
+	require.Equal(t, "pricing", found.Owner)
+	require.Equal(t, []string{domain.ScopeRatesRead}, found.Scopes)
+	require.True(t, expiresAt.Equal(*found.ExpiresAt))
+	require.Nil(t, found.RevokedAt)
+
+	_, err = r.APIKeys.GetByHash(ctx, "hash-unknown")
+	require.ErrorIs(t, err, domain.ErrAPIKeyNotFound)
+
+	second, err := r.APIKeys.Create(ctx, "hash-2", domain.APIKey{Prefix: "fxr_ijkl", Owner: "ops", Scopes: []string{domain.ScopeKeysAdmin}})
+	require.NoError(t, err)
+	require.Greater(t, second.ID, created.ID)
+
+	require.NoError(t, r.APIKeys.Revoke(ctx, created.ID))
+	require.ErrorIs(t, r.APIKeys.Revoke(ctx, created.ID), domain.ErrAPIKeyNotFound)
+	require.ErrorIs(t, r.APIKeys.Revoke(ctx, second.ID+100), domain.ErrAPIKeyNotFound)
+
+	keys, err := r.APIKeys.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	require.Equal(t, created.ID, keys[0].ID)
+	require.NotNil(t, keys[0].RevokedAt)
+	require.Nil(t, keys[1].RevokedAt)
+}
+
+func testCurrencies(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	sek := domain.Currency{Code: "SEK", Name: "Swedish Krona", NumericCode: "752", MinorUnits: 2}
+	jpy := domain.Currency{Code: "JPY", Name: "Yen", NumericCode: "392", MinorUnits: 0}
+
+	added, err := r.Currencies.Add(ctx, sek)
+	require.NoError(t, err)
+	require.True(t, added.Enabled)
+	_, err = r.Currencies.Add(ctx, jpy)
+	require.NoError(t, err)
+
+	_, err = r.Currencies.Add(ctx, sek)
+	require.ErrorIs(t, err, domain.ErrCurrencyExists)
+
+	require.NoError(t, r.Currencies.Disable(ctx, "SEK"))
+	require.ErrorIs(t, r.Currencies.Disable(ctx, "SEK"), domain.ErrCurrencyNotFound)
+	require.ErrorIs(t, r.Currencies.Disable(ctx, "FOO"), domain.ErrCurrencyNotFound)
+
+	// disabled currencies are kept, so pairs referencing them can still be scheduled
+	_, err = r.Updates.ScheduleNewOrGetExisting(ctx, "SEK", "JPY")
+	require.NoError(t, err)
+
+	currencies, err := r.Currencies.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Currency{
+		{Code: "JPY", Name: "Yen", NumericCode: "392", MinorUnits: 0, Enabled: true},
+		{Code: "SEK", Name: "Swedish Krona", NumericCode: "752", MinorUnits: 2, Enabled: false},
+	}, currencies)
+
+	sek.Name = "Krona"
+	readded, err := r.Currencies.Add(ctx, sek)
+	require.NoError(t, err)
+	require.Equal(t, domain.Currency{Code: "SEK", Name: "Krona", NumericCode: "752", MinorUnits: 2, Enabled: true}, readded)
+}
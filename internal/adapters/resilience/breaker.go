@@ -0,0 +1,127 @@
+// Package resilience guards rate providers with retries and circuit breakers, so an upstream outage
+// costs a few short-circuited calls instead of every worker hammering a dead endpoint on every run
+package resilience
+
+import (
+	"errors"
+	"fxrates/internal/metrics"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the provider while its breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed calls opening the breaker
+	FailureThreshold int
+	// OpenTimeout is how long calls are short-circuited before a trial call is let through
+	OpenTimeout time.Duration
+}
+
+// BreakerStatus is a snapshot of a breaker, OpenedAt and RetryAt are set unless it's closed
+type BreakerStatus struct {
+	Provider            string
+	State               string
+	ConsecutiveFailures int
+	OpenedAt            time.Time
+	RetryAt             time.Time
+}
+
+// Breaker opens after FailureThreshold consecutive calls failed with domain.ErrUpstreamUnavailable. Once
+// OpenTimeout passes, a single trial call is let through (half open): its success closes the breaker, its
+// failure opens it again. Other errors mean the upstream answered, they close the breaker too
+type Breaker struct {
+	provider string
+	opts     BreakerOptions
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool // a half open trial call is in flight
+}
+
+// allow tells whether a call may go through, claiming the trial call when the open timeout passed
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Before(b.openedAt.Add(b.opts.OpenTimeout)) {
+			return false
+		}
+		b.setState(StateHalfOpen)
+		b.trial = true
+		return true
+	case StateHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success closes the breaker
+func (b *Breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures, b.trial = 0, false
+	b.setState(StateClosed)
+}
+
+// failure counts a failed call, opening the breaker at the threshold or on a failed trial
+func (b *Breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.state == StateHalfOpen || b.failures >= b.opts.FailureThreshold {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// release gives up a trial call which told nothing about the upstream, like a canceled one
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *Breaker) setState(state string) {
+	b.state = state
+	open := 0.0
+	if state == StateOpen {
+		open = 1
+	}
+	metrics.UpstreamBreakerOpen.WithLabelValues(b.provider).Set(open)
+}
+
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := BreakerStatus{Provider: b.provider, State: b.state, ConsecutiveFailures: b.failures}
+	if b.state != StateClosed {
+		s.OpenedAt, s.RetryAt = b.openedAt, b.openedAt.Add(b.opts.OpenTimeout)
+	}
+	return s
+}
+
+func NewBreaker(provider string, opts BreakerOptions) *Breaker {
+	b := &Breaker{provider: provider, opts: opts, now: time.Now}
+	b.setState(StateClosed)
+	return b
+}
@@ -0,0 +1,147 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"fxrates/internal/metrics"
+	"math/rand/v2"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type RetryPolicy struct {
+	// MaxAttempts per call, 1 or less disables retries
+	MaxAttempts int
+	// InitialBackoff doubles after every attempt up to MaxBackoff, the actual wait is jittered between half of it and all of it
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// AttemptTimeout bounds every attempt, so a hanging one leaves time to retry within the caller's deadline.
+	// Zero leaves attempts the whole deadline
+	AttemptTimeout time.Duration
+}
+
+// RateClient retries calls of the provider failing with domain.ErrUpstreamUnavailable or timing out, and short-circuits
+// them with ErrCircuitOpen while the breaker is open. Retries stop early once the context is done or the budget is used up
+type RateClient struct {
+	provider string
+	next     adapters.RateClient
+	retry    RetryPolicy
+	breaker  *Breaker
+	// budget is charged for every retry, the first attempt is reserved by the caller. Nil doesn't limit retries
+	budget adapters.UpstreamBudget
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// WithBudget charges every retry to the budget, retries stop once it's used up
+func (c *RateClient) WithBudget(budget adapters.UpstreamBudget) *RateClient {
+	c.budget = budget
+	return c
+}
+
+func (c *RateClient) GetExchangeRates(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	if c.breaker != nil && !c.breaker.allow() {
+		return domain.ExchangeRates{}, fmt.Errorf("provider %s skipped for currency %q: %w", c.provider, base, ErrCircuitOpen)
+	}
+
+	res, err := c.call(ctx, base)
+	if c.breaker != nil {
+		switch {
+		case err == nil:
+			c.breaker.success()
+		case errors.Is(err, domain.ErrUpstreamUnavailable):
+			c.breaker.failure()
+		case ctx.Err() != nil:
+			c.breaker.release()
+		default:
+			c.breaker.success()
+		}
+	}
+	return res, err
+}
+
+func (c *RateClient) call(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	for attempt := 1; ; attempt++ {
+		res, err := c.attempt(ctx, base)
+		if err == nil || attempt >= c.retry.MaxAttempts || !errors.Is(err, domain.ErrUpstreamUnavailable) {
+			return res, err
+		}
+
+		wait := c.backoff(attempt)
+		logrus.WithError(err).Debugf("Retrying provider %s for base '%s' in %s", c.provider, base, wait)
+		if sleepErr := c.sleep(ctx, wait); sleepErr != nil {
+			return res, err // out of time, the last failure tells more than the context
+		}
+		if !c.reserveRetry(ctx, base) {
+			return res, err
+		}
+		metrics.UpstreamRetries.WithLabelValues(c.provider).Inc()
+	}
+}
+
+// attempt calls the provider within the attempt timeout. An attempt running out of it while the caller
+// still has time is domain.ErrUpstreamUnavailable, so it's retried
+func (c *RateClient) attempt(ctx context.Context, base string) (domain.ExchangeRates, error) {
+	if c.retry.AttemptTimeout <= 0 {
+		return c.next.GetExchangeRates(ctx, base)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, c.retry.AttemptTimeout)
+	defer cancel()
+
+	res, err := c.next.GetExchangeRates(attemptCtx, base)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) && !errors.Is(err, domain.ErrUpstreamUnavailable) {
+		err = fmt.Errorf("provider %s timed out after %s for currency %q: %w: %w", c.provider, c.retry.AttemptTimeout, base, err, domain.ErrUpstreamUnavailable)
+	}
+	return res, err
+}
+
+// reserveRetry takes a fetch of the budget for the next attempt, a budget error denies it as upstream calls are paid
+func (c *RateClient) reserveRetry(ctx context.Context, base string) bool {
+	if c.budget == nil {
+		return true
+	}
+	granted, err := c.budget.Reserve(ctx, 1)
+	if err != nil {
+		logrus.Errorf("Upstream budget wasn't reserved, provider %s isn't retried for base '%s': %v", c.provider, base, err)
+		return false
+	}
+	if granted < 1 {
+		logrus.Warnf("Upstream budget is exhausted, provider %s isn't retried for base '%s'", c.provider, base)
+		return false
+	}
+	return true
+}
+
+// backoff returns the jittered wait after the given attempt
+func (c *RateClient) backoff(attempt int) time.Duration {
+	d := c.retry.InitialBackoff
+	for i := 1; i < attempt && d < c.retry.MaxBackoff; i++ {
+		d *= 2
+	}
+	if c.retry.MaxBackoff > 0 {
+		d = min(d, c.retry.MaxBackoff)
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewRateClient guards the provider with the retry policy and the breaker, nil breaker disables short-circuiting
+func NewRateClient(provider string, next adapters.RateClient, retry RetryPolicy, breaker *Breaker) *RateClient {
+	return &RateClient{provider: provider, next: next, retry: retry, breaker: breaker, sleep: sleep}
+}
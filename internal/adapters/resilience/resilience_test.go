@@ -0,0 +1,182 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRateClient struct{ mock.Mock }
+
+func (m *MockRateClient) GetExchangeRates(ctx context.Context, code string) (domain.ExchangeRates, error) {
+	args := m.Called(ctx, code)
+	rates, _ := args.Get(0).(domain.ExchangeRates)
+	return rates, args.Error(1)
+}
+
+var (
+	usdRates   = domain.ExchangeRates{Provider: "first", Rates: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.92")}}
+	errOutage  = fmt.Errorf("unexpected status code 503: %w", domain.ErrUpstreamUnavailable)
+	errBadCode = errors.New("unexpected status code 404")
+)
+
+// newTestClient records backoffs instead of sleeping, the breaker's clock is moved by the returned func
+func newTestClient(next *MockRateClient, retry RetryPolicy, opts BreakerOptions) (*RateClient, *[]time.Duration, func(time.Duration)) {
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	breaker := NewBreaker("first", opts)
+	breaker.now = func() time.Time { return now }
+	c := NewRateClient("first", next, retry, breaker)
+	var waits []time.Duration
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return c, &waits, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateClient_RetriesUnavailableWithJitteredBackoff(t *testing.T) {
+	next := new(MockRateClient)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errOutage).Times(3)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(usdRates, nil).Once()
+	c, waits, _ := newTestClient(next, RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}, BreakerOptions{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	res, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "first", res.Provider)
+	require.Len(t, *waits, 3)
+	for i, limit := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond} {
+		require.GreaterOrEqual(t, (*waits)[i], limit/2)
+		require.LessOrEqual(t, (*waits)[i], limit)
+	}
+	require.Equal(t, StateClosed, c.breaker.Status().State) // a call succeeding on retry isn't a failure
+	next.AssertExpectations(t)
+}
+
+func TestRateClient_DoesNotRetryOtherErrors(t *testing.T) {
+	next := new(MockRateClient)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errBadCode).Once()
+	c, waits, _ := newTestClient(next, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}, BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.ErrorIs(t, err, errBadCode)
+	require.Empty(t, *waits)
+	require.Equal(t, StateClosed, c.breaker.Status().State) // the upstream answered
+	next.AssertExpectations(t)
+}
+
+func TestRateClient_StopsRetryingOnceContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	next := new(MockRateClient)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errOutage).Once().Run(func(mock.Arguments) { cancel() })
+	c, _, _ := newTestClient(next, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}, BreakerOptions{FailureThreshold: 5, OpenTimeout: time.Minute})
+
+	_, err := c.GetExchangeRates(ctx, "USD")
+
+	require.ErrorIs(t, err, errOutage)
+	next.AssertExpectations(t)
+}
+
+func TestRateClient_RetriesTimedOutAttemptWithinDeadline(t *testing.T) {
+	next := new(MockRateClient)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, context.DeadlineExceeded).Once().Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done() // hangs until the attempt times out
+	})
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(usdRates, nil).Once()
+	c, waits, _ := newTestClient(next, RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, AttemptTimeout: 20 * time.Millisecond}, BreakerOptions{FailureThreshold: 5, OpenTimeout: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := c.GetExchangeRates(ctx, "USD")
+
+	require.NoError(t, err)
+	require.Equal(t, "first", res.Provider)
+	require.Len(t, *waits, 1)
+	next.AssertExpectations(t)
+}
+
+type MockUpstreamBudget struct{ mock.Mock }
+
+func (m *MockUpstreamBudget) Reserve(ctx context.Context, n int) (int, error) {
+	args := m.Called(ctx, n)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRateClient_ChargesRetriesToBudget(t *testing.T) {
+	next := new(MockRateClient)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errOutage).Twice()
+	budget := new(MockUpstreamBudget)
+	budget.On("Reserve", mock.Anything, 1).Return(1, nil).Once()
+	budget.On("Reserve", mock.Anything, 1).Return(0, nil).Once()
+	c, _, _ := newTestClient(next, RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}, BreakerOptions{FailureThreshold: 5, OpenTimeout: time.Minute})
+	c.WithBudget(budget)
+
+	_, err := c.GetExchangeRates(context.Background(), "USD")
+
+	require.ErrorIs(t, err, errOutage)
+	next.AssertExpectations(t)
+	budget.AssertExpectations(t)
+}
+
+func TestRateClient_BreakerOpensThenLetsATrialThrough(t *testing.T) {
+	next := new(MockRateClient)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errOutage).Times(4)
+	c, _, advance := newTestClient(next, RetryPolicy{MaxAttempts: 2}, BreakerOptions{FailureThreshold: 2, OpenTimeout: time.Minute})
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := c.GetExchangeRates(ctx, "USD")
+		require.ErrorIs(t, err, errOutage)
+	}
+	status := c.breaker.Status()
+	require.Equal(t, StateOpen, status.State)
+	require.Equal(t, 2, status.ConsecutiveFailures)
+	require.Equal(t, status.OpenedAt.Add(time.Minute), status.RetryAt)
+
+	_, err := c.GetExchangeRates(ctx, "USD")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	next.AssertNumberOfCalls(t, "GetExchangeRates", 4)
+
+	// the trial fails (retries included), so the breaker opens again
+	advance(time.Minute)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errOutage).Twice()
+	_, err = c.GetExchangeRates(ctx, "USD")
+	require.ErrorIs(t, err, errOutage)
+	require.Equal(t, StateOpen, c.breaker.Status().State)
+	_, err = c.GetExchangeRates(ctx, "USD")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// the next trial succeeds and closes it
+	advance(time.Minute)
+	next.On("GetExchangeRates", mock.Anything, "USD").Return(usdRates, nil).Once()
+	_, err = c.GetExchangeRates(ctx, "USD")
+	require.NoError(t, err)
+	status = c.breaker.Status()
+	require.Equal(t, StateClosed, status.State)
+	require.Zero(t, status.ConsecutiveFailures)
+	require.True(t, status.RetryAt.IsZero())
+}
+
+func TestBreaker_SingleTrialWhileHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker("first", BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+	b.failure()
+	require.False(t, b.allow())
+
+	now = now.Add(time.Second)
+	require.True(t, b.allow())
+	require.Equal(t, StateHalfOpen, b.Status().State)
+	require.False(t, b.allow(), "only one trial call at a time")
+
+	b.release() // the trial was canceled, another one may go
+	require.True(t, b.allow())
+}
@@ -2,24 +2,85 @@ package api
 
 import (
 	_ "fxrates/docs"
+	apikeyhandler "fxrates/internal/apikey/handler"
+	currencyhandler "fxrates/internal/currency/handler"
+	"fxrates/internal/domain"
+	"fxrates/internal/health"
+	"fxrates/internal/metrics"
+	"fxrates/internal/platform/tracing"
 	"fxrates/internal/rate/handler"
+	"fxrates/internal/ratelimit"
+	"net/http"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	swagger "github.com/swaggo/http-swagger"
 )
 
-func NewRouter(rateHandler *handler.Handler) *chi.Mux {
+// NewRouter builds API routes. Nil keyHandler disables API key auth, so all routes but the admin ones,
+// which aren't mounted then, are public; nil limiter disables per-client rate limiting
+func NewRouter(
+	rateHandler *handler.Handler,
+	keyHandler *apikeyhandler.Handler,
+	currencyHandler *currencyhandler.Handler,
+	healthHandler *health.Handler,
+	limiter *ratelimit.ClientLimiter,
+) *chi.Mux {
 	router := chi.NewRouter()
-	router.Use(middleware.Recoverer)
 	router.Use(middleware.Heartbeat("/healthz"))
+	router.Use(metrics.Middleware) // outside of Recoverer to count recovered panics as 500
+	router.Use(tracing.Middleware)
+	router.Use(middleware.Recoverer)
+
+	router.Handle("/metrics", metrics.Handler())
+	router.Get("/healthz/upstreams", healthHandler.Upstreams)
 
 	// Swagger UI
 	router.Get("/swagger/*", swagger.WrapHandler)
 
-	router.Post("/api/v1/rates/updates", rateHandler.ScheduleUpdate)
-	router.Get("/api/v1/rates/updates/{id}", rateHandler.GetByUpdateID)
-	router.Get("/api/v1/rates/supported-currencies", rateHandler.GetSupportedCodes)
-	router.Get("/api/v1/rates/{base:[A-Za-z]{3}}/{quote:[A-Za-z]{3}}", rateHandler.GetByCodes)
+	requireScope := func(scope string) func(http.Handler) http.Handler {
+		if keyHandler == nil {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return keyHandler.RequireScope(scope)
+	}
+	// limited after auth, so clients are told apart by keys rather than IPs
+	limit := func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return limiter.Middleware(next)
+	}
+
+	router.Group(func(r chi.Router) {
+		r.Use(requireScope(domain.ScopeRatesSchedule), limit)
+		r.Post("/api/v1/rates/updates", rateHandler.ScheduleUpdate)
+		r.Post("/api/v1/rates/updates:batch", rateHandler.ScheduleUpdatesBatch)
+	})
+	router.Group(func(r chi.Router) {
+		r.Use(requireScope(domain.ScopeRatesRead), limit)
+		r.Get("/api/v1/rates/updates/{id}", rateHandler.GetByUpdateID)
+		r.Get("/api/v1/rates/supported-currencies", rateHandler.GetSupportedCodes)
+		r.Get("/api/v1/rates/stream", rateHandler.StreamRates)
+		r.Get("/api/v1/rates/{base:[A-Za-z]{3}}/{quote:[A-Za-z]{3}}", rateHandler.GetByCodes)
+		r.Get("/api/v1/rates/{base:[A-Za-z]{3}}/{quote:[A-Za-z]{3}}/history", rateHandler.GetHistory)
+		r.Get("/api/v1/convert", rateHandler.Convert)
+	})
+
+	// admin routes exist only with auth, they must never be public
+	if keyHandler != nil {
+		router.Group(func(r chi.Router) {
+			r.Use(keyHandler.RequireScope(domain.ScopeCurrenciesAdmin), limit)
+			r.Post("/api/v1/admin/currencies", currencyHandler.AddCurrency)
+			r.Get("/api/v1/admin/currencies", currencyHandler.ListCurrencies)
+			r.Delete("/api/v1/admin/currencies/{code:[A-Za-z]{3}}", currencyHandler.DisableCurrency)
+		})
+		router.Group(func(r chi.Router) {
+			r.Use(keyHandler.RequireScope(domain.ScopeKeysAdmin), limit)
+			r.Post("/api/v1/admin/api-keys", keyHandler.CreateKey)
+			r.Get("/api/v1/admin/api-keys", keyHandler.ListKeys)
+			r.Delete("/api/v1/admin/api-keys/{id}", keyHandler.RevokeKey)
+		})
+	}
 	return router
 }
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fxrates/internal/apikey"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxCreateKeyBodyBytes = 4 << 10
+
+type CreateKeyRequest struct {
+	Owner     string     `json:"owner" example:"pricing-service"`
+	Scopes    []string   `json:"scopes" example:"rates:read,rates:schedule"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-02T15:04:05Z"`
+}
+
+// CreateKeyResponse carries the key itself, it's shown only once
+type CreateKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" example:"fxr_Q2hhbmdlIG1lIQ"`
+}
+
+// CreateKey godoc
+// @Summary Create API key
+// @Description Issue a key for the owner with the given scopes (rates:read, rates:schedule, keys:admin, currencies:admin) and optional expiry.
+// @Description The key is returned only in this response, just its hash is stored
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body CreateKeyRequest true "Key parameters"
+// @Success 201 {object} CreateKeyResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/api-keys [post]
+func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxCreateKeyBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	var req CreateKeyRequest
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	key, created, err := h.service.Create(r.Context(), req.Owner, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, apikey.ErrOwnerRequired) || errors.Is(err, apikey.ErrScopesRequired) ||
+			errors.Is(err, apikey.ErrUnknownScope) || errors.Is(err, apikey.ErrExpiryInPast) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "CreateKey", "owner": req.Owner}).Error("api key wasn't created")
+		writeError(w, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(CreateKeyResponse{APIKeyResponse: toAPIKeyResponse(created), Key: key})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fxrates/internal/domain"
+	"net/http"
+	"time"
+)
+
+type KeyService interface {
+	Create(ctx context.Context, owner string, scopes []string, expiresAt *time.Time) (string, domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	Authenticate(ctx context.Context, key string) (domain.APIKey, error)
+}
+
+type Handler struct {
+	service KeyService
+}
+
+func NewAPIKeyHandler(keyService KeyService) *Handler {
+	return &Handler{service: keyService}
+}
+
+type APIKeyResponse struct {
+	ID        int64      `json:"id" example:"1"`
+	Prefix    string     `json:"prefix" example:"fxr_Q2hhbmdl"`
+	Owner     string     `json:"owner" example:"pricing-service"`
+	Scopes    []string   `json:"scopes" example:"rates:read,rates:schedule"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2026-01-02T15:04:05Z"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at" example:"2025-01-02T15:04:05Z"`
+}
+
+func toAPIKeyResponse(k domain.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:        k.ID,
+		Prefix:    k.Prefix,
+		Owner:     k.Owner,
+		Scopes:    k.Scopes,
+		ExpiresAt: k.ExpiresAt,
+		RevokedAt: k.RevokedAt,
+		CreatedAt: k.CreatedAt,
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error" example:"something bad happened"`
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errorMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Error: errorMsg,
+	})
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fxrates/internal/apikey"
+	"fxrates/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockKeyService struct{ mock.Mock }
+
+func (m *MockKeyService) Create(ctx context.Context, owner string, scopes []string, expiresAt *time.Time) (string, domain.APIKey, error) {
+	args := m.Called(ctx, owner, scopes, expiresAt)
+	created, _ := args.Get(1).(domain.APIKey)
+	return args.String(0), created, args.Error(2)
+}
+
+func (m *MockKeyService) List(ctx context.Context) ([]domain.APIKey, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).([]domain.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockKeyService) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockKeyService) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	args := m.Called(ctx, key)
+	found, _ := args.Get(0).(domain.APIKey)
+	return found, args.Error(1)
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+func requireError(t *testing.T, rr *httptest.ResponseRecorder, status int, msg string) {
+	t.Helper()
+	require.Equal(t, status, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, msg, ej.Error)
+}
+
+// --- RequireScope ---
+
+func TestRequireScope_Rejections(t *testing.T) {
+	cases := []struct {
+		name       string
+		header     string
+		value      string
+		found      domain.APIKey
+		authErr    error
+		wantStatus int
+		wantMsg    string
+	}{
+		{name: "no key", wantStatus: http.StatusUnauthorized, wantMsg: "api key is required"},
+		{name: "invalid key", header: "X-API-Key", value: "fxr_bad", authErr: apikey.ErrInvalidKey, wantStatus: http.StatusUnauthorized, wantMsg: "invalid api key"},
+		{name: "lacks scope", header: "Authorization", value: "Bearer fxr_bad", found: domain.APIKey{Scopes: []string{domain.ScopeRatesRead}}, wantStatus: http.StatusForbidden, wantMsg: `api key lacks "rates:schedule" scope`},
+		{name: "auth error", header: "X-API-Key", value: "fxr_bad", authErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantMsg: "failed to authenticate api key"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(MockKeyService)
+			svc.On("Authenticate", mock.Anything, "fxr_bad").Return(tc.found, tc.authErr).Maybe()
+			h := NewAPIKeyHandler(svc)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { t.Fatal("next handler must not be called") })
+
+			req := httptest.NewRequest(http.MethodPost, "/rates/updates", nil)
+			if tc.header != "" {
+				req.Header.Set(tc.header, tc.value)
+			}
+			rr := httptest.NewRecorder()
+			h.RequireScope(domain.ScopeRatesSchedule)(next).ServeHTTP(rr, req)
+
+			requireError(t, rr, tc.wantStatus, tc.wantMsg)
+		})
+	}
+}
+
+func TestRequireScope_PassesKeyToNextHandler(t *testing.T) {
+	svc := new(MockKeyService)
+	svc.On("Authenticate", mock.Anything, "fxr_good").Return(domain.APIKey{ID: 3, Scopes: []string{domain.ScopeRatesRead}}, nil).Once()
+	h := NewAPIKeyHandler(svc)
+
+	var got domain.APIKey
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = apikey.FromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/rates/USD/EUR", nil)
+	req.Header.Set("X-API-Key", "fxr_good")
+	rr := httptest.NewRecorder()
+
+	h.RequireScope(domain.ScopeRatesRead)(next).ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusNoContent, rr.Code)
+	require.Equal(t, int64(3), got.ID)
+}
+
+// --- CreateKey ---
+
+func TestCreateKey_Created(t *testing.T) {
+	svc := new(MockKeyService)
+	h := NewAPIKeyHandler(svc)
+	createdAt := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	svc.On("Create", mock.Anything, "pricing", []string{domain.ScopeRatesRead}, (*time.Time)(nil)).
+		Return("fxr_secret", domain.APIKey{ID: 5, Prefix: "fxr_secr", Owner: "pricing", Scopes: []string{domain.ScopeRatesRead}, CreatedAt: createdAt}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBufferString(`{"owner":"pricing","scopes":["rates:read"]}`))
+	rr := httptest.NewRecorder()
+	h.CreateKey(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var res CreateKeyResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, "fxr_secret", res.Key)
+	require.Equal(t, int64(5), res.ID)
+	require.Equal(t, "fxr_secr", res.Prefix)
+	require.Equal(t, createdAt, res.CreatedAt)
+}
+
+func TestCreateKey_BadRequest(t *testing.T) {
+	svc := new(MockKeyService)
+	h := NewAPIKeyHandler(svc)
+	svc.On("Create", mock.Anything, "pricing", []string{"rates:write"}, (*time.Time)(nil)).Return("", nil, apikey.ErrUnknownScope).Once()
+
+	rr := httptest.NewRecorder()
+	h.CreateKey(rr, httptest.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBufferString(`{"owner":"pricing","scopes":["rates:write"]}`)))
+	requireError(t, rr, http.StatusBadRequest, apikey.ErrUnknownScope.Error())
+
+	rr = httptest.NewRecorder()
+	h.CreateKey(rr, httptest.NewRequest(http.MethodPost, "/admin/api-keys", bytes.NewBufferString(`{"owner":"pricing","role":"admin"}`)))
+	requireError(t, rr, http.StatusBadRequest, "invalid request body")
+}
+
+// --- ListKeys ---
+
+func TestListKeys_OK(t *testing.T) {
+	svc := new(MockKeyService)
+	h := NewAPIKeyHandler(svc)
+	svc.On("List", mock.Anything).Return([]domain.APIKey{{ID: 1, Owner: "a"}, {ID: 2, Owner: "b"}}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.ListKeys(rr, httptest.NewRequest(http.MethodGet, "/admin/api-keys", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res ListKeysResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res.Keys, 2)
+	require.Equal(t, "b", res.Keys[1].Owner)
+}
+
+// --- RevokeKey ---
+
+func revokeRequest(id string) *http.Request {
+	req := httptest.NewRequest(http.MethodDelete, "/admin/api-keys/"+id, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestRevokeKey(t *testing.T) {
+	svc := new(MockKeyService)
+	h := NewAPIKeyHandler(svc)
+	svc.On("Revoke", mock.Anything, int64(1)).Return(nil).Once()
+	svc.On("Revoke", mock.Anything, int64(2)).Return(domain.ErrAPIKeyNotFound).Once()
+
+	rr := httptest.NewRecorder()
+	h.RevokeKey(rr, revokeRequest("1"))
+	require.Equal(t, http.StatusNoContent, rr.Code)
+
+	rr = httptest.NewRecorder()
+	h.RevokeKey(rr, revokeRequest("2"))
+	requireError(t, rr, http.StatusNotFound, "api key not found or already revoked")
+
+	rr = httptest.NewRecorder()
+	h.RevokeKey(rr, revokeRequest("abc"))
+	requireError(t, rr, http.StatusBadRequest, "invalid key ID")
+}
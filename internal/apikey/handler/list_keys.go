@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ListKeysResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+// ListKeys godoc
+// @Summary List API keys
+// @Description List issued keys, revoked and expired ones included. Keys themselves aren't stored, only their prefixes are shown
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} ListKeysResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/api-keys [get]
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		msg := "ups, couldn't list api keys this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "ListKeys"}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+
+	res := ListKeysResponse{Keys: make([]APIKeyResponse, 0, len(keys))}
+	for _, k := range keys {
+		res.Keys = append(res.Keys, toAPIKeyResponse(k))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"fxrates/internal/apikey"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// RequireScope lets requests through only with an active key having the scope. The key is taken from
+// X-API-Key header or "Authorization: Bearer" and stored in the request context for the next handlers
+func (h *Handler) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := keyFromRequest(r)
+			if raw == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, "api key is required")
+				return
+			}
+
+			key, err := h.service.Authenticate(r.Context(), raw)
+			if err != nil {
+				if errors.Is(err, apikey.ErrInvalidKey) {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+					writeError(w, http.StatusUnauthorized, err.Error())
+					return
+				}
+				logrus.WithError(err).WithFields(logrus.Fields{"handler": "RequireScope"}).Error("ups, couldn't authenticate api key this time")
+				writeError(w, http.StatusInternalServerError, "failed to authenticate api key")
+				return
+			}
+			if !key.HasScope(scope) {
+				writeError(w, http.StatusForbidden, fmt.Sprintf("api key lacks %q scope", scope))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(apikey.WithKey(r.Context(), key)))
+		})
+	}
+}
+
+func keyFromRequest(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(apiKeyHeader)); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"fxrates/internal/domain"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// RevokeKey godoc
+// @Summary Revoke API key
+// @Description Revoke the key immediately, requests with it are rejected with 401 afterwards
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Key ID"
+// @Success 204
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/api-keys/{id} [delete]
+func (h *Handler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid key ID")
+		return
+	}
+
+	if err = h.service.Revoke(r.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			writeError(w, http.StatusNotFound, "api key not found or already revoked")
+			return
+		}
+		msg := "ups, couldn't revoke api key this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "RevokeKey", "id": id}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
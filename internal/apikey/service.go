@@ -0,0 +1,142 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidKey     = errors.New("invalid api key")
+	ErrOwnerRequired  = errors.New("owner is required")
+	ErrScopesRequired = errors.New("at least one scope is required")
+	ErrUnknownScope   = errors.New("unknown scope")
+	ErrExpiryInPast   = errors.New("expiry must be in the future")
+)
+
+const (
+	// keyPrefix marks keys of this service, so leaked keys are easy to find by secret scanners
+	keyPrefix        = "fxr_"
+	keySecretBytes   = 32
+	keyVisiblePrefix = len(keyPrefix) + 8
+	maxOwnerLength   = 256
+	adminOwner       = "admin"
+)
+
+type Service struct {
+	repo adapters.APIKeyRepository
+	// adminKeyHash is the hash of the configured bootstrap key having all scopes, empty disables it
+	adminKeyHash string
+	now          func() time.Time
+}
+
+// Create issues a key with the given scopes. The key itself is returned only here, just its hash is stored
+func (s *Service) Create(ctx context.Context, owner string, scopes []string, expiresAt *time.Time) (string, domain.APIKey, error) {
+	owner = strings.TrimSpace(owner)
+	if owner == "" || len(owner) > maxOwnerLength {
+		return "", domain.APIKey{}, ErrOwnerRequired
+	}
+	if len(scopes) == 0 {
+		return "", domain.APIKey{}, ErrScopesRequired
+	}
+	uniqueScopes := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(domain.Scopes, scope) {
+			return "", domain.APIKey{}, fmt.Errorf("%w: %q", ErrUnknownScope, scope)
+		}
+		if !slices.Contains(uniqueScopes, scope) {
+			uniqueScopes = append(uniqueScopes, scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(s.now()) {
+		return "", domain.APIKey{}, ErrExpiryInPast
+	}
+
+	secret := make([]byte, keySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", domain.APIKey{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	created, err := s.repo.Create(ctx, hashKey(key), domain.APIKey{
+		Prefix:    key[:keyVisiblePrefix],
+		Owner:     owner,
+		Scopes:    uniqueScopes,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", domain.APIKey{}, err
+	}
+	return key, created, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]domain.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Revoke revokes the key immediately, domain.ErrAPIKeyNotFound is returned for unknown or already revoked keys
+func (s *Service) Revoke(ctx context.Context, id int64) error {
+	return s.repo.Revoke(ctx, id)
+}
+
+// Authenticate returns the active key matching the given one, ErrInvalidKey is returned for unknown, revoked or expired keys
+func (s *Service) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	keyHash := hashKey(key)
+	if s.adminKeyHash != "" && subtle.ConstantTimeCompare([]byte(keyHash), []byte(s.adminKeyHash)) == 1 {
+		return domain.APIKey{Owner: adminOwner, Scopes: domain.Scopes}, nil
+	}
+	if !strings.HasPrefix(key, keyPrefix) {
+		return domain.APIKey{}, ErrInvalidKey // not issued by us, no need to ask DB
+	}
+
+	found, err := s.repo.GetByHash(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return domain.APIKey{}, ErrInvalidKey
+		}
+		return domain.APIKey{}, err
+	}
+	if !found.Active(s.now()) {
+		return domain.APIKey{}, ErrInvalidKey
+	}
+	return found, nil
+}
+
+// hashKey hashes keys with plain SHA-256, which is enough for random keys of 256 bits unlike passwords
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type contextKey struct{}
+
+// WithKey stores the authenticated key in the context
+func WithKey(ctx context.Context, key domain.APIKey) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// FromContext returns the key authenticated for the request, if any
+func FromContext(ctx context.Context) (domain.APIKey, bool) {
+	key, ok := ctx.Value(contextKey{}).(domain.APIKey)
+	return key, ok
+}
+
+// NewService creates api keys service. Non-empty adminKey is accepted as a key having all scopes,
+// which bootstraps issuing the first keys
+func NewService(repo adapters.APIKeyRepository, adminKey string) *Service {
+	s := &Service{repo: repo, now: time.Now}
+	if adminKey != "" {
+		s.adminKeyHash = hashKey(adminKey)
+	}
+	return s
+}
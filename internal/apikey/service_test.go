@@ -0,0 +1,167 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fxrates/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockAPIKeyRepository struct{ mock.Mock }
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, keyHash string, key domain.APIKey) (domain.APIKey, error) {
+	args := m.Called(ctx, keyHash, key)
+	created, _ := args.Get(0).(domain.APIKey)
+	return created, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	key, _ := args.Get(0).(domain.APIKey)
+	return key, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	args := m.Called(ctx)
+	keys, _ := args.Get(0).([]domain.APIKey)
+	return keys, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+var testNow = time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func newTestService(repo *MockAPIKeyRepository, adminKey string) *Service {
+	s := NewService(repo, adminKey)
+	s.now = func() time.Time { return testNow }
+	return s
+}
+
+// --- Create ---
+
+func TestService_Create_StoresOnlyHash(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+	svc := newTestService(repo, "")
+	expiresAt := testNow.Add(24 * time.Hour)
+
+	var storedHash string
+	var stored domain.APIKey
+	repo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		storedHash = args.String(1)
+		stored = args.Get(2).(domain.APIKey)
+	}).Return(domain.APIKey{ID: 7}, nil).Once()
+
+	key, created, err := svc.Create(context.Background(), " pricing ", []string{domain.ScopeRatesRead, domain.ScopeRatesRead, domain.ScopeRatesSchedule}, &expiresAt)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(7), created.ID)
+	require.True(t, strings.HasPrefix(key, "fxr_"))
+	require.Equal(t, hashKey(key), storedHash)
+	require.NotContains(t, storedHash, key)
+	require.Equal(t, key[:keyVisiblePrefix], stored.Prefix)
+	require.Equal(t, "pricing", stored.Owner)
+	require.Equal(t, []string{domain.ScopeRatesRead, domain.ScopeRatesSchedule}, stored.Scopes)
+	require.Equal(t, &expiresAt, stored.ExpiresAt)
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	past := testNow.Add(-time.Minute)
+	cases := []struct {
+		name      string
+		owner     string
+		scopes    []string
+		expiresAt *time.Time
+		wantErr   error
+	}{
+		{name: "no owner", owner: " ", scopes: []string{domain.ScopeRatesRead}, wantErr: ErrOwnerRequired},
+		{name: "no scopes", owner: "pricing", wantErr: ErrScopesRequired},
+		{name: "unknown scope", owner: "pricing", scopes: []string{"rates:write"}, wantErr: ErrUnknownScope},
+		{name: "expired", owner: "pricing", scopes: []string{domain.ScopeRatesRead}, expiresAt: &past, wantErr: ErrExpiryInPast},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockAPIKeyRepository)
+			_, _, err := newTestService(repo, "").Create(context.Background(), tc.owner, tc.scopes, tc.expiresAt)
+			require.ErrorIs(t, err, tc.wantErr)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// --- Authenticate ---
+
+func TestService_Authenticate_ActiveKey(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+	key := "fxr_secret"
+	repo.On("GetByHash", mock.Anything, hashKey(key)).Return(domain.APIKey{ID: 1, Scopes: []string{domain.ScopeRatesRead}}, nil).Once()
+
+	found, err := newTestService(repo, "").Authenticate(context.Background(), key)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(1), found.ID)
+}
+
+func TestService_Authenticate_InvalidKeys(t *testing.T) {
+	expired := testNow.Add(-time.Second)
+	revoked := testNow.Add(-time.Hour)
+	cases := []struct {
+		name  string
+		found domain.APIKey
+		err   error
+	}{
+		{name: "unknown", err: domain.ErrAPIKeyNotFound},
+		{name: "expired", found: domain.APIKey{ID: 1, ExpiresAt: &expired}},
+		{name: "revoked", found: domain.APIKey{ID: 1, RevokedAt: &revoked}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockAPIKeyRepository)
+			repo.On("GetByHash", mock.Anything, mock.Anything).Return(tc.found, tc.err).Once()
+
+			_, err := newTestService(repo, "").Authenticate(context.Background(), "fxr_secret")
+
+			require.ErrorIs(t, err, ErrInvalidKey)
+		})
+	}
+}
+
+func TestService_Authenticate_ForeignKey_NoDBCall(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+
+	_, err := newTestService(repo, "").Authenticate(context.Background(), "not-ours")
+
+	require.ErrorIs(t, err, ErrInvalidKey)
+	repo.AssertNotCalled(t, "GetByHash", mock.Anything, mock.Anything)
+}
+
+func TestService_Authenticate_AdminKey_HasAllScopes(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+
+	found, err := newTestService(repo, "bootstrap").Authenticate(context.Background(), "bootstrap")
+
+	require.NoError(t, err)
+	for _, scope := range domain.Scopes {
+		require.True(t, found.HasScope(scope), scope)
+	}
+	repo.AssertNotCalled(t, "GetByHash", mock.Anything, mock.Anything)
+}
+
+func TestService_Authenticate_RepoError_Propagates(t *testing.T) {
+	repo := new(MockAPIKeyRepository)
+	wantErr := errors.New("db down")
+	repo.On("GetByHash", mock.Anything, mock.Anything).Return(nil, wantErr).Once()
+
+	_, err := newTestService(repo, "").Authenticate(context.Background(), "fxr_secret")
+
+	require.ErrorIs(t, err, wantErr)
+	require.NotErrorIs(t, err, ErrInvalidKey)
+}
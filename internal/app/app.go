@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"fxrates/internal/platform/db"
+	grpcserver "fxrates/internal/platform/grpc"
 	httpserver "fxrates/internal/platform/http"
+	"fxrates/internal/platform/netguard"
+	"fxrates/internal/platform/tracing"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,16 +16,31 @@ import (
 	"syscall"
 	"time"
 
+	"fxrates/internal/adapters"
 	"fxrates/internal/adapters/cache"
+	"fxrates/internal/adapters/cassette"
+	"fxrates/internal/adapters/composite"
+	"fxrates/internal/adapters/fixture"
 	"fxrates/internal/adapters/httpclient"
 	"fxrates/internal/adapters/postgres"
+	"fxrates/internal/adapters/pubsub"
+	"fxrates/internal/adapters/resilience"
 	"fxrates/internal/api"
+	"fxrates/internal/apikey"
+	apikeyhandler "fxrates/internal/apikey/handler"
 	"fxrates/internal/config"
+	"fxrates/internal/currency"
+	currencyhandler "fxrates/internal/currency/handler"
+	"fxrates/internal/health"
 	"fxrates/internal/rate"
+	"fxrates/internal/rate/grpcapi"
+	"fxrates/internal/rate/grpcapi/fxratesv1"
 	"fxrates/internal/rate/handler"
+	"fxrates/internal/ratelimit"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 // Run wires the application components, starts HTTP server and scheduler
@@ -32,13 +50,7 @@ func Run() error {
 		return err
 	}
 	// Logger
-	logrus.SetOutput(os.Stdout)
-	cfgLevel := appCfg.Logging.Level
-	if parsedLevel, parseErr := logrus.ParseLevel(cfgLevel); parseErr != nil {
-		logrus.SetLevel(logrus.InfoLevel)
-	} else {
-		logrus.SetLevel(parsedLevel)
-	}
+	setupLogger(appCfg.Logging)
 	logrus.Info("✅ Config initialization successful")
 
 	// Root context bound to OS signals for graceful shutdown
@@ -49,43 +61,70 @@ func Run() error {
 	startupCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	// DB pool
-	pool, err := db.CreatePoolAndPing(startupCtx, appCfg.DbServer)
+	// Tracing, set up first so DB and HTTP clients are instrumented
+	shutdownTracing, err := tracing.Setup(startupCtx, appCfg.Tracing)
 	if err != nil {
-		return fmt.Errorf("failed to establish DB connection: %w", err)
+		return fmt.Errorf("tracing initialization failed: %w", err)
 	}
-	defer pool.Close()
-	logrus.Info("✅ Postgres connection successful")
-
-	// Load supported currencies codes
-	supportedCodes, err := loadSupportedCodes(startupCtx, pool)
-	if err != nil || len(supportedCodes) == 0 {
-		if err == nil {
-			err = errors.New("no currencies available")
+	// Flush spans after everything else stopped
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if shutdownErr := shutdownTracing(flushCtx); shutdownErr != nil {
+			logrus.Errorf("tracing shutdown error: %v", shutdownErr)
 		}
+	}()
+
+	// Storage: Postgres pool, or in-memory repositories for local runs
+	repos, err := newStorage(startupCtx, appCfg.Storage, appCfg.DbServer, appCfg.Scheduler.NotifyEnabled)
+	if err != nil {
+		return err
+	}
+	defer repos.Close()
+	if repos.pool != nil {
+		logrus.Info("✅ Postgres connection successful")
+	} else {
+		logrus.Warn("In-memory storage is used, data is lost on exit and isn't shared by instances")
+	}
+
+	// Supported currencies, refreshed at runtime by the currency service
+	pivotCurrency := strings.ToUpper(strings.TrimSpace(appCfg.Rates.PivotCurrency))
+	rateValidator := rate.NewValidator(nil)
+	currencyService := currency.NewService(repos.currencies, rateValidator, pivotCurrency)
+	if err = currencyService.Refresh(startupCtx); err != nil {
 		return fmt.Errorf("error loading supported currencies: %w", err)
 	}
+	if len(rateValidator.SupportedCodes()) == 0 {
+		return errors.New("error loading supported currencies: no currencies available")
+	}
+	// Pivot currency for triangulation (empty disables it)
+	if pivotCurrency != "" && !rateValidator.IsSupported(pivotCurrency) {
+		return fmt.Errorf("pivot currency %q is not supported", pivotCurrency)
+	}
 
 	// Base HTTP client
-	httpTimeout := time.Duration(appCfg.HTTPClient.TimeoutSeconds) * time.Second
-	if httpTimeout <= 0 {
-		httpTimeout = 10 * time.Second
+	baseHTTPClient := newHTTPClient(appCfg.HTTPClient)
+
+	// Upstream budget (nil when unlimited), charged by the scheduler for base fetches and by providers for retries
+	upstreamBudget, err := newUpstreamBudget(appCfg.UpstreamBudget, repos.pool)
+	if err != nil {
+		return fmt.Errorf("upstream budget initialization failed: %w", err)
 	}
-	baseHTTPClient := &http.Client{Timeout: httpTimeout}
 
 	// External clients
-	exchangeAPIBaseURL := strings.TrimSuffix(appCfg.ExchangeRateAPI.BaseURL, "/")
-	if appCfg.ExchangeRateAPI.APIKey == "" {
-		return fmt.Errorf("exchange rate api key is required")
+	rateClient, breakers, err := newRateClient(appCfg.ExchangeRateAPI, baseHTTPClient, upstreamBudget)
+	if err != nil {
+		return fmt.Errorf("rate provider initialization failed: %w", err)
 	}
-	rateClient := httpclient.NewExchangeRateClient(
-		baseHTTPClient,
-		fmt.Sprintf("%s/%s/latest", exchangeAPIBaseURL, appCfg.ExchangeRateAPI.APIKey),
-	)
+	defer closeRateClient(rateClient)
 
-	// Repositories
-	rateUpdateRepo := postgres.NewRateUpdateRepository(pool)
-	rateRepo := postgres.NewRateRepository(pool)
+	// Callbacks are signed, so they're enabled only along with the secret
+	var callbackRepo adapters.RateUpdateCallbackRepository
+	if appCfg.Webhooks.Secret != "" {
+		callbackRepo = repos.callbacks
+	} else {
+		logrus.Warn("WEBHOOK_SECRET isn't set, callback_url of rate updates is disabled")
+	}
 
 	// Cache
 	rateUpdateCache, err := cache.NewRateUpdateCache(appCfg.Cache.RateUpdatesMaxItems)
@@ -94,11 +133,52 @@ func Run() error {
 	}
 	defer rateUpdateCache.Close()
 
+	// In-process pub/sub of applied rates feeding rate streams
+	rateBroker := pubsub.NewRateBroker(appCfg.Streams.SubscriberBufferSize)
+	defer rateBroker.Close()
+	// Requests long-polling pending updates, woken by update runs
+	updateWaiters := pubsub.NewUpdateWaiters(appCfg.Streams.UpdateMaxWaiters)
+	defer updateWaiters.Close()
+
 	// Services
-	rateService := rate.NewService(rateUpdateRepo, rateRepo, rateUpdateCache)
-	rateValidator := rate.NewValidator(supportedCodes)
-	scheduler := rate.NewScheduler(rateUpdateRepo, rateClient, rateUpdateCache, time.Duration(appCfg.Scheduler.UpdateRatesJobDurationSec)*time.Second)
-	// Ensure scheduler stops before DB pool closes
+	rateService := rate.NewService(repos.updates, repos.rates, rateUpdateCache, callbackRepo, pivotCurrency).
+		WithMinorUnits(rateValidator.MinorUnits)
+	if appCfg.Webhooks.AllowPrivateURLs {
+		logrus.Warn("Callbacks may reach private networks, WEBHOOK_ALLOW_PRIVATE_URLS must be off in production")
+		rateService.WithPrivateCallbackURLs()
+	}
+	scheduler := rate.NewScheduler(
+		repos.updates,
+		rateClient,
+		rateUpdateCache,
+		rateBroker,
+		time.Duration(appCfg.Scheduler.UpdateRatesJobDurationSec)*time.Second,
+		jobOptions(appCfg.Scheduler, pivotCurrency, fetchTimeout(appCfg.ExchangeRateAPI), upstreamBudget, updateWaiters),
+	)
+	if repos.wakeups != nil {
+		if repos.listen != nil {
+			go repos.listen(ctx)
+		}
+		scheduler.WithWakeups(repos.wakeups, time.Duration(appCfg.Scheduler.NotifyDebounceMs)*time.Millisecond)
+	}
+	if callbackRepo != nil {
+		batchSize := appCfg.Webhooks.BatchSize
+		if batchSize <= 0 {
+			batchSize = 50
+		}
+		scheduler.WithCallbacks(
+			callbackRepo,
+			httpclient.NewWebhookClient(newWebhookHTTPClient(appCfg.HTTPClient, appCfg.Webhooks.AllowPrivateURLs), appCfg.Webhooks.Secret),
+			time.Duration(appCfg.Webhooks.DeliverCallbacksJobDurationSec)*time.Second,
+			rate.CallbackOptions{
+				BatchSize:      batchSize,
+				MaxAttempts:    appCfg.Webhooks.MaxAttempts,
+				InitialBackoff: time.Duration(appCfg.Webhooks.InitialBackoffSec) * time.Second,
+				MaxBackoff:     time.Duration(appCfg.Webhooks.MaxBackoffSec) * time.Second,
+			},
+		)
+	}
+	// Ensure scheduler stops before storage closes
 	defer func() {
 		if shutDownErr := scheduler.Shutdown(); shutDownErr != nil {
 			logrus.Errorf("scheduler shutdown error: %v", shutDownErr)
@@ -110,37 +190,268 @@ func Run() error {
 	}
 	logrus.Info("✅ Scheduler activation successful")
 
+	// Pick up currencies changed through other instances
+	if appCfg.Rates.CurrenciesRefreshSec > 0 {
+		go currencyService.RefreshEvery(ctx, time.Duration(appCfg.Rates.CurrenciesRefreshSec)*time.Second)
+	}
+
 	// Handlers and router
-	rateHandler := handler.NewRateHandler(rateValidator, rateService)
-	router := api.NewRouter(rateHandler)
+	rateHandler := handler.NewRateHandler(rateValidator, rateService, rateBroker)
+	if appCfg.Streams.UpdateWaitMaxSec > 0 {
+		rateHandler.WithUpdateWaits(updateWaiters, time.Duration(appCfg.Streams.UpdateWaitMaxSec)*time.Second)
+	}
+	var keyService *apikey.Service
+	var keyHandler *apikeyhandler.Handler
+	if appCfg.Auth.Enabled {
+		keyService = apikey.NewService(repos.apiKeys, appCfg.Auth.AdminKey)
+		keyHandler = apikeyhandler.NewAPIKeyHandler(keyService)
+	} else {
+		logrus.Warn("API key auth is disabled, all routes are public and admin routes are off")
+	}
+	var limiter *ratelimit.ClientLimiter
+	if appCfg.RateLimit.RequestsPerSec > 0 {
+		limiter = ratelimit.NewClientLimiter(appCfg.RateLimit.RequestsPerSec, appCfg.RateLimit.Burst)
+	}
+	currencyHandler := currencyhandler.NewCurrencyHandler(currencyService)
+	healthHandler := health.NewHealthHandler(breakers...)
+	router := api.NewRouter(rateHandler, keyHandler, currencyHandler, healthHandler, limiter)
+
+	// gRPC API on its own port, shut down along with the HTTP server
+	grpcErrCh := make(chan error, 1)
+	if appCfg.GRPCServer.Port != "" {
+		// the limiter is shared with the REST API, so a client has a single budget over both
+		var unary []grpc.UnaryServerInterceptor
+		var stream []grpc.StreamServerInterceptor
+		if keyService != nil {
+			unary, stream = append(unary, grpcapi.UnaryAuth(keyService)), append(stream, grpcapi.StreamAuth(keyService))
+		}
+		if limiter != nil {
+			unary, stream = append(unary, grpcapi.UnaryRateLimit(limiter)), append(stream, grpcapi.StreamRateLimit(limiter))
+		}
+		grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+		fxratesv1.RegisterRatesServiceServer(grpcServer, grpcapi.NewRatesServer(rateValidator, rateService, rateBroker))
+		go func() {
+			grpcErr := grpcserver.Start(ctx, appCfg.GRPCServer, grpcServer, rateBroker.Close)
+			if grpcErr != nil {
+				stop() // takes the HTTP server down too
+			}
+			grpcErrCh <- grpcErr
+		}()
+	} else {
+		grpcErrCh <- nil
+	}
 
 	// Block until context is canceled, then perform graceful shutdown.
-	if serverErr := httpserver.Start(ctx, appCfg.HTTPServer, router); serverErr != nil {
+	serverErr := httpserver.Start(ctx, appCfg.HTTPServer, router, rateBroker.Close, updateWaiters.Close)
+	if serverErr != nil {
 		// Cancel the root context to stop scheduler and other in-flight work
 		stop()
+	}
+	grpcErr := <-grpcErrCh
+	if serverErr != nil {
 		return fmt.Errorf("HTTP server error: %w", serverErr)
 	}
+	if grpcErr != nil {
+		return fmt.Errorf("gRPC server error: %w", grpcErr)
+	}
 	return nil
 }
 
-// loadSupportedCodes loads supported currencies codes from DB
-func loadSupportedCodes(ctx context.Context, pool *pgxpool.Pool) (map[string]struct{}, error) {
-	rows, err := pool.Query(ctx, `select code from currencies`)
-	if err != nil {
-		return nil, err
+func setupLogger(cfg config.Logging) {
+	logrus.SetOutput(os.Stdout)
+	if parsedLevel, parseErr := logrus.ParseLevel(cfg.Level); parseErr != nil {
+		logrus.SetLevel(logrus.InfoLevel)
+	} else {
+		logrus.SetLevel(parsedLevel)
+	}
+}
+
+// newHTTPClient builds the base client of outgoing requests, they carry the trace context
+func newHTTPClient(cfg config.HTTPClient) *http.Client {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &http.Client{Timeout: timeout, Transport: tracing.Transport(http.DefaultTransport)}
+}
+
+// newWebhookHTTPClient builds the client of callbacks. Callback URLs are given by API clients, so unless allowPrivate
+// it dials public addresses only, whatever their hosts resolve to at delivery time
+func newWebhookHTTPClient(cfg config.HTTPClient, allowPrivate bool) *http.Client {
+	client := newHTTPClient(cfg)
+	if !allowPrivate {
+		client.Transport = tracing.Transport(netguard.Transport())
 	}
-	defer rows.Close()
+	return client
+}
 
-	m := make(map[string]struct{})
-	for rows.Next() {
-		var c string
-		if err = rows.Scan(&c); err != nil {
-			return nil, err
+// jobOptions tunes update runs by the scheduler config, nil notifier and budget are allowed
+func jobOptions(cfg config.Scheduler, pivotCurrency string, fetchTimeout time.Duration, budget adapters.UpstreamBudget, notifier adapters.UpdateNotifier) rate.JobOptions {
+	opts := rate.JobOptions{
+		PivotCurrency: pivotCurrency,
+		FetchTimeout:  fetchTimeout,
+		MaxAttempts:   cfg.UpdateMaxAttempts,
+		MaxAge:        time.Duration(cfg.UpdateMaxAgeSec) * time.Second,
+		Budget:        budget,
+		ClaimLease:    time.Duration(cfg.UpdateClaimLeaseSec) * time.Second,
+		Notifier:      notifier,
+	}
+	if cfg.QuotaBackoffSec > 0 {
+		opts.QuotaBackoff = rate.NewQuotaBackoff(time.Duration(cfg.QuotaBackoffSec)*time.Second, time.Duration(cfg.QuotaBackoffMaxSec)*time.Second)
+	}
+	return opts
+}
+
+// fetchTimeout is the deadline of a base fetch: failover may ask every provider in turn, consensus asks them at once.
+// Zero leaves the job default
+func fetchTimeout(cfg config.ExchangeRateAPI) time.Duration {
+	timeout := time.Duration(cfg.ProviderTimeoutMs) * time.Millisecond
+	if cfg.Mode == "consensus" || len(cfg.Providers) < 2 {
+		return timeout
+	}
+	return timeout * time.Duration(len(cfg.Providers))
+}
+
+// newRateClient builds the configured providers, recording their calls to the cassette or replaced by it.
+// Breakers of the upstream providers are returned for health reporting
+func newRateClient(cfg config.ExchangeRateAPI, httpClient *http.Client, budget adapters.UpstreamBudget) (adapters.RateClient, []*resilience.Breaker, error) {
+	switch cfg.Cassette.Mode {
+	case "":
+		return newProvidersClient(cfg, httpClient, budget)
+	case cassette.ModeReplay:
+		player, err := cassette.NewPlayer(cfg.Cassette.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cassette replay: %w", err)
+		}
+		return player, nil, nil
+	case cassette.ModeRecord:
+		client, breakers, err := newProvidersClient(cfg, httpClient, budget)
+		if err != nil {
+			return nil, nil, err
+		}
+		recorder, err := cassette.NewRecorder(client, cfg.Cassette.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cassette recording: %w", err)
 		}
-		m[c] = struct{}{}
+		return recorder, breakers, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown cassette mode %q", cfg.Cassette.Mode)
 	}
-	if err = rows.Err(); err != nil {
-		return nil, err
+}
+
+// closeRateClient closes the cassette file the client records to, other clients have nothing to close
+func closeRateClient(client adapters.RateClient) {
+	closer, ok := client.(io.Closer)
+	if !ok {
+		return
+	}
+	if err := closer.Close(); err != nil {
+		logrus.Errorf("cassette close error: %v", err)
+	}
+}
+
+// newProvidersClient builds provider adapters in the configured order and combines them according to the mode.
+// Upstream providers are retried within the budget and guarded by a breaker each, so failover skips the ones that are down
+func newProvidersClient(cfg config.ExchangeRateAPI, httpClient *http.Client, budget adapters.UpstreamBudget) (adapters.RateClient, []*resilience.Breaker, error) {
+	names := cfg.Providers
+	if len(names) == 0 {
+		names = []string{httpclient.ExchangeRateProvider}
+	}
+	if cfg.ProviderTimeoutMs > 0 && cfg.Retry.AttemptTimeoutMs >= cfg.ProviderTimeoutMs {
+		return nil, nil, fmt.Errorf("retry attempt timeout %dms must be shorter than the provider timeout %dms", cfg.Retry.AttemptTimeoutMs, cfg.ProviderTimeoutMs)
+	}
+
+	retry := resilience.RetryPolicy{
+		MaxAttempts:    cfg.Retry.MaxAttempts,
+		InitialBackoff: time.Duration(cfg.Retry.InitialBackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.Retry.MaxBackoffMs) * time.Millisecond,
+		AttemptTimeout: time.Duration(cfg.Retry.AttemptTimeoutMs) * time.Millisecond,
+	}
+	var breakers []*resilience.Breaker
+	guard := func(name string, upstream adapters.RateClient) adapters.RateClient {
+		var breaker *resilience.Breaker
+		if cfg.Breaker.FailureThreshold > 0 {
+			breaker = resilience.NewBreaker(name, resilience.BreakerOptions{
+				FailureThreshold: cfg.Breaker.FailureThreshold,
+				OpenTimeout:      time.Duration(cfg.Breaker.OpenSec) * time.Second,
+			})
+			breakers = append(breakers, breaker)
+		}
+		client := resilience.NewRateClient(name, upstream, retry, breaker)
+		if budget != nil {
+			client.WithBudget(budget)
+		}
+		return client
+	}
+
+	providers := make([]adapters.RateClient, 0, len(names))
+	for _, name := range names {
+		switch name = strings.TrimSpace(name); name {
+		case httpclient.ExchangeRateProvider:
+			if cfg.APIKey == "" {
+				return nil, nil, fmt.Errorf("exchange rate api key is required")
+			}
+			providers = append(providers, guard(name, httpclient.NewExchangeRateClient(
+				httpClient,
+				fmt.Sprintf("%s/%s/latest", strings.TrimSuffix(cfg.BaseURL, "/"), cfg.APIKey),
+			)))
+		case httpclient.OpenERAPIProvider:
+			providers = append(providers, guard(name, httpclient.NewOpenERAPIClient(httpClient, cfg.OpenERAPI.BaseURL)))
+		case httpclient.FrankfurterProvider:
+			providers = append(providers, guard(name, httpclient.NewFrankfurterClient(httpClient, cfg.Frankfurter.BaseURL)))
+		case fixture.FileProvider:
+			fileClient, err := fixture.NewFileRateClient(cfg.File.Dir)
+			if err != nil {
+				return nil, nil, fmt.Errorf("file rate provider: %w", err)
+			}
+			providers = append(providers, fileClient)
+		default:
+			return nil, nil, fmt.Errorf("unknown rate provider %q", name)
+		}
+	}
+
+	switch cfg.Mode {
+	case "", "failover":
+		if len(providers) == 1 {
+			return providers[0], breakers, nil
+		}
+		return composite.NewFailoverRateClient(providers...).
+			WithProviderTimeout(time.Duration(cfg.ProviderTimeoutMs) * time.Millisecond), breakers, nil
+	case "consensus":
+		opts := composite.ConsensusOptions{
+			Method:       composite.ConsensusMethod(cfg.Consensus.Method),
+			Tolerance:    cfg.Consensus.Tolerance,
+			MinProviders: cfg.Consensus.MinProviders,
+		}
+		if opts.Method != composite.ConsensusMedian && opts.Method != composite.ConsensusTrimmedMean {
+			return nil, nil, fmt.Errorf("unknown consensus method %q", cfg.Consensus.Method)
+		}
+		if opts.Tolerance <= 0 {
+			return nil, nil, fmt.Errorf("consensus tolerance must be positive")
+		}
+		if opts.MinProviders < 1 || opts.MinProviders > len(providers) {
+			return nil, nil, fmt.Errorf("consensus min providers must be between 1 and %d", len(providers))
+		}
+		return composite.NewConsensusRateClient(opts, providers...), breakers, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown rate providers mode %q", cfg.Mode)
+	}
+}
+
+// newUpstreamBudget builds the budget of base fetches in the configured store, nil means unlimited. The pool is nil with in-memory storage
+func newUpstreamBudget(cfg config.UpstreamBudget, pool *pgxpool.Pool) (adapters.UpstreamBudget, error) {
+	if cfg.PerMinute <= 0 && cfg.PerDay <= 0 {
+		return nil, nil
+	}
+	switch cfg.Store {
+	case "", "memory":
+		return ratelimit.NewMemoryBudget(cfg.PerMinute, cfg.PerDay), nil
+	case "postgres":
+		if pool == nil {
+			return nil, fmt.Errorf("postgres upstream budget store requires the postgres storage driver")
+		}
+		return postgres.NewUpstreamBudget(pool, cfg.PerMinute, cfg.PerDay), nil
+	default:
+		return nil, fmt.Errorf("unknown upstream budget store %q", cfg.Store)
 	}
-	return m, nil
 }
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/adapters/cache"
+	"fxrates/internal/adapters/postgres"
+	"fxrates/internal/config"
+	"fxrates/internal/platform/db"
+	"fxrates/internal/rate"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// RunUpdateJob runs UpdatePendingRates once against the configured DB and providers and returns, so pending updates
+// can be applied by hand while the scheduler is down. Claims keep it from clashing with running replicas
+func RunUpdateJob(ctx context.Context) error {
+	appCfg, err := config.Init()
+	if err != nil {
+		return err
+	}
+	setupLogger(appCfg.Logging)
+	// in-memory updates live in the server process, there's nothing for a separate run to process
+	if appCfg.Storage.Driver == storageMemory {
+		return fmt.Errorf("running the update job needs the %s storage driver", storagePostgres)
+	}
+
+	pool, err := db.CreatePoolAndPing(ctx, appCfg.DbServer)
+	if err != nil {
+		return fmt.Errorf("failed to establish DB connection: %w", err)
+	}
+	defer pool.Close()
+
+	upstreamBudget, err := newUpstreamBudget(appCfg.UpstreamBudget, pool)
+	if err != nil {
+		return fmt.Errorf("upstream budget initialization failed: %w", err)
+	}
+	rateClient, _, err := newRateClient(appCfg.ExchangeRateAPI, newHTTPClient(appCfg.HTTPClient), upstreamBudget)
+	if err != nil {
+		return fmt.Errorf("rate provider initialization failed: %w", err)
+	}
+	defer closeRateClient(rateClient)
+	// the job needs a cache to clean, servers keep their own as they do for updates applied by other replicas
+	rateUpdateCache, err := cache.NewRateUpdateCache(appCfg.Cache.RateUpdatesMaxItems)
+	if err != nil {
+		return fmt.Errorf("cache initialization failed: %w", err)
+	}
+	defer rateUpdateCache.Close()
+
+	pivotCurrency := strings.ToUpper(strings.TrimSpace(appCfg.Rates.PivotCurrency))
+	execID := uuid.NewString()
+	logrus.Infof("Running update job once; execID: %s", execID)
+	return rate.UpdatePendingRates(
+		ctx,
+		execID,
+		postgres.NewRateUpdateRepository(pool),
+		rateClient,
+		rateUpdateCache,
+		nil,
+		jobOptions(appCfg.Scheduler, pivotCurrency, fetchTimeout(appCfg.ExchangeRateAPI), upstreamBudget, nil),
+	)
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/adapters/memory"
+	"fxrates/internal/adapters/postgres"
+	"fxrates/internal/config"
+	"fxrates/internal/platform/db"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+)
+
+// storage is the set of repositories of the configured driver
+type storage struct {
+	rates      adapters.RateRepository
+	updates    adapters.RateUpdateRepository
+	callbacks  adapters.RateUpdateCallbackRepository
+	apiKeys    adapters.APIKeyRepository
+	currencies adapters.CurrencyRepository
+	// pool is nil for the memory driver
+	pool *pgxpool.Pool
+	// wakeups receives a value once updates are scheduled when notifications are enabled,
+	// listen (when set) must be running for them to arrive
+	wakeups <-chan struct{}
+	listen  func(ctx context.Context)
+}
+
+// newStorage connects the repositories of the configured driver. The memory one starts with the currencies
+// seeded by migrations
+func newStorage(ctx context.Context, cfg config.Storage, dbCfg config.DbServer, notify bool) (*storage, error) {
+	switch cfg.Driver {
+	case "", storagePostgres:
+		pool, err := db.CreatePoolAndPing(ctx, dbCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to establish DB connection: %w", err)
+		}
+		updates := postgres.NewRateUpdateRepository(pool)
+		s := &storage{
+			rates:      postgres.NewRateRepository(pool),
+			updates:    updates,
+			callbacks:  postgres.NewRateUpdateCallbackRepository(pool),
+			apiKeys:    postgres.NewAPIKeyRepository(pool),
+			currencies: postgres.NewCurrencyRepository(pool),
+			pool:       pool,
+		}
+		if notify {
+			updates.WithNotify(postgres.RateUpdatesChannel)
+			listener := postgres.NewUpdateListener(pool, postgres.RateUpdatesChannel)
+			s.wakeups, s.listen = listener.Wakeups(), listener.Listen
+		}
+		return s, nil
+	case storageMemory:
+		store := memory.NewStore(memory.DefaultCurrencies...)
+		updates := memory.NewRateUpdateRepository(store)
+		s := &storage{
+			rates:      memory.NewRateRepository(store),
+			updates:    updates,
+			callbacks:  memory.NewRateUpdateCallbackRepository(store),
+			apiKeys:    memory.NewAPIKeyRepository(store),
+			currencies: memory.NewCurrencyRepository(store),
+		}
+		if notify {
+			s.wakeups = updates.WithNotify().Wakeups()
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+func (s *storage) Close() {
+	if s.pool != nil {
+		s.pool.Close()
+	}
+}
@@ -12,6 +12,16 @@ type HTTPServer struct {
 	Port string `mapstructure:"port"`
 }
 
+// GRPCServer serves the gRPC API, empty port disables it
+type GRPCServer struct {
+	Port string `mapstructure:"port"`
+}
+
+// Storage selects the repositories backend: postgres, or memory for local runs and tests (single instance, lost on exit)
+type Storage struct {
+	Driver string `mapstructure:"driver"`
+}
+
 type DbServer struct {
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
@@ -30,12 +40,21 @@ func (config *DbServer) GetConnectionStr() string {
 
 type AppConfig struct {
 	HTTPServer      HTTPServer      `mapstructure:"http_server"`
+	GRPCServer      GRPCServer      `mapstructure:"grpc_server"`
+	Storage         Storage         `mapstructure:"storage"`
 	DbServer        DbServer        `mapstructure:"db_server"`
 	HTTPClient      HTTPClient      `mapstructure:"http_client"`
 	ExchangeRateAPI ExchangeRateAPI `mapstructure:"exchange_rate_api"`
 	Logging         Logging         `mapstructure:"logging"`
 	Scheduler       Scheduler       `mapstructure:"scheduler"`
 	Cache           Cache           `mapstructure:"cache"`
+	Rates           Rates           `mapstructure:"rates"`
+	Webhooks        Webhooks        `mapstructure:"webhooks"`
+	Streams         Streams         `mapstructure:"streams"`
+	Tracing         Tracing         `mapstructure:"tracing"`
+	Auth            Auth            `mapstructure:"auth"`
+	RateLimit       RateLimit       `mapstructure:"rate_limit"`
+	UpstreamBudget  UpstreamBudget  `mapstructure:"upstream_budget"`
 }
 
 type HTTPClient struct {
@@ -47,18 +66,123 @@ type Logging struct {
 }
 
 type ExchangeRateAPI struct {
+	BaseURL     string      `mapstructure:"base_url"`
+	APIKey      string      `mapstructure:"api_key"`
+	Providers   []string    `mapstructure:"providers"`
+	Mode        string      `mapstructure:"mode"`
+	Consensus   Consensus   `mapstructure:"consensus"`
+	OpenERAPI   ProviderAPI `mapstructure:"open_er_api"`
+	Frankfurter ProviderAPI `mapstructure:"frankfurter"`
+	File        FileAPI     `mapstructure:"file"`
+	Cassette    Cassette    `mapstructure:"cassette"`
+	Retry       Retry       `mapstructure:"retry"`
+	Breaker     Breaker     `mapstructure:"breaker"`
+
+	// ProviderTimeoutMs bounds a call of every provider, retries included, so a hanging one leaves time for the fallbacks
+	ProviderTimeoutMs int `mapstructure:"provider_timeout_ms"`
+}
+
+type Consensus struct {
+	Method       string  `mapstructure:"method"`
+	Tolerance    float64 `mapstructure:"tolerance"`
+	MinProviders int     `mapstructure:"min_providers"`
+}
+
+type ProviderAPI struct {
 	BaseURL string `mapstructure:"base_url"`
-	APIKey  string `mapstructure:"api_key"`
+}
+
+// FileAPI is the offline provider serving rates from fixtures in Dir
+type FileAPI struct {
+	Dir string `mapstructure:"dir"`
+}
+
+// Cassette records rate client calls to the file at Path or replays them instead of calling providers,
+// Mode is "record", "replay" or empty to disable
+type Cassette struct {
+	Mode string `mapstructure:"mode"`
+	Path string `mapstructure:"path"`
+}
+
+// Retry of upstream calls failing with 5xx, timeouts or exhausted quota, MaxAttempts of 1 or less disables it.
+// AttemptTimeoutMs bounds every attempt, it must be shorter than ProviderTimeoutMs to leave time for retries
+type Retry struct {
+	MaxAttempts      int `mapstructure:"max_attempts"`
+	InitialBackoffMs int `mapstructure:"initial_backoff_ms"`
+	MaxBackoffMs     int `mapstructure:"max_backoff_ms"`
+	AttemptTimeoutMs int `mapstructure:"attempt_timeout_ms"`
+}
+
+// Breaker of every upstream provider, FailureThreshold of 0 disables it
+type Breaker struct {
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	OpenSec          int `mapstructure:"open_sec"`
 }
 
 type Scheduler struct {
 	UpdateRatesJobDurationSec int `mapstructure:"update_rates_job_duration_sec"`
+	UpdateMaxAttempts         int `mapstructure:"update_max_attempts"`
+	UpdateMaxAgeSec           int `mapstructure:"update_max_age_sec"`
+	UpdateClaimLeaseSec       int `mapstructure:"update_claim_lease_sec"`
+	// NotifyEnabled wakes the scheduler up via LISTEN/NOTIFY once updates are scheduled
+	NotifyEnabled    bool `mapstructure:"notify_enabled"`
+	NotifyDebounceMs int  `mapstructure:"notify_debounce_ms"`
+	// QuotaBackoffSec pauses update runs once a provider reported its quota exhausted, doubled up to QuotaBackoffMaxSec
+	QuotaBackoffSec    int `mapstructure:"quota_backoff_sec"`
+	QuotaBackoffMaxSec int `mapstructure:"quota_backoff_max_sec"`
 }
 
 type Cache struct {
 	RateUpdatesMaxItems int64 `mapstructure:"rate_updates_max_items"`
 }
 
+type Rates struct {
+	PivotCurrency string `mapstructure:"pivot_currency"`
+	// CurrenciesRefreshSec is how often supported currencies are reloaded to pick up changes of other instances
+	CurrenciesRefreshSec int `mapstructure:"currencies_refresh_sec"`
+}
+
+type Webhooks struct {
+	Secret                         string `mapstructure:"secret"`
+	DeliverCallbacksJobDurationSec int    `mapstructure:"deliver_callbacks_job_duration_sec"`
+	BatchSize                      int    `mapstructure:"batch_size"`
+	MaxAttempts                    int    `mapstructure:"max_attempts"`
+	InitialBackoffSec              int    `mapstructure:"initial_backoff_sec"`
+	MaxBackoffSec                  int    `mapstructure:"max_backoff_sec"`
+	// AllowPrivateURLs lets callbacks reach loopback, private and link-local addresses, for local development only
+	AllowPrivateURLs bool `mapstructure:"allow_private_urls"`
+}
+
+type Streams struct {
+	SubscriberBufferSize int `mapstructure:"subscriber_buffer_size"`
+	UpdateWaitMaxSec     int `mapstructure:"update_wait_max_sec"`
+	UpdateMaxWaiters     int `mapstructure:"update_max_waiters"`
+}
+
+type Tracing struct {
+	Exporter     string `mapstructure:"exporter"`
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"`
+	ServiceName  string `mapstructure:"service_name"`
+	// SampleRatio is the share of new traces kept, 0 keeps none. Unset keeps all
+	SampleRatio *float64 `mapstructure:"sample_ratio"`
+}
+
+type Auth struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	AdminKey string `mapstructure:"admin_key"`
+}
+
+type RateLimit struct {
+	RequestsPerSec float64 `mapstructure:"requests_per_sec"`
+	Burst          int     `mapstructure:"burst"`
+}
+
+type UpstreamBudget struct {
+	PerMinute int    `mapstructure:"per_minute"`
+	PerDay    int    `mapstructure:"per_day"`
+	Store     string `mapstructure:"store"`
+}
+
 func Init() (*AppConfig, error) {
 	var cfg AppConfig
 
@@ -77,6 +201,12 @@ func Init() (*AppConfig, error) {
 		return nil, fmt.Errorf("error reading config file: %w", err)
 	}
 
+	// grpc server env vars
+	_ = viper.BindEnv("grpc_server.port", "GRPC_SERVER_PORT")
+
+	// storage env vars
+	_ = viper.BindEnv("storage.driver", "STORAGE_DRIVER")
+
 	// db server env vars
 	_ = viper.BindEnv("db_server.host", "DB_HOST")
 	_ = viper.BindEnv("db_server.port", "DB_PORT")
@@ -92,11 +222,69 @@ func Init() (*AppConfig, error) {
 	// exchange rate api env vars
 	_ = viper.BindEnv("exchange_rate_api.base_url", "EXCHANGE_RATE_API_BASE_URL")
 	_ = viper.BindEnv("exchange_rate_api.api_key", "EXCHANGE_RATE_API_KEY")
+	_ = viper.BindEnv("exchange_rate_api.providers", "EXCHANGE_RATE_API_PROVIDERS")
+	_ = viper.BindEnv("exchange_rate_api.mode", "EXCHANGE_RATE_API_MODE")
+	_ = viper.BindEnv("exchange_rate_api.provider_timeout_ms", "EXCHANGE_RATE_API_PROVIDER_TIMEOUT_MS")
+	_ = viper.BindEnv("exchange_rate_api.consensus.method", "EXCHANGE_RATE_API_CONSENSUS_METHOD")
+	_ = viper.BindEnv("exchange_rate_api.consensus.tolerance", "EXCHANGE_RATE_API_CONSENSUS_TOLERANCE")
+	_ = viper.BindEnv("exchange_rate_api.consensus.min_providers", "EXCHANGE_RATE_API_CONSENSUS_MIN_PROVIDERS")
+	_ = viper.BindEnv("exchange_rate_api.open_er_api.base_url", "OPEN_ER_API_BASE_URL")
+	_ = viper.BindEnv("exchange_rate_api.frankfurter.base_url", "FRANKFURTER_API_BASE_URL")
+	_ = viper.BindEnv("exchange_rate_api.file.dir", "FILE_PROVIDER_DIR")
+	_ = viper.BindEnv("exchange_rate_api.cassette.mode", "RATE_CASSETTE_MODE")
+	_ = viper.BindEnv("exchange_rate_api.cassette.path", "RATE_CASSETTE_PATH")
+	_ = viper.BindEnv("exchange_rate_api.retry.max_attempts", "UPSTREAM_RETRY_MAX_ATTEMPTS")
+	_ = viper.BindEnv("exchange_rate_api.retry.initial_backoff_ms", "UPSTREAM_RETRY_INITIAL_BACKOFF_MS")
+	_ = viper.BindEnv("exchange_rate_api.retry.max_backoff_ms", "UPSTREAM_RETRY_MAX_BACKOFF_MS")
+	_ = viper.BindEnv("exchange_rate_api.retry.attempt_timeout_ms", "UPSTREAM_RETRY_ATTEMPT_TIMEOUT_MS")
+	_ = viper.BindEnv("exchange_rate_api.breaker.failure_threshold", "UPSTREAM_BREAKER_FAILURE_THRESHOLD")
+	_ = viper.BindEnv("exchange_rate_api.breaker.open_sec", "UPSTREAM_BREAKER_OPEN_SEC")
 
 	// scheduler env vars
 	_ = viper.BindEnv("scheduler.update_rates_job_duration_sec", "UPDATE_RATES_JOB_DURATION_SEC")
+	_ = viper.BindEnv("scheduler.update_max_attempts", "UPDATE_MAX_ATTEMPTS")
+	_ = viper.BindEnv("scheduler.update_max_age_sec", "UPDATE_MAX_AGE_SEC")
+	_ = viper.BindEnv("scheduler.update_claim_lease_sec", "UPDATE_CLAIM_LEASE_SEC")
+	_ = viper.BindEnv("scheduler.notify_enabled", "UPDATE_NOTIFY_ENABLED")
+	_ = viper.BindEnv("scheduler.notify_debounce_ms", "UPDATE_NOTIFY_DEBOUNCE_MS")
+	_ = viper.BindEnv("scheduler.quota_backoff_sec", "UPDATE_QUOTA_BACKOFF_SEC")
+	_ = viper.BindEnv("scheduler.quota_backoff_max_sec", "UPDATE_QUOTA_BACKOFF_MAX_SEC")
 	// cache env vars
 	_ = viper.BindEnv("cache.rate_updates_max_items", "RATE_UPDATES_CACHE_MAX_ITEMS")
+	// rates env vars
+	_ = viper.BindEnv("rates.pivot_currency", "RATES_PIVOT_CURRENCY")
+	_ = viper.BindEnv("rates.currencies_refresh_sec", "RATES_CURRENCIES_REFRESH_SEC")
+	// webhooks env vars
+	_ = viper.BindEnv("webhooks.secret", "WEBHOOK_SECRET")
+	_ = viper.BindEnv("webhooks.deliver_callbacks_job_duration_sec", "WEBHOOK_DELIVERY_JOB_DURATION_SEC")
+	_ = viper.BindEnv("webhooks.batch_size", "WEBHOOK_BATCH_SIZE")
+	_ = viper.BindEnv("webhooks.max_attempts", "WEBHOOK_MAX_ATTEMPTS")
+	_ = viper.BindEnv("webhooks.initial_backoff_sec", "WEBHOOK_INITIAL_BACKOFF_SEC")
+	_ = viper.BindEnv("webhooks.max_backoff_sec", "WEBHOOK_MAX_BACKOFF_SEC")
+	_ = viper.BindEnv("webhooks.allow_private_urls", "WEBHOOK_ALLOW_PRIVATE_URLS")
+
+	// streams env vars
+	_ = viper.BindEnv("streams.subscriber_buffer_size", "STREAMS_SUBSCRIBER_BUFFER_SIZE")
+	_ = viper.BindEnv("streams.update_wait_max_sec", "STREAMS_UPDATE_WAIT_MAX_SEC")
+	_ = viper.BindEnv("streams.update_max_waiters", "STREAMS_UPDATE_MAX_WAITERS")
+
+	// tracing env vars
+	_ = viper.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+	_ = viper.BindEnv("tracing.otlp_endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
+	_ = viper.BindEnv("tracing.service_name", "OTEL_SERVICE_NAME")
+	_ = viper.BindEnv("tracing.sample_ratio", "TRACING_SAMPLE_RATIO")
+
+	// auth env vars
+	_ = viper.BindEnv("auth.enabled", "AUTH_ENABLED")
+	_ = viper.BindEnv("auth.admin_key", "AUTH_ADMIN_KEY")
+
+	// rate limit env vars
+	_ = viper.BindEnv("rate_limit.requests_per_sec", "RATE_LIMIT_RPS")
+	_ = viper.BindEnv("rate_limit.burst", "RATE_LIMIT_BURST")
+	// upstream budget env vars
+	_ = viper.BindEnv("upstream_budget.per_minute", "UPSTREAM_BUDGET_PER_MINUTE")
+	_ = viper.BindEnv("upstream_budget.per_day", "UPSTREAM_BUDGET_PER_DAY")
+	_ = viper.BindEnv("upstream_budget.store", "UPSTREAM_BUDGET_STORE")
 
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("error unmarshalling config: %w", err)
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fxrates/internal/currency"
+	"fxrates/internal/domain"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxAddCurrencyBodyBytes = 4 << 10
+
+type AddCurrencyRequest struct {
+	Code        string `json:"code" example:"SEK"`
+	Name        string `json:"name" example:"Swedish Krona"`
+	NumericCode string `json:"numeric_code" example:"752"`
+	// MinorUnits defaults to the ISO 4217 minor units of the code
+	MinorUnits *int `json:"minor_units,omitempty" example:"2"`
+}
+
+// AddCurrency godoc
+// @Summary Add currency
+// @Description Add a currency, or enable a disabled one with the given details. It's accepted in requests right away, no restart needed
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body AddCurrencyRequest true "Currency"
+// @Success 201 {object} CurrencyResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/currencies [post]
+func (h *Handler) AddCurrency(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxAddCurrencyBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	var req AddCurrencyRequest
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	c := domain.Currency{Code: req.Code, Name: req.Name, NumericCode: req.NumericCode}
+	if req.MinorUnits != nil {
+		c.MinorUnits = *req.MinorUnits
+	} else {
+		c.MinorUnits = domain.MinorUnits(strings.ToUpper(strings.TrimSpace(req.Code)))
+	}
+
+	added, err := h.service.Add(r.Context(), c)
+	if err != nil {
+		if errors.Is(err, currency.ErrInvalidCode) || errors.Is(err, currency.ErrNameRequired) ||
+			errors.Is(err, currency.ErrInvalidNumericCode) || errors.Is(err, currency.ErrInvalidMinorUnits) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, domain.ErrCurrencyExists) {
+			writeError(w, http.StatusConflict, "currency already exists")
+			return
+		}
+		msg := "ups, couldn't add currency this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "AddCurrency", "code": req.Code}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toCurrencyResponse(added))
+}
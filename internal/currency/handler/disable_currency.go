@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"errors"
+	"fxrates/internal/currency"
+	"fxrates/internal/domain"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// DisableCurrency godoc
+// @Summary Disable currency
+// @Description Stop accepting the currency in requests right away. Its rates and history are kept, adding it again enables it
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Param code path string true "Currency code" example(SEK)
+// @Success 204
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/currencies/{code} [delete]
+func (h *Handler) DisableCurrency(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	if err := h.service.Disable(r.Context(), code); err != nil {
+		if errors.Is(err, domain.ErrCurrencyNotFound) {
+			writeError(w, http.StatusNotFound, "currency not found or already disabled")
+			return
+		}
+		if errors.Is(err, currency.ErrPivotCurrency) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		msg := "ups, couldn't disable currency this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "DisableCurrency", "code": code}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
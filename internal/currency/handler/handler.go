@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fxrates/internal/domain"
+	"net/http"
+)
+
+type CurrencyService interface {
+	List(ctx context.Context) ([]domain.Currency, error)
+	Add(ctx context.Context, currency domain.Currency) (domain.Currency, error)
+	Disable(ctx context.Context, code string) error
+}
+
+type Handler struct {
+	service CurrencyService
+}
+
+func NewCurrencyHandler(currencyService CurrencyService) *Handler {
+	return &Handler{service: currencyService}
+}
+
+type CurrencyResponse struct {
+	Code        string `json:"code" example:"USD"`
+	Name        string `json:"name" example:"US Dollar"`
+	NumericCode string `json:"numeric_code" example:"840"`
+	MinorUnits  int    `json:"minor_units" example:"2"`
+	Enabled     bool   `json:"enabled" example:"true"`
+}
+
+func toCurrencyResponse(c domain.Currency) CurrencyResponse {
+	return CurrencyResponse{
+		Code:        c.Code,
+		Name:        c.Name,
+		NumericCode: c.NumericCode,
+		MinorUnits:  c.MinorUnits,
+		Enabled:     c.Enabled,
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error" example:"something bad happened"`
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errorMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Error: errorMsg,
+	})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fxrates/internal/currency"
+	"fxrates/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCurrencyService struct{ mock.Mock }
+
+func (m *MockCurrencyService) List(ctx context.Context) ([]domain.Currency, error) {
+	args := m.Called(ctx)
+	currencies, _ := args.Get(0).([]domain.Currency)
+	return currencies, args.Error(1)
+}
+
+func (m *MockCurrencyService) Add(ctx context.Context, c domain.Currency) (domain.Currency, error) {
+	args := m.Called(ctx, c)
+	added, _ := args.Get(0).(domain.Currency)
+	return added, args.Error(1)
+}
+
+func (m *MockCurrencyService) Disable(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+func requireError(t *testing.T, rr *httptest.ResponseRecorder, status int, msg string) {
+	t.Helper()
+	require.Equal(t, status, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, msg, ej.Error)
+}
+
+// --- AddCurrency ---
+
+func TestAddCurrency_DefaultsMinorUnitsToISO(t *testing.T) {
+	svc := new(MockCurrencyService)
+	h := NewCurrencyHandler(svc)
+	kwd := domain.Currency{Code: "KWD", Name: "Kuwaiti Dinar", NumericCode: "414", MinorUnits: 3, Enabled: true}
+	svc.On("Add", mock.Anything, domain.Currency{Code: "kwd", Name: "Kuwaiti Dinar", NumericCode: "414", MinorUnits: 3}).Return(kwd, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.AddCurrency(rr, httptest.NewRequest(http.MethodPost, "/api/v1/admin/currencies",
+		bytes.NewBufferString(`{"code":"kwd","name":"Kuwaiti Dinar","numeric_code":"414"}`)))
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var res CurrencyResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, CurrencyResponse{Code: "KWD", Name: "Kuwaiti Dinar", NumericCode: "414", MinorUnits: 3, Enabled: true}, res)
+	svc.AssertExpectations(t)
+}
+
+func TestAddCurrency_Errors(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		svcErr     error
+		wantStatus int
+		wantMsg    string
+	}{
+		{name: "bad body", body: `{"code":`, wantStatus: http.StatusBadRequest, wantMsg: "invalid request body"},
+		{name: "invalid", body: `{"code":"SEK","minor_units":9}`, svcErr: currency.ErrInvalidMinorUnits, wantStatus: http.StatusBadRequest, wantMsg: currency.ErrInvalidMinorUnits.Error()},
+		{name: "exists", body: `{"code":"USD"}`, svcErr: domain.ErrCurrencyExists, wantStatus: http.StatusConflict, wantMsg: "currency already exists"},
+		{name: "service error", body: `{"code":"SEK"}`, svcErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantMsg: "ups, couldn't add currency this time"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(MockCurrencyService)
+			svc.On("Add", mock.Anything, mock.Anything).Return(nil, tc.svcErr).Maybe()
+			rr := httptest.NewRecorder()
+
+			NewCurrencyHandler(svc).AddCurrency(rr, httptest.NewRequest(http.MethodPost, "/api/v1/admin/currencies", bytes.NewBufferString(tc.body)))
+
+			requireError(t, rr, tc.wantStatus, tc.wantMsg)
+		})
+	}
+}
+
+// --- ListCurrencies ---
+
+func TestListCurrencies_Success(t *testing.T) {
+	svc := new(MockCurrencyService)
+	svc.On("List", mock.Anything).Return([]domain.Currency{{Code: "EUR", Name: "Euro", NumericCode: "978", MinorUnits: 2}}, nil).Once()
+	rr := httptest.NewRecorder()
+
+	NewCurrencyHandler(svc).ListCurrencies(rr, httptest.NewRequest(http.MethodGet, "/api/v1/admin/currencies", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.JSONEq(t, `{"currencies":[{"code":"EUR","name":"Euro","numeric_code":"978","minor_units":2,"enabled":false}]}`, rr.Body.String())
+}
+
+// --- DisableCurrency ---
+
+func TestDisableCurrency(t *testing.T) {
+	cases := []struct {
+		name       string
+		svcErr     error
+		wantStatus int
+		wantMsg    string
+	}{
+		{name: "disabled", wantStatus: http.StatusNoContent},
+		{name: "not found", svcErr: domain.ErrCurrencyNotFound, wantStatus: http.StatusNotFound, wantMsg: "currency not found or already disabled"},
+		{name: "pivot", svcErr: currency.ErrPivotCurrency, wantStatus: http.StatusConflict, wantMsg: currency.ErrPivotCurrency.Error()},
+		{name: "service error", svcErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantMsg: "ups, couldn't disable currency this time"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := new(MockCurrencyService)
+			svc.On("Disable", mock.Anything, "SEK").Return(tc.svcErr).Once()
+			router := chi.NewRouter()
+			router.Delete("/api/v1/admin/currencies/{code}", NewCurrencyHandler(svc).DisableCurrency)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/admin/currencies/SEK", nil))
+
+			if tc.wantMsg == "" {
+				require.Equal(t, tc.wantStatus, rr.Code)
+			} else {
+				requireError(t, rr, tc.wantStatus, tc.wantMsg)
+			}
+			svc.AssertExpectations(t)
+		})
+	}
+}
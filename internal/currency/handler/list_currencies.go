@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ListCurrenciesResponse struct {
+	Currencies []CurrencyResponse `json:"currencies"`
+}
+
+// ListCurrencies godoc
+// @Summary List currencies
+// @Description List all currencies with their ISO 4217 details, disabled ones included
+// @Tags Admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} ListCurrenciesResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/currencies [get]
+func (h *Handler) ListCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := h.service.List(r.Context())
+	if err != nil {
+		msg := "ups, couldn't list currencies this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "ListCurrencies"}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+
+	res := ListCurrenciesResponse{Currencies: make([]CurrencyResponse, 0, len(currencies))}
+	for _, c := range currencies {
+		res.Currencies = append(res.Currencies, toCurrencyResponse(c))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
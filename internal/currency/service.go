@@ -0,0 +1,127 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrInvalidCode        = errors.New("code must be 3 letters")
+	ErrNameRequired       = errors.New("name is required")
+	ErrInvalidNumericCode = errors.New("numeric code must be 3 digits")
+	ErrInvalidMinorUnits  = errors.New("minor units must be between 0 and 4")
+	ErrPivotCurrency      = errors.New("pivot currency can't be disabled")
+)
+
+const (
+	maxNameLength = 128
+	maxMinorUnits = 4
+)
+
+var (
+	codePattern        = regexp.MustCompile(`^[A-Z]{3}$`)
+	numericCodePattern = regexp.MustCompile(`^[0-9]{3}$`)
+)
+
+// Set is the set of supported currencies requests are validated against
+type Set interface {
+	// Replace atomically swaps the set for the enabled currencies of the list
+	Replace(currencies []domain.Currency)
+}
+
+// Service manages currencies and keeps the supported set in sync with the DB
+type Service struct {
+	repo          adapters.CurrencyRepository
+	set           Set
+	pivotCurrency string
+}
+
+func (s *Service) List(ctx context.Context) ([]domain.Currency, error) {
+	return s.repo.List(ctx)
+}
+
+// Add adds a currency or enables a disabled one, it's accepted in requests right after
+func (s *Service) Add(ctx context.Context, currency domain.Currency) (domain.Currency, error) {
+	currency.Code = strings.ToUpper(strings.TrimSpace(currency.Code))
+	currency.Name = strings.TrimSpace(currency.Name)
+	currency.NumericCode = strings.TrimSpace(currency.NumericCode)
+	if !codePattern.MatchString(currency.Code) {
+		return domain.Currency{}, ErrInvalidCode
+	}
+	if currency.Name == "" || len(currency.Name) > maxNameLength {
+		return domain.Currency{}, ErrNameRequired
+	}
+	if !numericCodePattern.MatchString(currency.NumericCode) {
+		return domain.Currency{}, ErrInvalidNumericCode
+	}
+	if currency.MinorUnits < 0 || currency.MinorUnits > maxMinorUnits {
+		return domain.Currency{}, ErrInvalidMinorUnits
+	}
+
+	added, err := s.repo.Add(ctx, currency)
+	if err != nil {
+		return domain.Currency{}, err
+	}
+	s.refreshAfterChange(ctx)
+	return added, nil
+}
+
+// Disable stops accepting the currency in requests. Its pairs, rates and history are kept
+func (s *Service) Disable(ctx context.Context, code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == s.pivotCurrency {
+		return ErrPivotCurrency
+	}
+	if err := s.repo.Disable(ctx, code); err != nil {
+		return err
+	}
+	s.refreshAfterChange(ctx)
+	return nil
+}
+
+// Refresh reloads currencies from the DB into the supported set
+func (s *Service) Refresh(ctx context.Context) error {
+	currencies, err := s.repo.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to refresh currencies: %w", err)
+	}
+	s.set.Replace(currencies)
+	return nil
+}
+
+// RefreshEvery refreshes the supported set periodically until ctx is done,
+// so changes made through other instances are picked up
+func (s *Service) RefreshEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				logrus.WithError(err).Warn("periodic currencies refresh failed")
+			}
+		}
+	}
+}
+
+// refreshAfterChange doesn't fail the change itself: it's stored already and the periodic refresh catches up
+func (s *Service) refreshAfterChange(ctx context.Context) {
+	if err := s.Refresh(ctx); err != nil {
+		logrus.WithError(err).Warn("currencies weren't refreshed after a change")
+	}
+}
+
+// NewService creates currency service, the pivot currency can't be disabled as triangulation relies on it
+func NewService(repo adapters.CurrencyRepository, set Set, pivotCurrency string) *Service {
+	return &Service{repo: repo, set: set, pivotCurrency: pivotCurrency}
+}
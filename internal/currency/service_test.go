@@ -0,0 +1,116 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"fxrates/internal/domain"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCurrencyRepository struct{ mock.Mock }
+
+func (m *MockCurrencyRepository) List(ctx context.Context) ([]domain.Currency, error) {
+	args := m.Called(ctx)
+	currencies, _ := args.Get(0).([]domain.Currency)
+	return currencies, args.Error(1)
+}
+
+func (m *MockCurrencyRepository) Add(ctx context.Context, currency domain.Currency) (domain.Currency, error) {
+	args := m.Called(ctx, currency)
+	added, _ := args.Get(0).(domain.Currency)
+	return added, args.Error(1)
+}
+
+func (m *MockCurrencyRepository) Disable(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+type MockSet struct{ mock.Mock }
+
+func (m *MockSet) Replace(currencies []domain.Currency) {
+	m.Called(currencies)
+}
+
+var (
+	usd = domain.Currency{Code: "USD", Name: "US Dollar", NumericCode: "840", MinorUnits: 2, Enabled: true}
+	sek = domain.Currency{Code: "SEK", Name: "Swedish Krona", NumericCode: "752", MinorUnits: 2, Enabled: true}
+)
+
+func TestService_Add_NormalizesStoresAndRefreshes(t *testing.T) {
+	repo := new(MockCurrencyRepository)
+	set := new(MockSet)
+	svc := NewService(repo, set, "USD")
+
+	repo.On("Add", mock.Anything, domain.Currency{Code: "SEK", Name: "Swedish Krona", NumericCode: "752", MinorUnits: 2}).Return(sek, nil).Once()
+	repo.On("List", mock.Anything).Return([]domain.Currency{sek, usd}, nil).Once()
+	set.On("Replace", []domain.Currency{sek, usd}).Once()
+
+	added, err := svc.Add(context.Background(), domain.Currency{Code: " sek ", Name: " Swedish Krona", NumericCode: "752", MinorUnits: 2})
+
+	require.NoError(t, err)
+	require.Equal(t, sek, added)
+	repo.AssertExpectations(t)
+	set.AssertExpectations(t)
+}
+
+func TestService_Add_Invalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		currency domain.Currency
+		wantErr  error
+	}{
+		{name: "code", currency: domain.Currency{Code: "SE1", Name: "x", NumericCode: "752"}, wantErr: ErrInvalidCode},
+		{name: "name", currency: domain.Currency{Code: "SEK", Name: " ", NumericCode: "752"}, wantErr: ErrNameRequired},
+		{name: "numeric code", currency: domain.Currency{Code: "SEK", Name: "x", NumericCode: "75"}, wantErr: ErrInvalidNumericCode},
+		{name: "minor units", currency: domain.Currency{Code: "SEK", Name: "x", NumericCode: "752", MinorUnits: 5}, wantErr: ErrInvalidMinorUnits},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(MockCurrencyRepository)
+			svc := NewService(repo, new(MockSet), "")
+
+			_, err := svc.Add(context.Background(), tc.currency)
+
+			require.ErrorIs(t, err, tc.wantErr)
+			repo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_Add_RefreshFailure_StillAdded(t *testing.T) {
+	repo := new(MockCurrencyRepository)
+	set := new(MockSet)
+	svc := NewService(repo, set, "")
+
+	repo.On("Add", mock.Anything, sek).Return(sek, nil).Once()
+	repo.On("List", mock.Anything).Return(nil, errors.New("db down")).Once()
+
+	added, err := svc.Add(context.Background(), sek)
+
+	require.NoError(t, err)
+	require.Equal(t, sek, added)
+	set.AssertNotCalled(t, "Replace", mock.Anything)
+}
+
+func TestService_Disable(t *testing.T) {
+	repo := new(MockCurrencyRepository)
+	set := new(MockSet)
+	svc := NewService(repo, set, "USD")
+
+	repo.On("Disable", mock.Anything, "SEK").Return(nil).Once()
+	repo.On("List", mock.Anything).Return([]domain.Currency{usd}, nil).Once()
+	set.On("Replace", []domain.Currency{usd}).Once()
+
+	require.NoError(t, svc.Disable(context.Background(), "sek"))
+	require.ErrorIs(t, svc.Disable(context.Background(), "USD"), ErrPivotCurrency)
+
+	repo.On("Disable", mock.Anything, "XXX").Return(domain.ErrCurrencyNotFound).Once()
+	require.ErrorIs(t, svc.Disable(context.Background(), "XXX"), domain.ErrCurrencyNotFound)
+	repo.AssertExpectations(t)
+	set.AssertExpectations(t)
+}
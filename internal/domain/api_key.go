@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	ScopeRatesRead       = "rates:read"
+	ScopeRatesSchedule   = "rates:schedule"
+	ScopeKeysAdmin       = "keys:admin"
+	ScopeCurrenciesAdmin = "currencies:admin"
+)
+
+// Scopes lists all known scopes
+var Scopes = []string{ScopeRatesRead, ScopeRatesSchedule, ScopeKeysAdmin, ScopeCurrenciesAdmin}
+
+// APIKey is an issued key without its secret part, only the hash of the key is stored
+type APIKey struct {
+	ID        int64
+	Prefix    string
+	Owner     string
+	Scopes    []string
+	ExpiresAt *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Active tells whether the key is neither revoked nor expired at the given time
+func (k APIKey) Active(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || at.Before(*k.ExpiresAt))
+}
@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RateUpdateCallback is a callback of a finished update claimed for delivery along with its outcome:
+// the applied rate, or the reason a failed or expired update was closed without one
+type RateUpdateCallback struct {
+	ID        int64
+	UpdateID  uuid.UUID
+	URL       string
+	Attempts  int
+	Base      string
+	Quote     string
+	Status    RateUpdateStatus
+	Value     decimal.Decimal
+	Reason    string
+	UpdatedAt time.Time
+}
@@ -0,0 +1,29 @@
+package domain
+
+// defaultMinorUnits is the number of decimal places used by most ISO 4217 currencies
+const defaultMinorUnits = 2
+
+// minorUnitsExceptions lists ISO 4217 currencies whose minor units differ from defaultMinorUnits
+var minorUnitsExceptions = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// MinorUnits returns the number of decimal places amounts in the currency are rounded to
+func MinorUnits(code string) int {
+	if units, ok := minorUnitsExceptions[code]; ok {
+		return units
+	}
+	return defaultMinorUnits
+}
+
+// Currency is an ISO 4217 currency, only enabled ones are accepted in requests
+type Currency struct {
+	Code        string
+	Name        string
+	NumericCode string
+	MinorUnits  int
+	Enabled     bool
+}
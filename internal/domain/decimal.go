@@ -0,0 +1,11 @@
+package domain
+
+import "github.com/shopspring/decimal"
+
+// RateScale is the number of fractional digits rate values are stored (numeric(16,8)) and served with
+const RateScale = 8
+
+// InverseRate returns 1/v rounded half away from zero to RateScale digits
+func InverseRate(v decimal.Decimal) decimal.Decimal {
+	return decimal.NewFromInt(1).DivRound(v, RateScale)
+}
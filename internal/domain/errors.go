@@ -3,5 +3,20 @@ package domain
 import "errors"
 
 var (
-	ErrRateNotFound = errors.New("rate not found")
+	ErrRateNotFound   = errors.New("rate not found")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrCurrencyNotFound is returned for unknown currencies as well as for already disabled ones on disabling
+	ErrCurrencyNotFound = errors.New("currency not found")
+	ErrCurrencyExists   = errors.New("currency already exists")
+	// ErrClaimLost is returned when claimed updates were reclaimed by another run after the lease expired
+	ErrClaimLost = errors.New("claim of pending updates was lost")
+	// ErrUpstreamUnavailable marks provider failures that may pass on a later attempt: network errors, timeouts,
+	// 5xx and 429 answers, exhausted quotas
+	ErrUpstreamUnavailable = errors.New("upstream temporarily unavailable")
+	// ErrUpstreamAuth is returned when the provider rejected the API key or the account behind it
+	ErrUpstreamAuth = errors.New("upstream rejected the credentials")
+	// ErrUpstreamQuota is returned when the provider's request quota is used up, it's ErrUpstreamUnavailable too
+	ErrUpstreamQuota = errors.New("upstream quota is exhausted")
+	// ErrUnsupportedCurrency is returned when the provider doesn't support the requested currency
+	ErrUnsupportedCurrency = errors.New("currency isn't supported by the upstream")
 )
@@ -2,14 +2,20 @@ package domain
 
 import (
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Rate struct {
 	PairID    int64
 	Base      string
 	Quote     string
-	Value     float64
+	Value     decimal.Decimal
 	UpdatedAt time.Time
+	Source    string
+	Quotes    []ProviderQuote
+	Attempts  int
+	Reason    string
 }
 
 type RatePair struct {
@@ -23,3 +29,23 @@ func (p RatePair) Reversed() RatePair {
 		Quote: p.Base,
 	}
 }
+
+type RateHistoryPoint struct {
+	Value      decimal.Decimal
+	RecordedAt time.Time
+}
+
+// ExchangeRates is a conversion table of a base currency served by a rate provider
+type ExchangeRates struct {
+	Provider string
+	Rates    map[string]decimal.Decimal
+	// Quotes holds per-provider values of each quote when rates were agreed by several providers
+	Quotes map[string][]ProviderQuote
+}
+
+// ProviderQuote is a value a single provider returned for a quote, accepted when it was close enough to the others
+type ProviderQuote struct {
+	Provider string          `json:"provider"`
+	Value    decimal.Decimal `json:"value"`
+	Accepted bool            `json:"accepted"`
+}
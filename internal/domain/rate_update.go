@@ -1,23 +1,67 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
 
 type RateUpdateStatus string
 
 const (
 	StatusPending RateUpdateStatus = "pending"
 	StatusApplied RateUpdateStatus = "applied"
+	// StatusFailed is set when the rate wasn't fetched within the max number of attempts
+	StatusFailed RateUpdateStatus = "failed"
+	// StatusExpired is set when the rate wasn't fetched within the max age of the update
+	StatusExpired RateUpdateStatus = "expired"
 )
 
 type PendingRateUpdate struct {
-	UpdateID uuid.UUID `json:"update_id"`
-	PairID   int64     `json:"pair_id"`
-	Base     string    `json:"base"`
-	Quote    string    `json:"quote"`
+	UpdateID  uuid.UUID `json:"update_id"`
+	PairID    int64     `json:"pair_id"`
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type AppliedRateUpdate struct {
-	UpdateID uuid.UUID `json:"update_id"`
-	PairID   int64     `json:"pair_id"`
-	Value    float64   `json:"value"`
+	UpdateID uuid.UUID       `json:"update_id"`
+	PairID   int64           `json:"pair_id"`
+	Value    decimal.Decimal `json:"value"`
+	Source   string          `json:"source"`
+	Quotes   []ProviderQuote `json:"quotes,omitempty"`
+}
+
+// RateChange is a value applied by the update job, published to rate streams
+type RateChange struct {
+	UpdateID  uuid.UUID
+	Base      string
+	Quote     string
+	Value     decimal.Decimal
+	Source    string
+	UpdatedAt time.Time
+}
+
+// ClosedRateUpdate is a pending update closed without a value, Status is either StatusFailed or StatusExpired
+type ClosedRateUpdate struct {
+	UpdateID uuid.UUID        `json:"update_id"`
+	Status   RateUpdateStatus `json:"status"`
+	Reason   string           `json:"reason"`
+}
+
+// ClaimLostError lists the updates which were reclaimed by another run, the rest of the batch was applied
+type ClaimLostError struct {
+	UpdateIDs []uuid.UUID
+}
+
+func (e *ClaimLostError) Error() string {
+	return fmt.Sprintf("%s: %d updates", ErrClaimLost, len(e.UpdateIDs))
+}
+
+func (e *ClaimLostError) Unwrap() error {
+	return ErrClaimLost
 }
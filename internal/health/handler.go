@@ -0,0 +1,64 @@
+// Package health reports the state of upstream rate providers
+package health
+
+import (
+	"encoding/json"
+	"fxrates/internal/adapters/resilience"
+	"net/http"
+	"time"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+type Handler struct {
+	breakers []*resilience.Breaker
+}
+
+type UpstreamsResponse struct {
+	// Status is ok with every breaker closed, unavailable with every breaker open and degraded otherwise
+	Status    string             `json:"status" example:"degraded"`
+	Upstreams []UpstreamResponse `json:"upstreams"`
+}
+
+type UpstreamResponse struct {
+	Provider            string     `json:"provider" example:"exchangerate_api"`
+	State               string     `json:"state" example:"open"`
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"5"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	RetryAt             *time.Time `json:"retry_at,omitempty"`
+}
+
+// Upstreams serves breaker states of the providers, answering 503 when none of them can be called
+func (h *Handler) Upstreams(w http.ResponseWriter, _ *http.Request) {
+	res := UpstreamsResponse{Status: StatusOK, Upstreams: make([]UpstreamResponse, 0, len(h.breakers))}
+	open := 0
+	for _, b := range h.breakers {
+		s := b.Status()
+		u := UpstreamResponse{Provider: s.Provider, State: s.State, ConsecutiveFailures: s.ConsecutiveFailures}
+		if s.State != resilience.StateClosed {
+			res.Status = StatusDegraded
+			u.OpenedAt, u.RetryAt = &s.OpenedAt, &s.RetryAt
+		}
+		if s.State == resilience.StateOpen {
+			open++
+		}
+		res.Upstreams = append(res.Upstreams, u)
+	}
+
+	status := http.StatusOK
+	if open > 0 && open == len(h.breakers) {
+		res.Status, status = StatusUnavailable, http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// NewHealthHandler reports the given breakers, with none the upstreams are always ok
+func NewHealthHandler(breakers ...*resilience.Breaker) *Handler {
+	return &Handler{breakers: breakers}
+}
@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fxrates/internal/adapters/resilience"
+	"fxrates/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type downClient struct{}
+
+func (downClient) GetExchangeRates(context.Context, string) (domain.ExchangeRates, error) {
+	return domain.ExchangeRates{}, fmt.Errorf("unexpected status code 503: %w", domain.ErrUpstreamUnavailable)
+}
+
+// openBreaker returns a breaker opened by a failed call
+func openBreaker(t *testing.T, provider string) *resilience.Breaker {
+	b := resilience.NewBreaker(provider, resilience.BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute})
+	_, err := resilience.NewRateClient(provider, downClient{}, resilience.RetryPolicy{}, b).GetExchangeRates(context.Background(), "USD")
+	require.Error(t, err)
+	return b
+}
+
+func TestUpstreams(t *testing.T) {
+	closed := func() *resilience.Breaker {
+		return resilience.NewBreaker("frankfurter", resilience.BreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute})
+	}
+	tests := []struct {
+		name       string
+		breakers   []*resilience.Breaker
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "no breakers", wantCode: http.StatusOK, wantStatus: StatusOK},
+		{name: "all closed", breakers: []*resilience.Breaker{closed()}, wantCode: http.StatusOK, wantStatus: StatusOK},
+		{name: "one open", breakers: []*resilience.Breaker{openBreaker(t, "exchangerate_api"), closed()}, wantCode: http.StatusOK, wantStatus: StatusDegraded},
+		{name: "all open", breakers: []*resilience.Breaker{openBreaker(t, "exchangerate_api")}, wantCode: http.StatusServiceUnavailable, wantStatus: StatusUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewHealthHandler(tt.breakers...).Upstreams(rec, httptest.NewRequest(http.MethodGet, "/healthz/upstreams", nil))
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			var res UpstreamsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			require.Equal(t, tt.wantStatus, res.Status)
+			require.Len(t, res.Upstreams, len(tt.breakers))
+			for _, u := range res.Upstreams {
+				require.Equal(t, u.State != resilience.StateClosed, u.RetryAt != nil, u.Provider)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "fxrates"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request duration by route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	UpdateJobDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "update_job_duration_seconds",
+		Help:      "Duration of UpdatePendingRates runs.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	})
+
+	UpdateJobPending = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "update_job_pending_updates",
+		Help:      "Pending rate updates claimed by the last UpdatePendingRates run.",
+	})
+
+	UpdatesApplied = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_applied_total",
+		Help:      "Rate updates applied with a fetched value.",
+	})
+
+	UpdatesSkipped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_skipped_total",
+		Help:      "Rate updates left without a value by an UpdatePendingRates run.",
+	})
+
+	ProviderRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_request_duration_seconds",
+		Help:      "Latency of upstream rate requests by base currency.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"base"})
+
+	ProviderErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_errors_total",
+		Help:      "Failed upstream rate requests by base currency.",
+	}, []string{"base"})
+
+	UpstreamRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_retries_total",
+		Help:      "Retried upstream rate requests by provider.",
+	}, []string{"provider"})
+
+	UpstreamBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_breaker_open",
+		Help:      "Whether the circuit breaker of the provider is open (1) and its calls are short-circuited.",
+	}, []string{"provider"})
+
+	UpstreamBudgetDenied = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_budget_denied_total",
+		Help:      "Base fetches postponed as the upstream budget was exhausted.",
+	})
+
+	RateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_rate_limited_total",
+		Help:      "API requests rejected with 429 (RESOURCE_EXHAUSTED over gRPC) by the per-client rate limit.",
+	})
+
+	UpdateWaitsRejected = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "update_waits_rejected_total",
+		Help:      "Long-polling update requests answered at once as too many were waiting already.",
+	})
+
+	cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Rate update cache lookups by result (hit or miss).",
+	}, []string{"result"})
+)
+
+// Handler serves metrics of the default registry in Prometheus text format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Middleware counts requests and observes their duration labelled by chi route pattern rather than raw path,
+// so path parameters like update IDs don't blow up cardinality
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK // nothing was written explicitly
+		}
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+		httpRequestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+// ObserveCacheLookup records a hit or a miss of the rate update cache
+func ObserveCacheLookup(hit bool) {
+	if hit {
+		cacheRequests.WithLabelValues("hit").Inc()
+		return
+	}
+	cacheRequests.WithLabelValues("miss").Inc()
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_LabelsByRoutePattern(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/api/v1/rates/updates/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	router.Get("/api/v1/rates/supported-currencies", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	})
+
+	found := httpRequests.WithLabelValues("/api/v1/rates/updates/{id}", http.MethodGet, "202")
+	implicitOK := httpRequests.WithLabelValues("/api/v1/rates/supported-currencies", http.MethodGet, "200")
+	unmatched := httpRequests.WithLabelValues("unmatched", http.MethodGet, "404")
+	foundBefore, okBefore, unmatchedBefore := testutil.ToFloat64(found), testutil.ToFloat64(implicitOK), testutil.ToFloat64(unmatched)
+
+	for _, path := range []string{"/api/v1/rates/updates/a", "/api/v1/rates/updates/b", "/api/v1/rates/supported-currencies", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	require.Equal(t, foundBefore+2, testutil.ToFloat64(found))
+	require.Equal(t, okBefore+1, testutil.ToFloat64(implicitOK))
+	require.Equal(t, unmatchedBefore+1, testutil.ToFloat64(unmatched))
+}
+
+func TestHandler_ExposesMetrics(t *testing.T) {
+	ObserveCacheLookup(true)
+
+	rr := httptest.NewRecorder()
+	Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.True(t, strings.Contains(rr.Body.String(), `fxrates_cache_requests_total{result="hit"}`))
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+create table fx_rate_history (
+    id          bigserial primary key,
+    pair_id     bigint not null references fx_pairs(id) on delete cascade,
+    value       numeric(16,8) not null,
+    recorded_at timestamptz not null default now()
+);
+
+create index fx_rate_history_pair_recorded_idx
+    on fx_rate_history(pair_id, recorded_at);
@@ -0,0 +1,3 @@
+-- +goose Up
+-- provider which served the applied value
+alter table fx_rate_updates add column source text;
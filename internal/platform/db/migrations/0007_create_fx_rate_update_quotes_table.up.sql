@@ -0,0 +1,12 @@
+-- +goose Up
+-- per-provider values behind a consensus rate, kept for audit
+create table fx_rate_update_quotes (
+    id        bigserial primary key,
+    update_id uuid not null references fx_rate_updates(update_id) on delete cascade,
+    provider  text not null,
+    value     numeric(16,8) not null,
+    accepted  boolean not null
+);
+
+create index fx_rate_update_quotes_update_idx
+    on fx_rate_update_quotes(update_id);
@@ -0,0 +1,9 @@
+-- +goose Up
+-- failed fetch attempts of a pending update and the reason it was closed without a value
+alter table fx_rate_updates
+    add column attempts integer not null default 0,
+    add column reason   text;
+
+alter table fx_rate_updates
+    add constraint status_is_known check (status in ('pending', 'applied', 'failed', 'expired')),
+    add constraint must_have_reason_when_status_failed_or_expired check ((status in ('failed', 'expired')) = (reason is not null));
@@ -0,0 +1,19 @@
+-- +goose Up
+-- outbox of callbacks delivered once their update is applied
+create table fx_rate_update_callbacks (
+    id              bigserial primary key,
+    update_id       uuid        not null references fx_rate_updates(update_id) on delete cascade,
+    url             text        not null,
+    status          text        not null default 'pending',
+    attempts        integer     not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    last_error      text,
+    created_at      timestamptz not null default now(),
+    updated_at      timestamptz not null default now(),
+    unique (update_id, url),
+    constraint status_is_known check (status in ('pending', 'delivered', 'failed'))
+);
+
+create index fx_rate_update_callbacks_due_idx
+    on fx_rate_update_callbacks(next_attempt_at)
+    where status = 'pending';
@@ -0,0 +1,13 @@
+-- +goose Up
+-- keys are stored as SHA-256 hashes, the prefix only helps owners recognize their keys
+create table api_keys (
+    id         bigserial primary key,
+    key_hash   text        not null unique,
+    key_prefix text        not null,
+    owner      text        not null,
+    scopes     text[]      not null,
+    expires_at timestamptz,
+    revoked_at timestamptz,
+    created_at timestamptz not null default now(),
+    constraint scopes_not_empty check (cardinality(scopes) > 0)
+);
@@ -0,0 +1,9 @@
+-- +goose Up
+-- upstream fetches counted in fixed UTC windows, shared by all instances
+create table upstream_budget (
+    window_kind  text        not null,
+    window_start timestamptz not null,
+    used         integer     not null default 0,
+    primary key (window_kind, window_start),
+    constraint window_kind_is_known check (window_kind in ('minute', 'day'))
+);
@@ -0,0 +1,23 @@
+-- +goose Up
+-- defaults keep plain "insert into currencies(code)" working, details are filled in through the admin API
+alter table currencies
+    add column name         text     not null default '',
+    add column numeric_code text     not null default '000',
+    add column minor_units  smallint not null default 2,
+    add column enabled      boolean  not null default true,
+    add constraint currencies_numeric_code_check check (numeric_code ~ '^[0-9]{3}$'),
+    add constraint currencies_minor_units_check check (minor_units between 0 and 4);
+
+update currencies c
+set name = v.name, numeric_code = v.numeric_code, minor_units = v.minor_units
+from (values
+    ('USD', 'US Dollar', '840', 2),
+    ('EUR', 'Euro', '978', 2),
+    ('MXN', 'Mexican Peso', '484', 2),
+    ('GBP', 'Pound Sterling', '826', 2),
+    ('JPY', 'Yen', '392', 0),
+    ('CHF', 'Swiss Franc', '756', 2),
+    ('AUD', 'Australian Dollar', '036', 2),
+    ('CAD', 'Canadian Dollar', '124', 2)
+) as v(code, name, numeric_code, minor_units)
+where c.code = v.code;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- pending updates are claimed by a job run until the lease expires, so replicas don't process the same updates
+alter table fx_rate_updates
+    add column claimed_by    text,
+    add column claimed_until timestamptz;
+
+create index fx_rate_updates_pending_claim_idx
+    on fx_rate_updates(claimed_until)
+    where status = 'pending';
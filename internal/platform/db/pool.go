@@ -15,6 +15,7 @@ func CreatePoolAndPing(ctx context.Context, cfg config.DbServer) (*pgxpool.Pool,
 	if cfg.MaxConns > 0 {
 		poolCfg.MaxConns = cfg.MaxConns
 	}
+	poolCfg.ConnConfig.Tracer = newQueryTracer()
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationName(t *testing.T) {
+	require.Equal(t, "select", operationName("SELECT code from currencies"))
+	require.Equal(t, "with", operationName("\n\t\twith due as (select 1) select * from due"))
+	require.Equal(t, "unknown", operationName("  "))
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"context"
+	"fxrates/internal/config"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// Start serves gRPC and stops it gracefully on ctx cancellation, like the HTTP server does.
+// onShutdown funcs are called when shutdown begins to end streams, calls still running after the timeout are cut
+func Start(ctx context.Context, cfg config.GRPCServer, server *grpc.Server, onShutdown ...func()) error {
+	listener, listenErr := net.Listen("tcp", ":"+cfg.Port)
+	if listenErr != nil {
+		return listenErr
+	}
+	logrus.Infof("✅ gRPC server is listening on %s", cfg.Port)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		for _, f := range onShutdown {
+			f()
+		}
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(10 * time.Second):
+			server.Stop()
+		}
+		return nil
+	case serveErr := <-errCh:
+		return serveErr
+	}
+}
@@ -13,7 +13,8 @@ import (
 )
 
 // Start runs HTTP server and shuts it down gracefully on ctx cancellation.
-func Start(ctx context.Context, cfg config.HTTPServer, router *chi.Mux) error {
+// onShutdown funcs are called when shutdown begins to end long-lived requests (streams), which would hold it otherwise
+func Start(ctx context.Context, cfg config.HTTPServer, router *chi.Mux, onShutdown ...func()) error {
 	listener, listenErr := net.Listen("tcp", ":"+cfg.Port)
 	if listenErr != nil {
 		return listenErr
@@ -22,6 +23,9 @@ func Start(ctx context.Context, cfg config.HTTPServer, router *chi.Mux) error {
 	logrus.Info("😎 All components ready! You are good to go 🚀")
 
 	server := &http.Server{Handler: router}
+	for _, f := range onShutdown {
+		server.RegisterOnShutdown(f)
+	}
 	errCh := make(chan error, 1)
 	go func() {
 		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
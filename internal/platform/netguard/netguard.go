@@ -0,0 +1,80 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrNotPublic = errors.New("address isn't public")
+
+// cgnat is the shared address space of carrier-grade NAT (RFC 6598), not routable on the internet either
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublic reports whether ip is routable on the internet, rejecting loopback, private (RFC 1918, ULA),
+// link-local (cloud metadata endpoints included), multicast and unspecified addresses
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!cgnat.Contains(ip)
+}
+
+// CheckHost fails when host is a non-public IP or resolves to one. A host which can't be resolved passes,
+// connections are checked again once dialed
+func CheckHost(ctx context.Context, host string) error {
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if !IsPublic(ip) {
+			return fmt.Errorf("%s: %w", host, ErrNotPublic)
+		}
+		return nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil
+	}
+	for _, ip := range ips {
+		if !IsPublic(ip) {
+			return fmt.Errorf("%s resolves to %s: %w", host, ip, ErrNotPublic)
+		}
+	}
+	return nil
+}
+
+// Dialer refuses connections to non-public addresses. The check runs on the address actually dialed,
+// after resolution, so DNS rebinding can't get around it
+func Dialer(timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("failed to parse dialed address %q: %w", address, err)
+			}
+			if !IsPublic(addrPort.Addr()) {
+				return fmt.Errorf("%s: %w", addrPort.Addr(), ErrNotPublic)
+			}
+			return nil
+		},
+	}
+}
+
+// Transport is a clone of the default transport dialing public addresses only. Proxies aren't used,
+// as a proxy would make the dialed address its own
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = Dialer(30 * time.Second).DialContext
+	return transport
+}
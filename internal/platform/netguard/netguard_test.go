@@ -0,0 +1,45 @@
+package netguard
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPublic(t *testing.T) {
+	public := []string{"8.8.8.8", "1.1.1.1", "2606:4700:4700::1111"}
+	for _, raw := range public {
+		require.True(t, IsPublic(netip.MustParseAddr(raw)), raw)
+	}
+
+	nonPublic := []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "fe80::1",
+		"fd00::1", "0.0.0.0", "::", "224.0.0.1", "100.64.0.1", "::ffff:127.0.0.1", "::ffff:169.254.169.254",
+	}
+	for _, raw := range nonPublic {
+		require.False(t, IsPublic(netip.MustParseAddr(raw)), raw)
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	require.NoError(t, CheckHost(ctx, "8.8.8.8"))
+	require.ErrorIs(t, CheckHost(ctx, "169.254.169.254"), ErrNotPublic)
+	require.ErrorIs(t, CheckHost(ctx, "::1"), ErrNotPublic)
+	require.ErrorIs(t, CheckHost(ctx, "localhost"), ErrNotPublic)
+}
+
+func TestTransport_RefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("request shouldn't reach the server")
+	}))
+	defer srv.Close()
+
+	_, err := (&http.Client{Transport: Transport()}).Get(srv.URL)
+
+	require.ErrorIs(t, err, ErrNotPublic)
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fxrates/internal/config"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+func TestMiddleware_NamesSpanAfterRoutePattern(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	router := chi.NewRouter()
+	router.Use(Middleware)
+	router.Get("/api/v1/rates/updates/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/rates/updates/77b5d9f5", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "GET /api/v1/rates/updates/{id}", spans[0].Name())
+	require.Contains(t, spans[0].Attributes(), semconv.HTTPRoute("/api/v1/rates/updates/{id}"))
+	require.Equal(t, "GET", spans[1].Name())
+}
+
+func TestSetup_Exporters(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.Tracing{Exporter: ExporterNone})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup(context.Background(), config.Tracing{Exporter: "jaeger"})
+	require.ErrorContains(t, err, `unknown tracing exporter "jaeger"`)
+}
+
+func TestSampler_ZeroKeepsNoNewTraces(t *testing.T) {
+	zero, half := 0.0, 0.5
+	require.Contains(t, sampler(nil).Description(), "AlwaysOnSampler")
+	require.Contains(t, sampler(&zero).Description(), "AlwaysOffSampler")
+	require.Contains(t, sampler(&half).Description(), "TraceIDRatioBased{0.5}")
+}
@@ -0,0 +1,161 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"fxrates/internal/adapters"
+	"fxrates/internal/domain"
+	"fxrates/internal/platform/netguard"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const maxCallbackURLLength = 2048
+
+var (
+	ErrCallbackURLInvalid   = errors.New("callback_url must be an absolute http or https URL")
+	ErrCallbackURLNotPublic = errors.New("callback_url must point to a public address")
+	ErrCallbacksDisabled    = errors.New("callbacks are disabled")
+)
+
+// CallbackOptions tunes DeliverCallbacks behaviour
+type CallbackOptions struct {
+	// BatchSize is the max number of callbacks delivered per run
+	BatchSize int
+	// MaxAttempts, when positive, fails a callback after that many unsuccessful deliveries
+	MaxAttempts int
+	// InitialBackoff is the delay after the first unsuccessful delivery, doubled after each next one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// callbackPayload is the JSON body posted to callback URLs. Value is set for applied updates, Reason for failed and expired ones
+type callbackPayload struct {
+	UpdateID  string    `json:"update_id"`
+	Base      string    `json:"base"`
+	Quote     string    `json:"quote"`
+	Status    string    `json:"status"`
+	Value     string    `json:"value,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeliverCallbacks posts finished updates to their callback URLs, applied ones with the value and closed ones with the reason. Unsuccessful deliveries are retried with exponential backoff,
+// so the same callback may be delivered more than once and receivers should deduplicate by update_id
+func DeliverCallbacks(ctx context.Context, execID string, callbackRepo adapters.RateUpdateCallbackRepository, sender adapters.CallbackSender, opts CallbackOptions) error {
+	// STEP 1: claiming due callbacks for as long as the whole batch may take
+	lease := perRequestTimeout * time.Duration(opts.BatchSize/numWorkers+1)
+	due, err := callbackRepo.ClaimDue(ctx, opts.BatchSize, lease)
+	if err != nil {
+		return fmt.Errorf("failed to claim callbacks: %w", err)
+	}
+	if len(due) == 0 {
+		logrus.Debugf("No callbacks to deliver this time; execID: %s", execID)
+		return nil
+	}
+
+	// STEP 2: delivering them in parallel using workers pool
+	workQueue := make(chan domain.RateUpdateCallback, len(due))
+	for _, c := range due {
+		workQueue <- c
+	}
+	close(workQueue)
+
+	var delivered atomic.Int64
+	var mu sync.Mutex
+	var errs []error
+
+	var wg sync.WaitGroup
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range workQueue {
+				ok, deliverErr := deliverCallback(ctx, c, callbackRepo, sender, opts)
+				if ok {
+					delivered.Add(1)
+				}
+				if deliverErr != nil {
+					mu.Lock()
+					errs = append(errs, deliverErr)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	logrus.Infof("%d of %d callbacks were delivered; execID: %s", delivered.Load(), len(due), execID)
+	return errors.Join(errs...)
+}
+
+// deliverCallback sends a single callback and records the outcome. Only recording errors are returned
+func deliverCallback(ctx context.Context, c domain.RateUpdateCallback, callbackRepo adapters.RateUpdateCallbackRepository, sender adapters.CallbackSender, opts CallbackOptions) (bool, error) {
+	body := callbackPayload{
+		UpdateID:  c.UpdateID.String(),
+		Base:      c.Base,
+		Quote:     c.Quote,
+		Status:    string(c.Status),
+		Reason:    c.Reason,
+		UpdatedAt: c.UpdatedAt,
+	}
+	if c.Status == domain.StatusApplied {
+		body.Value = c.Value.StringFixed(domain.RateScale)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal callback %d: %w", c.ID, err)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, perRequestTimeout)
+	sendErr := sender.Send(reqCtx, c.URL, payload)
+	cancel()
+
+	if sendErr == nil {
+		return true, callbackRepo.MarkDelivered(ctx, c.ID)
+	}
+
+	attempts := c.Attempts + 1
+	if opts.MaxAttempts > 0 && attempts >= opts.MaxAttempts {
+		logrus.Warnf("Callback of update %s to %s failed after %d attempts: %s", c.UpdateID, c.URL, attempts, sendErr)
+		return false, callbackRepo.MarkFailed(ctx, c.ID, sendErr.Error())
+	}
+	logrus.Warnf("Callback of update %s to %s failed, it'll be retried: %s", c.UpdateID, c.URL, sendErr)
+	return false, callbackRepo.Reschedule(ctx, c.ID, callbackBackoff(attempts, opts), sendErr.Error())
+}
+
+// callbackBackoff returns InitialBackoff doubled for every attempt after the first one, capped by MaxBackoff
+func callbackBackoff(attempts int, opts CallbackOptions) time.Duration {
+	backoff := opts.InitialBackoff
+	for i := 1; i < attempts && (opts.MaxBackoff <= 0 || backoff < opts.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if opts.MaxBackoff > 0 && backoff > opts.MaxBackoff {
+		return opts.MaxBackoff
+	}
+	return backoff
+}
+
+// validateCallbackURL accepts absolute http(s) URLs with a host. Unless allowPrivate, the host must not be or resolve to
+// a loopback, private or link-local address, so callbacks can't reach internal services
+func validateCallbackURL(ctx context.Context, raw string, allowPrivate bool) error {
+	if len(raw) > maxCallbackURLLength {
+		return ErrCallbackURLInvalid
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrCallbackURLInvalid
+	}
+	if !allowPrivate {
+		if err = netguard.CheckHost(ctx, u.Hostname()); err != nil {
+			return ErrCallbackURLNotPublic
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"fxrates/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRateUpdateCallbackRepository struct{ mock.Mock }
+
+func (m *MockRateUpdateCallbackRepository) Register(ctx context.Context, updateID uuid.UUID, url string) error {
+	args := m.Called(ctx, updateID, url)
+	return args.Error(0)
+}
+
+func (m *MockRateUpdateCallbackRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]domain.RateUpdateCallback, error) {
+	args := m.Called(ctx, limit, lease)
+	callbacks, _ := args.Get(0).([]domain.RateUpdateCallback)
+	return callbacks, args.Error(1)
+}
+
+func (m *MockRateUpdateCallbackRepository) MarkDelivered(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockRateUpdateCallbackRepository) Reschedule(ctx context.Context, id int64, after time.Duration, lastErr string) error {
+	args := m.Called(ctx, id, after, lastErr)
+	return args.Error(0)
+}
+
+func (m *MockRateUpdateCallbackRepository) MarkFailed(ctx context.Context, id int64, lastErr string) error {
+	args := m.Called(ctx, id, lastErr)
+	return args.Error(0)
+}
+
+type MockCallbackSender struct{ mock.Mock }
+
+func (m *MockCallbackSender) Send(ctx context.Context, url string, payload []byte) error {
+	args := m.Called(ctx, url, payload)
+	return args.Error(0)
+}
+
+var testCallbackOpts = CallbackOptions{BatchSize: 10, MaxAttempts: 3, InitialBackoff: 5 * time.Second, MaxBackoff: time.Minute}
+
+// --- DeliverCallbacks ---
+
+func TestDeliverCallbacks_NothingDue(t *testing.T) {
+	repo := new(MockRateUpdateCallbackRepository)
+	sender := new(MockCallbackSender)
+	repo.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]domain.RateUpdateCallback{}, nil).Once()
+
+	err := DeliverCallbacks(context.Background(), "exec", repo, sender, testCallbackOpts)
+
+	require.NoError(t, err)
+	sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestDeliverCallbacks_ClaimError(t *testing.T) {
+	repo := new(MockRateUpdateCallbackRepository)
+	repo.On("ClaimDue", mock.Anything, 10, mock.Anything).Return(nil, errors.New("db down")).Once()
+
+	err := DeliverCallbacks(context.Background(), "exec", repo, new(MockCallbackSender), testCallbackOpts)
+
+	require.ErrorContains(t, err, "failed to claim callbacks: db down")
+}
+
+func TestDeliverCallbacks_DeliversRetriesAndFails(t *testing.T) {
+	repo := new(MockRateUpdateCallbackRepository)
+	sender := new(MockCallbackSender)
+
+	updatedAt := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	delivered := domain.RateUpdateCallback{ID: 1, UpdateID: uuid.New(), URL: "https://a.example.com", Base: "USD", Quote: "EUR", Status: domain.StatusApplied, Value: dec("0.9231"), UpdatedAt: updatedAt}
+	retried := domain.RateUpdateCallback{ID: 2, UpdateID: uuid.New(), URL: "https://b.example.com", Attempts: 1, Base: "USD", Quote: "EUR", Value: dec("0.9231"), UpdatedAt: updatedAt}
+	failed := domain.RateUpdateCallback{ID: 3, UpdateID: uuid.New(), URL: "https://c.example.com", Attempts: 2, Base: "USD", Quote: "EUR", Value: dec("0.9231"), UpdatedAt: updatedAt}
+
+	repo.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]domain.RateUpdateCallback{delivered, retried, failed}, nil).Once()
+
+	var gotPayload callbackPayload
+	sender.On("Send", mock.Anything, delivered.URL, mock.Anything).Run(func(args mock.Arguments) {
+		require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &gotPayload))
+	}).Return(nil).Once()
+	sender.On("Send", mock.Anything, retried.URL, mock.Anything).Return(errors.New("status 503")).Once()
+	sender.On("Send", mock.Anything, failed.URL, mock.Anything).Return(errors.New("status 500")).Once()
+
+	repo.On("MarkDelivered", mock.Anything, int64(1)).Return(nil).Once()
+	repo.On("Reschedule", mock.Anything, int64(2), 10*time.Second, "status 503").Return(nil).Once()
+	repo.On("MarkFailed", mock.Anything, int64(3), "status 500").Return(nil).Once()
+
+	err := DeliverCallbacks(context.Background(), "exec", repo, sender, testCallbackOpts)
+
+	require.NoError(t, err)
+	require.Equal(t, callbackPayload{
+		UpdateID:  delivered.UpdateID.String(),
+		Base:      "USD",
+		Quote:     "EUR",
+		Status:    "applied",
+		Value:     "0.92310000",
+		UpdatedAt: updatedAt,
+	}, gotPayload)
+	repo.AssertExpectations(t)
+	sender.AssertExpectations(t)
+}
+
+func TestDeliverCallbacks_ClosedUpdate_SendsReasonWithoutValue(t *testing.T) {
+	repo := new(MockRateUpdateCallbackRepository)
+	sender := new(MockCallbackSender)
+
+	updatedAt := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	cb := domain.RateUpdateCallback{ID: 4, UpdateID: uuid.New(), URL: "https://a.example.com", Base: "USD", Quote: "XAU",
+		Status: domain.StatusFailed, Reason: "currency XAU isn't supported by the rate provider", UpdatedAt: updatedAt}
+	repo.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]domain.RateUpdateCallback{cb}, nil).Once()
+	var gotPayload map[string]any
+	sender.On("Send", mock.Anything, cb.URL, mock.Anything).Run(func(args mock.Arguments) {
+		require.NoError(t, json.Unmarshal(args.Get(2).([]byte), &gotPayload))
+	}).Return(nil).Once()
+	repo.On("MarkDelivered", mock.Anything, int64(4)).Return(nil).Once()
+
+	require.NoError(t, DeliverCallbacks(context.Background(), "exec", repo, sender, testCallbackOpts))
+
+	require.Equal(t, "failed", gotPayload["status"])
+	require.Equal(t, cb.Reason, gotPayload["reason"])
+	require.NotContains(t, gotPayload, "value")
+	repo.AssertExpectations(t)
+}
+
+func TestDeliverCallbacks_RecordError_Propagates(t *testing.T) {
+	repo := new(MockRateUpdateCallbackRepository)
+	sender := new(MockCallbackSender)
+
+	cb := domain.RateUpdateCallback{ID: 7, UpdateID: uuid.New(), URL: "https://a.example.com", Value: dec("1")}
+	repo.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]domain.RateUpdateCallback{cb}, nil).Once()
+	sender.On("Send", mock.Anything, cb.URL, mock.Anything).Return(nil).Once()
+	repo.On("MarkDelivered", mock.Anything, int64(7)).Return(errors.New("db down")).Once()
+
+	err := DeliverCallbacks(context.Background(), "exec", repo, sender, testCallbackOpts)
+
+	require.ErrorContains(t, err, "db down")
+}
+
+// --- callbackBackoff ---
+
+func TestCallbackBackoff_DoublesUpToMax(t *testing.T) {
+	cases := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{4, 40 * time.Second},
+		{5, time.Minute},
+		{50, time.Minute},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.expected, callbackBackoff(tc.attempts, testCallbackOpts), "attempts %d", tc.attempts)
+	}
+}
+
+// --- validateCallbackURL ---
+
+func TestValidateCallbackURL(t *testing.T) {
+	ctx := context.Background()
+	valid := []string{"https://pricing.example.com/hooks/fx", "http://8.8.8.8:9000/cb?token=1"}
+	for _, raw := range valid {
+		require.NoError(t, validateCallbackURL(ctx, raw, false), raw)
+	}
+
+	invalid := []string{"pricing.example.com/hooks", "ftp://example.com", "https://", "/relative", "https://example.com/" + strings.Repeat("a", maxCallbackURLLength)}
+	for _, raw := range invalid {
+		require.ErrorIs(t, validateCallbackURL(ctx, raw, false), ErrCallbackURLInvalid, raw)
+	}
+
+	private := []string{"http://localhost:9000/cb", "http://127.0.0.1/cb", "http://169.254.169.254/latest/meta-data", "http://10.0.0.5/cb", "http://[::1]:8080/cb"}
+	for _, raw := range private {
+		require.ErrorIs(t, validateCallbackURL(ctx, raw, false), ErrCallbackURLNotPublic, raw)
+		require.NoError(t, validateCallbackURL(ctx, raw, true), raw)
+	}
+}
@@ -0,0 +1,102 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/apikey"
+	"fxrates/internal/domain"
+	"fxrates/internal/rate/grpcapi/fxratesv1"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const apiKeyMetadata = "x-api-key"
+
+// KeyAuthenticator returns the active key matching the given one, see apikey.Service
+type KeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (domain.APIKey, error)
+}
+
+// methodScopes are the scopes RPCs require, the same as their REST routes do. Methods missing here are denied
+var methodScopes = map[string]string{
+	fxratesv1.RatesService_ScheduleUpdate_FullMethodName:          domain.ScopeRatesSchedule,
+	fxratesv1.RatesService_GetUpdate_FullMethodName:               domain.ScopeRatesRead,
+	fxratesv1.RatesService_GetRate_FullMethodName:                 domain.ScopeRatesRead,
+	fxratesv1.RatesService_ListSupportedCurrencies_FullMethodName: domain.ScopeRatesRead,
+	fxratesv1.RatesService_WatchRates_FullMethodName:              domain.ScopeRatesRead,
+}
+
+// UnaryAuth lets calls through only with an active key having the method scope, the key is stored in the call context
+func UnaryAuth(auth KeyAuthenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, auth, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// StreamAuth is UnaryAuth for streaming calls
+func StreamAuth(auth KeyAuthenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), auth, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return next(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authorize(ctx context.Context, auth KeyAuthenticator, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok {
+		return nil, status.Error(codes.PermissionDenied, "method isn't allowed")
+	}
+	raw := keyFromMetadata(ctx)
+	if raw == "" {
+		return nil, status.Error(codes.Unauthenticated, "api key is required")
+	}
+
+	key, err := auth.Authenticate(ctx, raw)
+	if err != nil {
+		if errors.Is(err, apikey.ErrInvalidKey) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		logrus.WithError(err).WithFields(logrus.Fields{"rpc": method}).Error("ups, couldn't authenticate api key this time")
+		return nil, status.Error(codes.Internal, "failed to authenticate api key")
+	}
+	if !key.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("api key lacks %q scope", scope))
+	}
+	return apikey.WithKey(ctx, key), nil
+}
+
+func keyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(apiKeyMetadata); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		return strings.TrimSpace(values[0])
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if token, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
+
+// authenticatedStream passes the context with the authenticated key to stream handlers
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
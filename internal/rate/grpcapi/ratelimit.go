@@ -0,0 +1,50 @@
+package grpcapi
+
+import (
+	"context"
+	"fxrates/internal/metrics"
+	"fxrates/internal/ratelimit"
+	"math"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryRateLimit rejects calls over the client's limit with ResourceExhausted and retry-after in seconds.
+// It must be chained after auth to limit keys rather than peer addresses, the same way the REST middleware does
+func UnaryRateLimit(limiter *ratelimit.ClientLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if err := allow(ctx, limiter, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// StreamRateLimit is UnaryRateLimit for streaming calls, a stream takes a single token when it's opened
+func StreamRateLimit(limiter *ratelimit.ClientLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) error {
+		if err := allow(ss.Context(), limiter, ss.SetHeader); err != nil {
+			return err
+		}
+		return next(srv, ss)
+	}
+}
+
+func allow(ctx context.Context, limiter *ratelimit.ClientLimiter, setHeader func(metadata.MD) error) error {
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remoteAddr = p.Addr.String()
+	}
+	allowed, retryAfter := limiter.Allow(ratelimit.ClientKey(ctx, remoteAddr))
+	if allowed {
+		return nil
+	}
+	metrics.RateLimited.Inc()
+	_ = setHeader(metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(retryAfter.Seconds())))))
+	return status.Error(codes.ResourceExhausted, "too many requests, retry later")
+}
@@ -0,0 +1,207 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fxrates/internal/domain"
+	"fxrates/internal/rate"
+	"fxrates/internal/rate/grpcapi/fxratesv1"
+	"fxrates/internal/rate/handler"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxWatchPairs = 50
+
+// Server serves fxrates.v1.RatesService with the same services as the REST rate handlers
+type Server struct {
+	fxratesv1.UnimplementedRatesServiceServer
+	validator  handler.CurrencyValidator
+	service    handler.RateService
+	subscriber handler.RateSubscriber
+}
+
+func NewRatesServer(currencyValidator handler.CurrencyValidator, rateService handler.RateService, rateSubscriber handler.RateSubscriber) *Server {
+	return &Server{
+		validator:  currencyValidator,
+		service:    rateService,
+		subscriber: rateSubscriber,
+	}
+}
+
+func (s *Server) ScheduleUpdate(ctx context.Context, req *fxratesv1.ScheduleUpdateRequest) (*fxratesv1.ScheduleUpdateResponse, error) {
+	base, quote := normalizeCode(req.GetBase()), normalizeCode(req.GetQuote())
+	if err := s.validator.ValidateCodes(base, quote); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	updateID, err := s.service.ScheduleUpdate(ctx, base, quote, strings.TrimSpace(req.GetCallbackUrl()))
+	if err != nil {
+		if errors.Is(err, rate.ErrCallbackURLInvalid) || errors.Is(err, rate.ErrCallbackURLNotPublic) || errors.Is(err, rate.ErrCallbacksDisabled) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		logrus.WithError(err).WithFields(logrus.Fields{"rpc": "ScheduleUpdate", "base": base, "quote": quote}).Error("update wasn't scheduled")
+		return nil, status.Error(codes.Internal, "failed to schedule rate update")
+	}
+	return &fxratesv1.ScheduleUpdateResponse{UpdateId: updateID.String()}, nil
+}
+
+// GetUpdate answers with the update state for any status; unlike REST, failed and expired updates aren't errors
+func (s *Server) GetUpdate(ctx context.Context, req *fxratesv1.GetUpdateRequest) (*fxratesv1.GetUpdateResponse, error) {
+	updateID, err := uuid.Parse(req.GetUpdateId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid update ID format")
+	}
+
+	view, err := s.service.GetByUpdateID(ctx, updateID)
+	if err != nil {
+		if errors.Is(err, domain.ErrRateNotFound) {
+			return nil, status.Error(codes.NotFound, "rate update not found")
+		}
+		msg := "ups, couldn't get rate by update id this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"rpc": "GetUpdate", "update_id": updateID}).Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+
+	res := &fxratesv1.GetUpdateResponse{
+		UpdateId: updateID.String(),
+		Base:     view.Base,
+		Quote:    view.Quote,
+		Status:   toUpdateStatus(view.Status),
+		Source:   view.Source,
+		Attempts: int32(view.Attempts),
+		Reason:   view.Reason,
+	}
+	if view.Value != nil {
+		res.Value = formatRate(*view.Value)
+	}
+	if view.UpdatedAt != nil {
+		res.UpdatedAt = timestamppb.New(*view.UpdatedAt)
+	}
+	for _, q := range view.Quotes {
+		res.Quotes = append(res.Quotes, &fxratesv1.ProviderQuote{Provider: q.Provider, Value: formatRate(q.Value), Accepted: q.Accepted})
+	}
+	return res, nil
+}
+
+func (s *Server) GetRate(ctx context.Context, req *fxratesv1.GetRateRequest) (*fxratesv1.GetRateResponse, error) {
+	base, quote := normalizeCode(req.GetBase()), normalizeCode(req.GetQuote())
+	if err := s.validator.ValidateCodes(base, quote); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	view, err := s.service.GetByCodes(ctx, base, quote)
+	if err != nil {
+		if errors.Is(err, domain.ErrRateNotFound) {
+			return nil, status.Error(codes.NotFound, "rate not found")
+		}
+		msg := "ups, couldn't get rate by codes this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"rpc": "GetRate", "base": base, "quote": quote}).Error(msg)
+		return nil, status.Error(codes.Internal, msg)
+	}
+
+	res := &fxratesv1.GetRateResponse{
+		Base:      base,
+		Quote:     quote,
+		Value:     formatRate(*view.Value),
+		UpdatedAt: timestamppb.New(*view.UpdatedAt),
+		Derived:   view.Derived,
+	}
+	for _, leg := range view.Legs {
+		res.Legs = append(res.Legs, &fxratesv1.RateLeg{
+			Base:      leg.Base,
+			Quote:     leg.Quote,
+			Value:     formatRate(*leg.Value),
+			UpdatedAt: timestamppb.New(*leg.UpdatedAt),
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) ListSupportedCurrencies(context.Context, *fxratesv1.ListSupportedCurrenciesRequest) (*fxratesv1.ListSupportedCurrenciesResponse, error) {
+	return &fxratesv1.ListSupportedCurrenciesResponse{Codes: s.validator.SupportedCodes()}, nil
+}
+
+// WatchRates sends values applied for the pairs until the client cancels. On shutdown the stream ends with Unavailable,
+// so clients know to reconnect
+func (s *Server) WatchRates(req *fxratesv1.WatchRatesRequest, stream fxratesv1.RatesService_WatchRatesServer) error {
+	pairs, err := s.validatePairs(req.GetPairs())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	changes, unsubscribe := s.subscriber.Subscribe(pairs)
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-changes:
+			if !ok {
+				return status.Error(codes.Unavailable, "server is shutting down")
+			}
+			err = stream.Send(&fxratesv1.RateEvent{
+				UpdateId:  change.UpdateID.String(),
+				Base:      change.Base,
+				Quote:     change.Quote,
+				Value:     formatRate(change.Value),
+				Source:    change.Source,
+				UpdatedAt: timestamppb.New(change.UpdatedAt),
+			})
+			if err != nil {
+				return err // client is gone
+			}
+		}
+	}
+}
+
+func (s *Server) validatePairs(raw []*fxratesv1.RatePair) ([]domain.RatePair, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("at least one pair is required")
+	}
+	if len(raw) > maxWatchPairs {
+		return nil, fmt.Errorf("at most %d pairs can be watched", maxWatchPairs)
+	}
+
+	pairs := make([]domain.RatePair, 0, len(raw))
+	for _, p := range raw {
+		base, quote := normalizeCode(p.GetBase()), normalizeCode(p.GetQuote())
+		if err := s.validator.ValidateCodes(base, quote); err != nil {
+			return nil, fmt.Errorf("invalid pair %q: %w", base+"/"+quote, err)
+		}
+		pairs = append(pairs, domain.RatePair{Base: base, Quote: quote})
+	}
+	return pairs, nil
+}
+
+func toUpdateStatus(s domain.RateUpdateStatus) fxratesv1.UpdateStatus {
+	switch s {
+	case domain.StatusPending:
+		return fxratesv1.UpdateStatus_UPDATE_STATUS_PENDING
+	case domain.StatusApplied:
+		return fxratesv1.UpdateStatus_UPDATE_STATUS_APPLIED
+	case domain.StatusFailed:
+		return fxratesv1.UpdateStatus_UPDATE_STATUS_FAILED
+	case domain.StatusExpired:
+		return fxratesv1.UpdateStatus_UPDATE_STATUS_EXPIRED
+	}
+	return fxratesv1.UpdateStatus_UPDATE_STATUS_UNSPECIFIED
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// formatRate renders a rate the same way as REST does, with exactly domain.RateScale fractional digits
+func formatRate(v decimal.Decimal) string {
+	return v.StringFixed(domain.RateScale)
+}
@@ -0,0 +1,311 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"fxrates/internal/adapters/pubsub"
+	"fxrates/internal/apikey"
+	"fxrates/internal/domain"
+	"fxrates/internal/rate"
+	"fxrates/internal/rate/grpcapi/fxratesv1"
+	"fxrates/internal/ratelimit"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type MockService struct{ mock.Mock }
+
+func (m *MockService) ScheduleUpdate(ctx context.Context, base, quote, callbackURL string) (uuid.UUID, error) {
+	args := m.Called(ctx, base, quote, callbackURL)
+	id, _ := args.Get(0).(uuid.UUID)
+	return id, args.Error(1)
+}
+
+func (m *MockService) ScheduleUpdates(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error) {
+	args := m.Called(ctx, pairs)
+	ids, _ := args.Get(0).(map[domain.RatePair]uuid.UUID)
+	return ids, args.Error(1)
+}
+
+func (m *MockService) GetByUpdateID(ctx context.Context, id uuid.UUID) (rate.View, error) {
+	args := m.Called(ctx, id)
+	v, _ := args.Get(0).(rate.View)
+	return v, args.Error(1)
+}
+
+func (m *MockService) GetByCodes(ctx context.Context, base, quote string) (rate.View, error) {
+	args := m.Called(ctx, base, quote)
+	v, _ := args.Get(0).(rate.View)
+	return v, args.Error(1)
+}
+
+func (m *MockService) GetHistory(ctx context.Context, base, quote string, from, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error) {
+	args := m.Called(ctx, base, quote, from, to, interval)
+	points, _ := args.Get(0).([]domain.RateHistoryPoint)
+	return points, args.Error(1)
+}
+
+func (m *MockService) Convert(ctx context.Context, from, to string, amount decimal.Decimal) (rate.ConversionView, error) {
+	args := m.Called(ctx, from, to, amount)
+	v, _ := args.Get(0).(rate.ConversionView)
+	return v, args.Error(1)
+}
+
+type MockAuthenticator struct{ mock.Mock }
+
+func (m *MockAuthenticator) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	args := m.Called(ctx, key)
+	k, _ := args.Get(0).(domain.APIKey)
+	return k, args.Error(1)
+}
+
+// startServer serves the rates service over an in-memory connection and returns a client of it
+func startServer(t *testing.T, srv *Server, opts ...grpc.ServerOption) fxratesv1.RatesServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	fxratesv1.RegisterRatesServiceServer(server, srv)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return fxratesv1.NewRatesServiceClient(conn)
+}
+
+func newValidator() *rate.CurrencyValidator {
+	return rate.NewValidator(map[string]struct{}{"USD": {}, "EUR": {}, "JPY": {}})
+}
+
+func requireCode(t *testing.T, expected codes.Code, err error) {
+	t.Helper()
+	require.Error(t, err)
+	require.Equal(t, expected, status.Code(err), err.Error())
+}
+
+func TestServer_ScheduleUpdate(t *testing.T) {
+	mockService := new(MockService)
+	client := startServer(t, NewRatesServer(newValidator(), mockService, nil))
+
+	updateID := uuid.New()
+	mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR", "").Return(updateID, nil).Once()
+	mockService.On("ScheduleUpdate", mock.Anything, "USD", "JPY", "ftp://x").Return(uuid.Nil, rate.ErrCallbackURLInvalid).Once()
+	mockService.On("ScheduleUpdate", mock.Anything, "EUR", "JPY", "").Return(uuid.Nil, errors.New("db down")).Once()
+
+	res, err := client.ScheduleUpdate(context.Background(), &fxratesv1.ScheduleUpdateRequest{Base: " usd", Quote: "eur"})
+	require.NoError(t, err)
+	require.Equal(t, updateID.String(), res.GetUpdateId())
+
+	_, err = client.ScheduleUpdate(context.Background(), &fxratesv1.ScheduleUpdateRequest{Base: "USD", Quote: "USD"})
+	requireCode(t, codes.InvalidArgument, err)
+	_, err = client.ScheduleUpdate(context.Background(), &fxratesv1.ScheduleUpdateRequest{Base: "USD", Quote: "JPY", CallbackUrl: "ftp://x"})
+	requireCode(t, codes.InvalidArgument, err)
+	_, err = client.ScheduleUpdate(context.Background(), &fxratesv1.ScheduleUpdateRequest{Base: "EUR", Quote: "JPY"})
+	requireCode(t, codes.Internal, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestServer_GetUpdate(t *testing.T) {
+	mockService := new(MockService)
+	client := startServer(t, NewRatesServer(newValidator(), mockService, nil))
+
+	applied, failed, unknown := uuid.New(), uuid.New(), uuid.New()
+	val := decimal.RequireFromString("0.9231")
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	mockService.On("GetByUpdateID", mock.Anything, applied).Return(rate.View{
+		Base: "USD", Quote: "EUR", Status: domain.StatusApplied, Value: &val, UpdatedAt: &now, Source: "consensus",
+		Quotes: []domain.ProviderQuote{{Provider: "frankfurter", Value: val, Accepted: true}},
+	}, nil).Once()
+	mockService.On("GetByUpdateID", mock.Anything, failed).Return(rate.View{
+		Base: "USD", Quote: "JPY", Status: domain.StatusFailed, UpdatedAt: &now, Attempts: 10, Reason: "rate wasn't fetched after 10 attempts",
+	}, nil).Once()
+	mockService.On("GetByUpdateID", mock.Anything, unknown).Return(rate.View{}, domain.ErrRateNotFound).Once()
+
+	res, err := client.GetUpdate(context.Background(), &fxratesv1.GetUpdateRequest{UpdateId: applied.String()})
+	require.NoError(t, err)
+	require.Equal(t, fxratesv1.UpdateStatus_UPDATE_STATUS_APPLIED, res.GetStatus())
+	require.Equal(t, "0.92310000", res.GetValue())
+	require.True(t, res.GetUpdatedAt().AsTime().Equal(now))
+	require.Equal(t, "consensus", res.GetSource())
+	require.Len(t, res.GetQuotes(), 1)
+	require.Equal(t, "0.92310000", res.GetQuotes()[0].GetValue())
+
+	res, err = client.GetUpdate(context.Background(), &fxratesv1.GetUpdateRequest{UpdateId: failed.String()})
+	require.NoError(t, err)
+	require.Equal(t, fxratesv1.UpdateStatus_UPDATE_STATUS_FAILED, res.GetStatus())
+	require.Empty(t, res.GetValue())
+	require.Equal(t, int32(10), res.GetAttempts())
+	require.Equal(t, "rate wasn't fetched after 10 attempts", res.GetReason())
+
+	_, err = client.GetUpdate(context.Background(), &fxratesv1.GetUpdateRequest{UpdateId: unknown.String()})
+	requireCode(t, codes.NotFound, err)
+	_, err = client.GetUpdate(context.Background(), &fxratesv1.GetUpdateRequest{UpdateId: "nope"})
+	requireCode(t, codes.InvalidArgument, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestServer_GetRate_Derived(t *testing.T) {
+	mockService := new(MockService)
+	client := startServer(t, NewRatesServer(newValidator(), mockService, nil))
+
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	val, leg1, leg2 := decimal.RequireFromString("160.5"), decimal.RequireFromString("0.92"), decimal.RequireFromString("147.66")
+	mockService.On("GetByCodes", mock.Anything, "EUR", "JPY").Return(rate.View{
+		Value: &val, UpdatedAt: &now, Derived: true,
+		Legs: []rate.View{{Base: "USD", Quote: "EUR", Value: &leg1, UpdatedAt: &now}, {Base: "USD", Quote: "JPY", Value: &leg2, UpdatedAt: &now}},
+	}, nil).Once()
+	mockService.On("GetByCodes", mock.Anything, "USD", "EUR").Return(rate.View{}, domain.ErrRateNotFound).Once()
+
+	res, err := client.GetRate(context.Background(), &fxratesv1.GetRateRequest{Base: "eur", Quote: "jpy"})
+	require.NoError(t, err)
+	require.Equal(t, "EUR", res.GetBase())
+	require.Equal(t, "160.50000000", res.GetValue())
+	require.True(t, res.GetDerived())
+	require.Len(t, res.GetLegs(), 2)
+	require.Equal(t, "147.66000000", res.GetLegs()[1].GetValue())
+
+	_, err = client.GetRate(context.Background(), &fxratesv1.GetRateRequest{Base: "USD", Quote: "EUR"})
+	requireCode(t, codes.NotFound, err)
+	_, err = client.GetRate(context.Background(), &fxratesv1.GetRateRequest{Base: "USD", Quote: "XXX"})
+	requireCode(t, codes.InvalidArgument, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestServer_ListSupportedCurrencies(t *testing.T) {
+	client := startServer(t, NewRatesServer(newValidator(), new(MockService), nil))
+
+	res, err := client.ListSupportedCurrencies(context.Background(), &fxratesv1.ListSupportedCurrenciesRequest{})
+
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"USD", "EUR", "JPY"}, res.GetCodes())
+}
+
+func TestServer_WatchRates_StreamsSubscribedPairsUntilShutdown(t *testing.T) {
+	broker := pubsub.NewRateBroker(8)
+	client := startServer(t, NewRatesServer(newValidator(), new(MockService), broker))
+
+	stream, err := client.WatchRates(context.Background(), &fxratesv1.WatchRatesRequest{Pairs: []*fxratesv1.RatePair{{Base: "usd", Quote: "eur"}}})
+	require.NoError(t, err)
+	// the subscription is made once the server got the request, publish until the first event comes
+	updateID := uuid.New()
+	events := make(chan *fxratesv1.RateEvent, 1)
+	go func() {
+		event, recvErr := stream.Recv()
+		if recvErr == nil {
+			events <- event
+		}
+	}()
+	var event *fxratesv1.RateEvent
+	require.Eventually(t, func() bool {
+		broker.Publish([]domain.RateChange{
+			{UpdateID: uuid.New(), Base: "USD", Quote: "JPY", Value: decimal.RequireFromString("147")},
+			{UpdateID: updateID, Base: "USD", Quote: "EUR", Value: decimal.RequireFromString("0.92"), Source: "frankfurter", UpdatedAt: time.Now()},
+		})
+		select {
+		case event = <-events:
+			return true
+		default:
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, updateID.String(), event.GetUpdateId())
+	require.Equal(t, "0.92000000", event.GetValue())
+	require.Equal(t, "frankfurter", event.GetSource())
+
+	broker.Close()
+	for {
+		if _, err = stream.Recv(); err != nil {
+			break
+		}
+	}
+	requireCode(t, codes.Unavailable, err)
+}
+
+func TestServer_WatchRates_InvalidPairs(t *testing.T) {
+	client := startServer(t, NewRatesServer(newValidator(), new(MockService), pubsub.NewRateBroker(8)))
+
+	for name, pairs := range map[string][]*fxratesv1.RatePair{
+		"none":        nil,
+		"unsupported": {{Base: "USD", Quote: "XXX"}},
+		"too many":    make([]*fxratesv1.RatePair, maxWatchPairs+1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			stream, err := client.WatchRates(context.Background(), &fxratesv1.WatchRatesRequest{Pairs: pairs})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			requireCode(t, codes.InvalidArgument, err)
+		})
+	}
+}
+
+func TestAuth_RequiresKeyWithMethodScope(t *testing.T) {
+	auth := new(MockAuthenticator)
+	client := startServer(t, NewRatesServer(newValidator(), new(MockService), pubsub.NewRateBroker(8)),
+		grpc.ChainUnaryInterceptor(UnaryAuth(auth)), grpc.ChainStreamInterceptor(StreamAuth(auth)))
+
+	auth.On("Authenticate", mock.Anything, "reader").Return(domain.APIKey{Scopes: []string{domain.ScopeRatesRead}}, nil)
+	auth.On("Authenticate", mock.Anything, "revoked").Return(domain.APIKey{}, apikey.ErrInvalidKey)
+	withKey := func(header, value string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), header, value)
+	}
+
+	_, err := client.ListSupportedCurrencies(context.Background(), &fxratesv1.ListSupportedCurrenciesRequest{})
+	requireCode(t, codes.Unauthenticated, err)
+	_, err = client.ListSupportedCurrencies(withKey("x-api-key", "revoked"), &fxratesv1.ListSupportedCurrenciesRequest{})
+	requireCode(t, codes.Unauthenticated, err)
+	_, err = client.ListSupportedCurrencies(withKey("authorization", "Bearer reader"), &fxratesv1.ListSupportedCurrenciesRequest{})
+	require.NoError(t, err)
+	_, err = client.ScheduleUpdate(withKey("x-api-key", "reader"), &fxratesv1.ScheduleUpdateRequest{Base: "USD", Quote: "EUR"})
+	requireCode(t, codes.PermissionDenied, err)
+
+	stream, err := client.WatchRates(context.Background(), &fxratesv1.WatchRatesRequest{Pairs: []*fxratesv1.RatePair{{Base: "USD", Quote: "EUR"}}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	requireCode(t, codes.Unauthenticated, err)
+}
+
+func TestRateLimit_LimitsEveryKeyAfterAuth(t *testing.T) {
+	auth := new(MockAuthenticator)
+	limiter := ratelimit.NewClientLimiter(0.001, 1)
+	client := startServer(t, NewRatesServer(newValidator(), new(MockService), pubsub.NewRateBroker(8)),
+		grpc.ChainUnaryInterceptor(UnaryAuth(auth), UnaryRateLimit(limiter)),
+		grpc.ChainStreamInterceptor(StreamAuth(auth), StreamRateLimit(limiter)))
+
+	auth.On("Authenticate", mock.Anything, "first").Return(domain.APIKey{ID: 1, Scopes: []string{domain.ScopeRatesRead}}, nil)
+	auth.On("Authenticate", mock.Anything, "second").Return(domain.APIKey{ID: 2, Scopes: []string{domain.ScopeRatesRead}}, nil)
+	first := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "first")
+	second := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "second")
+
+	_, err := client.ListSupportedCurrencies(first, &fxratesv1.ListSupportedCurrenciesRequest{})
+	require.NoError(t, err)
+	var header metadata.MD
+	_, err = client.ListSupportedCurrencies(first, &fxratesv1.ListSupportedCurrenciesRequest{}, grpc.Header(&header))
+	requireCode(t, codes.ResourceExhausted, err)
+	require.NotEmpty(t, header.Get("retry-after"))
+
+	// streams share the budget of the key
+	stream, err := client.WatchRates(first, &fxratesv1.WatchRatesRequest{Pairs: []*fxratesv1.RatePair{{Base: "USD", Quote: "EUR"}}})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	requireCode(t, codes.ResourceExhausted, err)
+
+	_, err = client.ListSupportedCurrencies(second, &fxratesv1.ListSupportedCurrenciesRequest{})
+	require.NoError(t, err)
+}
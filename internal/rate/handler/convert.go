@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fxrates/internal/domain"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+var maxConvertAmount = decimal.New(1, 12)
+
+type ConvertResponse struct {
+	From            string    `json:"from" example:"USD"`
+	To              string    `json:"to" example:"EUR"`
+	Amount          string    `json:"amount" example:"125.5"`
+	ConvertedAmount string    `json:"converted_amount" example:"115.85"`
+	Rate            string    `json:"rate" example:"0.92310000"`
+	UpdatedAt       time.Time `json:"updated_at" example:"2025-01-02T15:04:05Z"`
+	Derived         bool      `json:"derived" example:"false"`
+	Legs            []RateLeg `json:"legs,omitempty"`
+}
+
+// Convert godoc
+// @Summary Convert amount
+// @Description Convert an amount using the latest stored rate. The reversed pair is used when the direct one is missing, then the rate is derived through the pivot currency. The converted amount is a decimal string rounded half away from zero to the minor units of the target currency, the rate has 8 fractional digits
+// @Tags Conversion
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string true "Source currency code" example(USD)
+// @Param to query string true "Target currency code" example(EUR)
+// @Param amount query number true "Positive amount in source currency" example(125.50)
+// @Success 200 {object} ConvertResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /convert [get]
+func (h *Handler) Convert(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from := strings.ToUpper(strings.TrimSpace(query.Get("from")))
+	to := strings.ToUpper(strings.TrimSpace(query.Get("to")))
+
+	if err := h.validator.ValidateCodes(from, to); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	amount, err := decimal.NewFromString(strings.TrimSpace(query.Get("amount")))
+	if err != nil || !amount.IsPositive() || amount.GreaterThan(maxConvertAmount) {
+		writeError(w, http.StatusBadRequest, "invalid 'amount' parameter, positive number expected")
+		return
+	}
+
+	view, err := h.service.Convert(r.Context(), from, to, amount)
+	if err != nil {
+		if errors.Is(err, domain.ErrRateNotFound) {
+			writeError(w, http.StatusNotFound, "rate not found")
+			return
+		}
+		msg := "ups, couldn't convert amount this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "Convert", "from": from, "to": to}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(ConvertResponse{
+		From:            view.From,
+		To:              view.To,
+		Amount:          view.Amount.String(),
+		ConvertedAmount: view.Result.StringFixed(int32(view.MinorUnits)),
+		Rate:            formatRate(view.Rate),
+		UpdatedAt:       view.UpdatedAt,
+		Derived:         view.Derived,
+		Legs:            toRateLegs(view.Legs),
+	})
+}
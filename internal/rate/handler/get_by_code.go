@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fxrates/internal/domain"
+	"fxrates/internal/rate"
 	"net/http"
 	"strings"
 	"time"
@@ -15,20 +16,33 @@ import (
 type GetByCodesResponse struct {
 	Base      string    `json:"base" example:"USD"`
 	Quote     string    `json:"quote" example:"EUR"`
-	Value     float64   `json:"value" example:"0.9231"`
+	Value     string    `json:"value" example:"0.92310000"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-01-02T15:04:05Z"`
+	Derived   bool      `json:"derived" example:"false"`
+	Legs      []RateLeg `json:"legs,omitempty"`
+}
+
+// RateLeg is a stored rate used to derive a cross rate through the pivot currency
+type RateLeg struct {
+	Base      string    `json:"base" example:"MXN"`
+	Quote     string    `json:"quote" example:"USD"`
+	Value     string    `json:"value" example:"0.05810000"`
 	UpdatedAt time.Time `json:"updated_at" example:"2025-01-02T15:04:05Z"`
 }
 
 // GetByCodes godoc
 // @Summary Get latest rate by codes
-// @Description Get the latest applied FX rate by base/quote codes
+// @Description Get the latest applied FX rate by base/quote codes. Values are decimal strings with 8 fractional digits. When the pair is missing, the rate may be derived through the pivot currency (derived=true, legs are listed)
 // @Tags Rates
 // @Produce json
+// @Security ApiKeyAuth
 // @Param base path string true "Base currency code" example(USD)
 // @Param quote path string true "Quote currency code" example(EUR)
 // @Success 200 {object} GetByCodesResponse
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /rates/{base}/{quote} [get]
 func (h *Handler) GetByCodes(w http.ResponseWriter, r *http.Request) {
@@ -55,10 +69,23 @@ func (h *Handler) GetByCodes(w http.ResponseWriter, r *http.Request) {
 	res := GetByCodesResponse{
 		Base:      base,
 		Quote:     quote,
-		Value:     *view.Value,
+		Value:     formatRate(*view.Value),
 		UpdatedAt: *view.UpdatedAt,
+		Derived:   view.Derived,
+		Legs:      toRateLegs(view.Legs),
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_ = json.NewEncoder(w).Encode(res)
 }
+
+func toRateLegs(views []rate.View) []RateLeg {
+	if len(views) == 0 {
+		return nil
+	}
+	legs := make([]RateLeg, 0, len(views))
+	for _, v := range views {
+		legs = append(legs, RateLeg{Base: v.Base, Quote: v.Quote, Value: formatRate(*v.Value), UpdatedAt: *v.UpdatedAt})
+	}
+	return legs
+}
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fxrates/internal/domain"
+	"fxrates/internal/metrics"
+	"fxrates/internal/rate"
 	"net/http"
 	"time"
 
@@ -12,30 +15,60 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// defaultWaitRecheck is how often a waiting request re-reads its update in case another instance applied it
+const defaultWaitRecheck = 5 * time.Second
+
 type GetByUpdateIDApplied struct {
 	UpdateID  string                  `json:"update_id" example:"77b5d9f5-0569-47e3-aee2-f659d59fbd97"`
 	Base      string                  `json:"base" example:"USD"`
 	Quote     string                  `json:"quote" example:"EUR"`
 	Status    domain.RateUpdateStatus `json:"status" example:"applied"`
-	Value     float64                 `json:"value" example:"0.9231"`
+	Value     string                  `json:"value" example:"0.92310000"`
 	UpdatedAt time.Time               `json:"updated_at" example:"2025-01-02T15:04:05Z"`
+	Source    string                  `json:"source,omitempty" example:"consensus"`
+	Quotes    []ProviderQuote         `json:"quotes,omitempty"`
+}
+
+// ProviderQuote is a value a single provider returned when the rate was agreed by several of them
+type ProviderQuote struct {
+	Provider string `json:"provider" example:"frankfurter"`
+	Value    string `json:"value" example:"0.92310000"`
+	Accepted bool   `json:"accepted" example:"true"`
 }
 type GetByUpdateIDPending struct {
 	UpdateID string                  `json:"update_id" example:"77b5d9f5-0569-47e3-aee2-f659d59fbd97"`
 	Base     string                  `json:"base" example:"USD"`
 	Quote    string                  `json:"quote" example:"EUR"`
 	Status   domain.RateUpdateStatus `json:"status" example:"pending"`
+	Attempts int                     `json:"attempts" example:"1"`
+}
+
+// GetByUpdateIDClosed is returned for an update closed without a value, clients should stop polling and may schedule a new one
+type GetByUpdateIDClosed struct {
+	UpdateID  string                  `json:"update_id" example:"77b5d9f5-0569-47e3-aee2-f659d59fbd97"`
+	Base      string                  `json:"base" example:"USD"`
+	Quote     string                  `json:"quote" example:"EUR"`
+	Status    domain.RateUpdateStatus `json:"status" example:"failed" enums:"failed,expired"`
+	Reason    string                  `json:"reason" example:"rate wasn't fetched after 10 attempts"`
+	Attempts  int                     `json:"attempts" example:"10"`
+	UpdatedAt time.Time               `json:"updated_at" example:"2025-01-02T15:04:05Z"`
 }
 
 // GetByUpdateID godoc
 // @Summary Get rate by update ID
-// @Description Get the applied rate for a scheduled update ID
+// @Description Get the applied rate for a scheduled update ID. Pending updates respond with 202, updates closed without a value (failed or expired) respond with 410 and a reason. Values are decimal strings with 8 fractional digits. Rates agreed by several providers carry per-provider quotes, rejected outliers included
+// @Description With `wait` a pending update is long-polled: the response is held until the update is applied or closed, or the wait expires with 202
 // @Tags Rates
 // @Produce json
+// @Security ApiKeyAuth
 // @Param id path string true "Update ID"
+// @Param wait query string false "How long to wait for a pending update, capped by the server maximum" example(20s)
 // @Success 200 {object} GetByUpdateIDApplied "rate update applied"
-// @Success 202 {object} GetByUpdateIDPending "rate update pending"
+// @Success 202 {object} GetByUpdateIDPending "rate update pending, poll again later"
+// @Failure 410 {object} GetByUpdateIDClosed "rate update failed or expired, stop polling"
 // @Failure 404 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /rates/updates/{id} [get]
 func (h *Handler) GetByUpdateID(w http.ResponseWriter, r *http.Request) {
@@ -46,7 +79,20 @@ func (h *Handler) GetByUpdateID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	view, err := h.service.GetByUpdateID(r.Context(), updateID)
+	wait, err := h.parseWait(r.URL.Query().Get("wait"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	view, err := h.service.GetByUpdateID(ctx, updateID)
+	if err == nil && view.Status == domain.StatusPending && wait > 0 {
+		view, err = h.waitForUpdate(ctx, updateID, view, wait)
+		if ctx.Err() != nil {
+			return // the client went away while waiting, nobody to answer
+		}
+	}
 	if err != nil {
 		if errors.Is(err, domain.ErrRateNotFound) {
 			writeError(w, http.StatusNotFound, "rate update not found")
@@ -59,13 +105,27 @@ func (h *Handler) GetByUpdateID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	if view.Status != domain.StatusApplied {
+	switch view.Status {
+	case domain.StatusPending:
 		w.WriteHeader(http.StatusAccepted)
 		_ = json.NewEncoder(w).Encode(GetByUpdateIDPending{
 			UpdateID: updateID.String(),
 			Base:     view.Base,
 			Quote:    view.Quote,
 			Status:   view.Status,
+			Attempts: view.Attempts,
+		})
+		return
+	case domain.StatusFailed, domain.StatusExpired:
+		w.WriteHeader(http.StatusGone)
+		_ = json.NewEncoder(w).Encode(GetByUpdateIDClosed{
+			UpdateID:  updateID.String(),
+			Base:      view.Base,
+			Quote:     view.Quote,
+			Status:    view.Status,
+			Reason:    view.Reason,
+			Attempts:  view.Attempts,
+			UpdatedAt: *view.UpdatedAt,
 		})
 		return
 	}
@@ -76,7 +136,68 @@ func (h *Handler) GetByUpdateID(w http.ResponseWriter, r *http.Request) {
 		Base:      view.Base,
 		Quote:     view.Quote,
 		Status:    view.Status,
-		Value:     *view.Value,
+		Value:     formatRate(*view.Value),
 		UpdatedAt: *view.UpdatedAt,
+		Source:    view.Source,
+		Quotes:    toProviderQuotes(view.Quotes),
 	})
 }
+
+// parseWait returns how long to wait for a pending update, zero when long-polling isn't asked for or isn't enabled
+func (h *Handler) parseWait(raw string) (time.Duration, error) {
+	if raw == "" || h.waiter == nil {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil || wait < 0 {
+		return 0, errors.New("invalid wait duration, expected a value like 20s")
+	}
+	return min(wait, h.maxUpdateWait), nil
+}
+
+// waitForUpdate blocks until the pending update is finished, the wait expires or the client goes away, and returns the latest view.
+// Besides the waiter wakeup the update is re-read every waitRecheck, as updates applied by other instances don't wake this one
+func (h *Handler) waitForUpdate(ctx context.Context, updateID uuid.UUID, view rate.View, wait time.Duration) (rate.View, error) {
+	finished, stop, err := h.waiter.Wait(updateID)
+	if err != nil {
+		// too many requests are waiting already, this one is answered right away
+		metrics.UpdateWaitsRejected.Inc()
+		return view, nil
+	}
+	defer stop()
+
+	// the update could be finished between the first read and the start of the wait
+	if view, err = h.service.GetByUpdateID(ctx, updateID); err != nil || view.Status != domain.StatusPending {
+		return view, err
+	}
+
+	timeout := time.NewTimer(wait)
+	defer timeout.Stop()
+	recheck := time.NewTicker(h.waitRecheck)
+	defer recheck.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return view, ctx.Err()
+		case <-timeout.C:
+			return view, nil
+		case <-finished:
+			return h.service.GetByUpdateID(ctx, updateID)
+		case <-recheck.C:
+			if view, err = h.service.GetByUpdateID(ctx, updateID); err != nil || view.Status != domain.StatusPending {
+				return view, err
+			}
+		}
+	}
+}
+
+func toProviderQuotes(quotes []domain.ProviderQuote) []ProviderQuote {
+	if len(quotes) == 0 {
+		return nil
+	}
+	res := make([]ProviderQuote, 0, len(quotes))
+	for _, q := range quotes {
+		res = append(res, ProviderQuote{Provider: q.Provider, Value: formatRate(q.Value), Accepted: q.Accepted})
+	}
+	return res
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHistoryRange = 24 * time.Hour
+	maxHistoryRange     = 366 * 24 * time.Hour
+	minHistoryInterval  = time.Minute
+)
+
+type HistoryPoint struct {
+	Value      string    `json:"value" example:"0.92310000"`
+	RecordedAt time.Time `json:"recorded_at" example:"2025-01-02T15:04:05Z"`
+}
+
+type GetHistoryResponse struct {
+	Base     string         `json:"base" example:"USD"`
+	Quote    string         `json:"quote" example:"EUR"`
+	From     time.Time      `json:"from" example:"2025-01-01T15:04:05Z"`
+	To       time.Time      `json:"to" example:"2025-01-02T15:04:05Z"`
+	Interval string         `json:"interval,omitempty" example:"1h0m0s"`
+	Points   []HistoryPoint `json:"points"`
+}
+
+// GetHistory godoc
+// @Summary Get rate history
+// @Description Get applied FX rate values of a pair within [from, to). Values are decimal strings with 8 fractional digits. When interval is set, the last value of each interval bucket is returned
+// @Tags Rates
+// @Produce json
+// @Security ApiKeyAuth
+// @Param base path string true "Base currency code" example(USD)
+// @Param quote path string true "Quote currency code" example(EUR)
+// @Param from query string false "Range start, RFC3339 (default: 24h before 'to')" example(2025-01-01T00:00:00Z)
+// @Param to query string false "Range end, RFC3339 (default: now)" example(2025-01-02T00:00:00Z)
+// @Param interval query string false "Bucket size as Go duration, at least 1m" example(1h)
+// @Success 200 {object} GetHistoryResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /rates/{base}/{quote}/history [get]
+func (h *Handler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	base := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "base")))
+	quote := strings.ToUpper(strings.TrimSpace(chi.URLParam(r, "quote")))
+
+	if err := h.validator.ValidateCodes(base, quote); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	if rawTo := query.Get("to"); rawTo != "" {
+		parsed, err := time.Parse(time.RFC3339, rawTo)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid 'to' parameter, RFC3339 expected")
+			return
+		}
+		to = parsed
+	}
+	from := to.Add(-defaultHistoryRange)
+	if rawFrom := query.Get("from"); rawFrom != "" {
+		parsed, err := time.Parse(time.RFC3339, rawFrom)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid 'from' parameter, RFC3339 expected")
+			return
+		}
+		from = parsed
+	}
+	if !from.Before(to) {
+		writeError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return
+	}
+	if to.Sub(from) > maxHistoryRange {
+		writeError(w, http.StatusBadRequest, "requested range is too wide")
+		return
+	}
+
+	var interval time.Duration
+	if rawInterval := query.Get("interval"); rawInterval != "" {
+		parsed, err := time.ParseDuration(rawInterval)
+		if err != nil || parsed < minHistoryInterval || parsed%time.Second != 0 {
+			writeError(w, http.StatusBadRequest, "invalid 'interval' parameter, whole seconds duration of at least 1m expected")
+			return
+		}
+		interval = parsed
+	}
+
+	points, err := h.service.GetHistory(r.Context(), base, quote, from, to, interval)
+	if err != nil {
+		msg := "ups, couldn't get rate history this time"
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "GetHistory", "base": base, "quote": quote}).Error(msg)
+		writeError(w, http.StatusInternalServerError, msg)
+		return
+	}
+
+	res := GetHistoryResponse{
+		Base:   base,
+		Quote:  quote,
+		From:   from,
+		To:     to,
+		Points: make([]HistoryPoint, 0, len(points)),
+	}
+	if interval > 0 {
+		res.Interval = interval.String()
+	}
+	for _, p := range points {
+		res.Points = append(res.Points, HistoryPoint{Value: formatRate(p.Value), RecordedAt: p.RecordedAt})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(res)
+}
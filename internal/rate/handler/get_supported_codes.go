@@ -14,7 +14,10 @@ type GetSupportedCodesResponse struct {
 // @Description Retrieve all supported currency codes for FX requests
 // @Tags Rates
 // @Produce json
+// @Security ApiKeyAuth
 // @Success 200 {object} GetSupportedCodesResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
 // @Router /rates/supported-currencies [get]
 func (h *Handler) GetSupportedCodes(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -3,10 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fxrates/internal/domain"
 	"fxrates/internal/rate"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type CurrencyValidator interface {
@@ -15,18 +18,49 @@ type CurrencyValidator interface {
 }
 
 type RateService interface {
-	ScheduleUpdate(ctx context.Context, base, quote string) (uuid.UUID, error)
+	ScheduleUpdate(ctx context.Context, base, quote, callbackURL string) (uuid.UUID, error)
+	ScheduleUpdates(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error)
 	GetByUpdateID(ctx context.Context, id uuid.UUID) (rate.View, error)
 	GetByCodes(ctx context.Context, base, quote string) (rate.View, error)
+	GetHistory(ctx context.Context, base, quote string, from, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error)
+	Convert(ctx context.Context, from, to string, amount decimal.Decimal) (rate.ConversionView, error)
+}
+
+// RateSubscriber streams applied changes of the given pairs until unsubscribed
+type RateSubscriber interface {
+	Subscribe(pairs []domain.RatePair) (<-chan domain.RateChange, func())
+}
+
+// UpdateWaiter wakes requests waiting for a pending update once it's applied or closed
+type UpdateWaiter interface {
+	Wait(updateID uuid.UUID) (<-chan struct{}, func(), error)
 }
 
 type Handler struct {
-	validator CurrencyValidator
-	service   RateService
+	validator       CurrencyValidator
+	service         RateService
+	subscriber      RateSubscriber
+	streamHeartbeat time.Duration
+	waiter          UpdateWaiter
+	maxUpdateWait   time.Duration
+	waitRecheck     time.Duration
 }
 
-func NewRateHandler(currencyValidator CurrencyValidator, rateService RateService) *Handler {
-	return &Handler{validator: currencyValidator, service: rateService}
+func NewRateHandler(currencyValidator CurrencyValidator, rateService RateService, rateSubscriber RateSubscriber) *Handler {
+	return &Handler{
+		validator:       currencyValidator,
+		service:         rateService,
+		subscriber:      rateSubscriber,
+		streamHeartbeat: defaultStreamHeartbeat,
+		waitRecheck:     defaultWaitRecheck,
+	}
+}
+
+// WithUpdateWaits lets GetByUpdateID block on pending updates for up to maxWait, woken by the waiter
+func (h *Handler) WithUpdateWaits(waiter UpdateWaiter, maxWait time.Duration) *Handler {
+	h.waiter = waiter
+	h.maxUpdateWait = maxWait
+	return h
 }
 
 type errorResponse struct {
@@ -40,3 +74,8 @@ func writeError(w http.ResponseWriter, statusCode int, errorMsg string) {
 		Error: errorMsg,
 	})
 }
+
+// formatRate renders a rate as a decimal string with exactly domain.RateScale fractional digits
+func formatRate(v decimal.Decimal) string {
+	return v.StringFixed(domain.RateScale)
+}
@@ -7,14 +7,17 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"fxrates/internal/adapters/pubsub"
 	"fxrates/internal/domain"
 	"fxrates/internal/rate"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -34,12 +37,18 @@ func (m *MockValidator) SupportedCodes() []string {
 
 type MockService struct{ mock.Mock }
 
-func (m *MockService) ScheduleUpdate(ctx context.Context, base, quote string) (uuid.UUID, error) {
-	args := m.Called(ctx, base, quote)
+func (m *MockService) ScheduleUpdate(ctx context.Context, base, quote, callbackURL string) (uuid.UUID, error) {
+	args := m.Called(ctx, base, quote, callbackURL)
 	id, _ := args.Get(0).(uuid.UUID)
 	return id, args.Error(1)
 }
 
+func (m *MockService) ScheduleUpdates(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error) {
+	args := m.Called(ctx, pairs)
+	ids, _ := args.Get(0).(map[domain.RatePair]uuid.UUID)
+	return ids, args.Error(1)
+}
+
 func (m *MockService) GetByUpdateID(ctx context.Context, id uuid.UUID) (rate.View, error) {
 	args := m.Called(ctx, id)
 	v, _ := args.Get(0).(rate.View)
@@ -52,6 +61,35 @@ func (m *MockService) GetByCodes(ctx context.Context, base, quote string) (rate.
 	return v, args.Error(1)
 }
 
+func (m *MockService) GetHistory(ctx context.Context, base, quote string, from, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error) {
+	args := m.Called(ctx, base, quote, from, to, interval)
+	points, _ := args.Get(0).([]domain.RateHistoryPoint)
+	return points, args.Error(1)
+}
+
+func (m *MockService) Convert(ctx context.Context, from, to string, amount decimal.Decimal) (rate.ConversionView, error) {
+	args := m.Called(ctx, from, to, amount)
+	v, _ := args.Get(0).(rate.ConversionView)
+	return v, args.Error(1)
+}
+
+type MockSubscriber struct{ mock.Mock }
+
+func (m *MockSubscriber) Subscribe(pairs []domain.RatePair) (<-chan domain.RateChange, func()) {
+	args := m.Called(pairs)
+	ch, _ := args.Get(0).(chan domain.RateChange)
+	return ch, func() { m.MethodCalled("Unsubscribe") }
+}
+
+func dec(v string) decimal.Decimal {
+	return decimal.RequireFromString(v)
+}
+
+// decimalArg matches a decimal mock argument numerically
+func decimalArg(v string) any {
+	return mock.MatchedBy(func(d decimal.Decimal) bool { return d.Equal(dec(v)) })
+}
+
 type errorJSON struct {
 	Error string `json:"error"`
 }
@@ -75,7 +113,7 @@ func TestHandler_GetByCodes_ValidationErrors(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			mockValidator := new(MockValidator)
 			mockService := new(MockService)
-			h := NewRateHandler(mockValidator, mockService)
+			h := NewRateHandler(mockValidator, mockService, nil)
 
 			req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur", nil)
 			rctx := chi.NewRouteContext()
@@ -103,7 +141,7 @@ func TestHandler_GetByCodes_ValidationErrors(t *testing.T) {
 func TestHandler_GetByCodes_NotFound(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur", nil)
 	rctx := chi.NewRouteContext()
@@ -129,7 +167,7 @@ func TestHandler_GetByCodes_NotFound(t *testing.T) {
 func TestHandler_GetByCodes_InternalError(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur", nil)
 	rctx := chi.NewRouteContext()
@@ -155,7 +193,7 @@ func TestHandler_GetByCodes_InternalError(t *testing.T) {
 func TestHandler_GetByCodes_Success(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur", nil)
 	rctx := chi.NewRouteContext()
@@ -165,7 +203,7 @@ func TestHandler_GetByCodes_Success(t *testing.T) {
 	rr := httptest.NewRecorder()
 
 	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
-	val := 0.9231
+	val := dec("0.9231")
 	view := rate.View{Base: "USD", Quote: "EUR", Value: &val, UpdatedAt: &now}
 
 	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
@@ -179,7 +217,285 @@ func TestHandler_GetByCodes_Success(t *testing.T) {
 	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
 	require.Equal(t, "USD", res.Base)
 	require.Equal(t, "EUR", res.Quote)
-	require.InDelta(t, 0.9231, res.Value, 1e-9)
+	require.Equal(t, "0.92310000", res.Value)
+	require.True(t, res.UpdatedAt.Equal(now))
+	mockValidator.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetByCodes_Derived(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/rates/mxn/jpy", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("base", "mxn")
+	rctx.URLParams.Add("quote", "jpy")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	val, leg1, leg2 := dec("7.5"), dec("0.05"), dec("150")
+	view := rate.View{
+		Base: "MXN", Quote: "JPY", Value: &val, UpdatedAt: &now, Derived: true,
+		Legs: []rate.View{
+			{Base: "MXN", Quote: "USD", Value: &leg1, UpdatedAt: &now},
+			{Base: "USD", Quote: "JPY", Value: &leg2, UpdatedAt: &now},
+		},
+	}
+	mockValidator.On("ValidateCodes", "MXN", "JPY").Return(nil).Once()
+	mockService.On("GetByCodes", mock.Anything, "MXN", "JPY").Return(view, nil).Once()
+
+	h.GetByCodes(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res GetByCodesResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.True(t, res.Derived)
+	require.Equal(t, "7.50000000", res.Value)
+	require.Len(t, res.Legs, 2)
+	require.Equal(t, "MXN", res.Legs[0].Base)
+	require.Equal(t, "USD", res.Legs[0].Quote)
+	require.Equal(t, "150.00000000", res.Legs[1].Value)
+	mockService.AssertExpectations(t)
+}
+
+// --- GetHistory ---
+
+func TestHandler_GetHistory_InvalidParams(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   string
+		wantMsg string
+	}{
+		{name: "bad from", query: "?from=yesterday", wantMsg: "invalid 'from' parameter, RFC3339 expected"},
+		{name: "bad to", query: "?to=2025-13-01", wantMsg: "invalid 'to' parameter, RFC3339 expected"},
+		{name: "from after to", query: "?from=2025-01-02T00:00:00Z&to=2025-01-01T00:00:00Z", wantMsg: "'from' must be before 'to'"},
+		{name: "range too wide", query: "?from=2023-01-01T00:00:00Z&to=2025-01-01T00:00:00Z", wantMsg: "requested range is too wide"},
+		{name: "interval too small", query: "?interval=30s", wantMsg: "invalid 'interval' parameter, whole seconds duration of at least 1m expected"},
+		{name: "interval garbage", query: "?interval=hourly", wantMsg: "invalid 'interval' parameter, whole seconds duration of at least 1m expected"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockValidator)
+			mockService := new(MockService)
+			h := NewRateHandler(mockValidator, mockService, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur/history"+tc.query, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("base", "usd")
+			rctx.URLParams.Add("quote", "eur")
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+
+			h.GetHistory(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			var ej errorJSON
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+			require.Equal(t, tc.wantMsg, ej.Error)
+			mockService.AssertNotCalled(t, "GetHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHandler_GetHistory_ValidationError(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/rates/usd/usd/history", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("base", "usd")
+	rctx.URLParams.Add("quote", "usd")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "USD").Return(rate.ErrSameCodes).Once()
+
+	h.GetHistory(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, rate.ErrSameCodes.Error(), ej.Error)
+	mockValidator.AssertExpectations(t)
+}
+
+func TestHandler_GetHistory_InternalError(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur/history", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("base", "usd")
+	rctx.URLParams.Add("quote", "eur")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("GetHistory", mock.Anything, "USD", "EUR", mock.Anything, mock.Anything, time.Duration(0)).
+		Return(nil, errors.New("boom")).Once()
+
+	h.GetHistory(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, "ups, couldn't get rate history this time", ej.Error)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetHistory_Success(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	req := httptest.NewRequest(http.MethodGet, "/rates/usd/eur/history?from=2025-01-01T00:00:00Z&to=2025-01-02T00:00:00Z&interval=1h", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("base", "usd")
+	rctx.URLParams.Add("quote", "eur")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	points := []domain.RateHistoryPoint{
+		{Value: dec("0.92"), RecordedAt: from.Add(time.Hour)},
+		{Value: dec("0.93"), RecordedAt: from.Add(2 * time.Hour)},
+	}
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("GetHistory", mock.Anything, "USD", "EUR", from, to, time.Hour).Return(points, nil).Once()
+
+	h.GetHistory(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var res GetHistoryResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, "USD", res.Base)
+	require.Equal(t, "EUR", res.Quote)
+	require.Equal(t, "1h0m0s", res.Interval)
+	require.Len(t, res.Points, 2)
+	require.Equal(t, "0.92000000", res.Points[0].Value)
+	require.True(t, res.Points[1].RecordedAt.Equal(from.Add(2*time.Hour)))
+	mockValidator.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+// --- Convert ---
+
+func TestHandler_Convert_InvalidAmount(t *testing.T) {
+	for _, raw := range []string{"", "abc", "0", "-5", "NaN", "Inf", "1e13"} {
+		t.Run(raw, func(t *testing.T) {
+			mockValidator := new(MockValidator)
+			mockService := new(MockService)
+			h := NewRateHandler(mockValidator, mockService, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/convert?from=usd&to=eur&amount="+raw, nil)
+			rr := httptest.NewRecorder()
+
+			mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+
+			h.Convert(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			var ej errorJSON
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+			require.Equal(t, "invalid 'amount' parameter, positive number expected", ej.Error)
+			mockService.AssertNotCalled(t, "Convert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHandler_Convert_ValidationError(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/convert?from=usd&to=zzz&amount=1", nil)
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "ZZZ").Return(rate.ErrQuoteUnsupported).Once()
+
+	h.Convert(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, rate.ErrQuoteUnsupported.Error(), ej.Error)
+	mockService.AssertNotCalled(t, "Convert", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandler_Convert_NotFound(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/convert?from=usd&to=eur&amount=10", nil)
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("Convert", mock.Anything, "USD", "EUR", decimalArg("10")).Return(rate.ConversionView{}, domain.ErrRateNotFound).Once()
+
+	h.Convert(rr, req)
+
+	require.Equal(t, http.StatusNotFound, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, "rate not found", ej.Error)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Convert_InternalError(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/convert?from=usd&to=eur&amount=10", nil)
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("Convert", mock.Anything, "USD", "EUR", decimalArg("10")).Return(rate.ConversionView{}, errors.New("boom")).Once()
+
+	h.Convert(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, "ups, couldn't convert amount this time", ej.Error)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Convert_Success(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/convert?from=usd&to=eur&amount=125.50", nil)
+	rr := httptest.NewRecorder()
+
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	view := rate.ConversionView{From: "USD", To: "EUR", Amount: dec("125.50"), Result: dec("115.85"), MinorUnits: 2, Rate: dec("0.9231"), UpdatedAt: now}
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("Convert", mock.Anything, "USD", "EUR", decimalArg("125.50")).Return(view, nil).Once()
+
+	h.Convert(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var res ConvertResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, "USD", res.From)
+	require.Equal(t, "EUR", res.To)
+	require.Equal(t, "125.5", res.Amount)
+	require.Equal(t, "115.85", res.ConvertedAmount)
+	require.Equal(t, "0.92310000", res.Rate)
 	require.True(t, res.UpdatedAt.Equal(now))
 	mockValidator.AssertExpectations(t)
 	mockService.AssertExpectations(t)
@@ -190,7 +506,7 @@ func TestHandler_GetByCodes_Success(t *testing.T) {
 func TestHandler_GetByUpdateID_InvalidID(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/rates/updates/not-a-uuid", nil)
 	rctx := chi.NewRouteContext()
@@ -210,7 +526,7 @@ func TestHandler_GetByUpdateID_InvalidID(t *testing.T) {
 func TestHandler_GetByUpdateID_NotFound(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	updateID := uuid.New()
 	req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String(), nil)
@@ -232,7 +548,7 @@ func TestHandler_GetByUpdateID_NotFound(t *testing.T) {
 
 func TestHandler_GetByUpdateID_InternalError(t *testing.T) {
 	mockService := new(MockService)
-	h := NewRateHandler(new(MockValidator), mockService)
+	h := NewRateHandler(new(MockValidator), mockService, nil)
 
 	updateID := uuid.New()
 	req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String(), nil)
@@ -254,7 +570,7 @@ func TestHandler_GetByUpdateID_InternalError(t *testing.T) {
 
 func TestHandler_GetByUpdateID_Pending(t *testing.T) {
 	mockService := new(MockService)
-	h := NewRateHandler(new(MockValidator), mockService)
+	h := NewRateHandler(new(MockValidator), mockService, nil)
 
 	updateID := uuid.New()
 	req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String(), nil)
@@ -263,7 +579,7 @@ func TestHandler_GetByUpdateID_Pending(t *testing.T) {
 	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
 	rr := httptest.NewRecorder()
 
-	view := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusPending}
+	view := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusPending, Attempts: 2}
 	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(view, nil).Once()
 
 	h.GetByUpdateID(rr, req)
@@ -276,12 +592,47 @@ func TestHandler_GetByUpdateID_Pending(t *testing.T) {
 	require.Equal(t, "USD", res.Base)
 	require.Equal(t, "EUR", res.Quote)
 	require.Equal(t, domain.StatusPending, res.Status)
+	require.Equal(t, 2, res.Attempts)
 	mockService.AssertExpectations(t)
 }
 
+func TestHandler_GetByUpdateID_Closed(t *testing.T) {
+	for _, status := range []domain.RateUpdateStatus{domain.StatusFailed, domain.StatusExpired} {
+		t.Run(string(status), func(t *testing.T) {
+			mockService := new(MockService)
+			h := NewRateHandler(new(MockValidator), mockService, nil)
+
+			updateID := uuid.New()
+			req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String(), nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", updateID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+			view := rate.View{Base: "USD", Quote: "EUR", Status: status, UpdatedAt: &now, Attempts: 10, Reason: "rate wasn't fetched after 10 attempts"}
+			mockService.On("GetByUpdateID", mock.Anything, updateID).Return(view, nil).Once()
+
+			h.GetByUpdateID(rr, req)
+
+			require.Equal(t, http.StatusGone, rr.Code)
+			require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			var res GetByUpdateIDClosed
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+			require.Equal(t, updateID.String(), res.UpdateID)
+			require.Equal(t, status, res.Status)
+			require.Equal(t, "rate wasn't fetched after 10 attempts", res.Reason)
+			require.Equal(t, 10, res.Attempts)
+			require.True(t, res.UpdatedAt.Equal(now))
+			require.NotContains(t, rr.Body.String(), `"value"`)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_GetByUpdateID_Applied(t *testing.T) {
 	mockService := new(MockService)
-	h := NewRateHandler(new(MockValidator), mockService)
+	h := NewRateHandler(new(MockValidator), mockService, nil)
 
 	updateID := uuid.New()
 	req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String(), nil)
@@ -290,9 +641,9 @@ func TestHandler_GetByUpdateID_Applied(t *testing.T) {
 	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
 	rr := httptest.NewRecorder()
 
-	val := 1.01
+	val := dec("1.01")
 	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
-	view := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusApplied, Value: &val, UpdatedAt: &now}
+	view := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusApplied, Value: &val, UpdatedAt: &now, Source: "frankfurter"}
 	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(view, nil).Once()
 
 	h.GetByUpdateID(rr, req)
@@ -305,8 +656,172 @@ func TestHandler_GetByUpdateID_Applied(t *testing.T) {
 	require.Equal(t, "USD", res.Base)
 	require.Equal(t, "EUR", res.Quote)
 	require.Equal(t, domain.StatusApplied, res.Status)
-	require.InDelta(t, 1.01, res.Value, 1e-9)
+	require.Equal(t, "1.01000000", res.Value)
 	require.True(t, res.UpdatedAt.Equal(now))
+	require.Equal(t, "frankfurter", res.Source)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetByUpdateID_Applied_WithConsensusQuotes(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	updateID := uuid.New()
+	req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String(), nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", updateID.String())
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	val := dec("0.9205")
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	view := rate.View{
+		Base: "USD", Quote: "EUR", Status: domain.StatusApplied, Value: &val, UpdatedAt: &now, Source: "consensus",
+		Quotes: []domain.ProviderQuote{
+			{Provider: "open_er_api", Value: dec("0.921"), Accepted: true},
+			{Provider: "frankfurter", Value: dec("1.05"), Accepted: false},
+		},
+	}
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(view, nil).Once()
+
+	h.GetByUpdateID(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var res GetByUpdateIDApplied
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, "consensus", res.Source)
+	require.Equal(t, []ProviderQuote{
+		{Provider: "open_er_api", Value: "0.92100000", Accepted: true},
+		{Provider: "frankfurter", Value: "1.05000000", Accepted: false},
+	}, res.Quotes)
+	mockService.AssertExpectations(t)
+}
+
+// --- GetByUpdateID with wait ---
+
+func updateIDRequest(ctx context.Context, updateID uuid.UUID, wait string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/rates/updates/"+updateID.String()+"?wait="+wait, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", updateID.String())
+	return req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+}
+
+func TestHandler_GetByUpdateID_Wait_InvalidDuration(t *testing.T) {
+	for _, wait := range []string{"soon", "-1s"} {
+		t.Run(wait, func(t *testing.T) {
+			h := NewRateHandler(new(MockValidator), new(MockService), nil).WithUpdateWaits(pubsub.NewUpdateWaiters(8), time.Second)
+			rr := httptest.NewRecorder()
+
+			h.GetByUpdateID(rr, updateIDRequest(context.Background(), uuid.New(), wait))
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestHandler_GetByUpdateID_Wait_AnswersOnceApplied(t *testing.T) {
+	mockService := new(MockService)
+	waiters := pubsub.NewUpdateWaiters(8)
+	h := NewRateHandler(new(MockValidator), mockService, nil).WithUpdateWaits(waiters, time.Minute)
+
+	updateID := uuid.New()
+	waiting := make(chan struct{})
+	pending := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusPending}
+	val := dec("0.92")
+	now := time.Now().UTC()
+	applied := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusApplied, Value: &val, UpdatedAt: &now}
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(pending, nil).Once()
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(pending, nil).Once().Run(func(mock.Arguments) { close(waiting) })
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(applied, nil).Once()
+
+	rr := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.GetByUpdateID(rr, updateIDRequest(context.Background(), updateID, "30s"))
+	}()
+	<-waiting
+	waiters.NotifyFinished([]uuid.UUID{updateID})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiting request wasn't woken")
+	}
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), `"value":"0.92000000"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetByUpdateID_Wait_ExpiresWithPending(t *testing.T) {
+	mockService := new(MockService)
+	h := NewRateHandler(new(MockValidator), mockService, nil).WithUpdateWaits(pubsub.NewUpdateWaiters(8), 50*time.Millisecond)
+
+	updateID := uuid.New()
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusPending}, nil)
+
+	rr := httptest.NewRecorder()
+	start := time.Now()
+	h.GetByUpdateID(rr, updateIDRequest(context.Background(), updateID, "1h")) // capped by the handler maximum
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestHandler_GetByUpdateID_Wait_RechecksForOtherInstances(t *testing.T) {
+	mockService := new(MockService)
+	h := NewRateHandler(new(MockValidator), mockService, nil).WithUpdateWaits(pubsub.NewUpdateWaiters(8), time.Minute)
+	h.waitRecheck = 10 * time.Millisecond
+
+	updateID := uuid.New()
+	now := time.Now().UTC()
+	closed := rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusFailed, UpdatedAt: &now, Reason: "rate wasn't fetched after 3 attempts"}
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusPending}, nil).Twice()
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(closed, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.GetByUpdateID(rr, updateIDRequest(context.Background(), updateID, "30s"))
+
+	require.Equal(t, http.StatusGone, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetByUpdateID_Wait_ClientGone_WritesNothing(t *testing.T) {
+	mockService := new(MockService)
+	waiters := pubsub.NewUpdateWaiters(1)
+	h := NewRateHandler(new(MockValidator), mockService, nil).WithUpdateWaits(waiters, time.Minute)
+
+	updateID := uuid.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(rate.View{Status: domain.StatusPending}, nil).Once()
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(rate.View{Status: domain.StatusPending}, nil).Once().Run(func(mock.Arguments) { cancel() })
+
+	rr := httptest.NewRecorder()
+	h.GetByUpdateID(rr, updateIDRequest(ctx, updateID, "30s"))
+
+	require.Empty(t, rr.Body.String())
+	_, _, err := waiters.Wait(uuid.New()) // the only waiter slot was freed
+	require.NoError(t, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetByUpdateID_Wait_TooManyWaiters_AnswersRightAway(t *testing.T) {
+	mockService := new(MockService)
+	waiters := pubsub.NewUpdateWaiters(1)
+	_, stop, err := waiters.Wait(uuid.New())
+	require.NoError(t, err)
+	defer stop()
+	h := NewRateHandler(new(MockValidator), mockService, nil).WithUpdateWaits(waiters, time.Minute)
+
+	updateID := uuid.New()
+	mockService.On("GetByUpdateID", mock.Anything, updateID).Return(rate.View{Base: "USD", Quote: "EUR", Status: domain.StatusPending}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.GetByUpdateID(rr, updateIDRequest(context.Background(), updateID, "30s"))
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
 	mockService.AssertExpectations(t)
 }
 
@@ -315,7 +830,7 @@ func TestHandler_GetByUpdateID_Applied(t *testing.T) {
 func TestHandler_ScheduleUpdate_InvalidJSON(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString("{"))
 	rr := httptest.NewRecorder()
@@ -327,13 +842,13 @@ func TestHandler_ScheduleUpdate_InvalidJSON(t *testing.T) {
 	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
 	require.Equal(t, "invalid request body", ej.Error)
 	mockValidator.AssertNotCalled(t, "ValidateCodes", mock.Anything, mock.Anything)
-	mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestHandler_ScheduleUpdate_UnknownField(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	body := `{"base":"USD","quote":"EUR","extra":1}`
 	req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString(body))
@@ -346,16 +861,16 @@ func TestHandler_ScheduleUpdate_UnknownField(t *testing.T) {
 	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
 	require.Equal(t, "invalid request body", ej.Error)
 	mockValidator.AssertNotCalled(t, "ValidateCodes", mock.Anything, mock.Anything)
-	mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestHandler_ScheduleUpdate_BodyTooLarge(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
-	// Build a single JSON object whose size exceeds 256 bytes
-	longBase := make([]byte, 270)
+	// Build a single JSON object whose size exceeds 4 KiB
+	longBase := make([]byte, 5000)
 	for i := range longBase {
 		longBase[i] = 'A'
 	}
@@ -370,7 +885,7 @@ func TestHandler_ScheduleUpdate_BodyTooLarge(t *testing.T) {
 	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
 	require.Equal(t, "invalid request body", ej.Error)
 	mockValidator.AssertNotCalled(t, "ValidateCodes", mock.Anything, mock.Anything)
-	mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything)
+	mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestHandler_ScheduleUpdate_ValidationErrors(t *testing.T) {
@@ -390,7 +905,7 @@ func TestHandler_ScheduleUpdate_ValidationErrors(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			mockValidator := new(MockValidator)
 			mockService := new(MockService)
-			h := NewRateHandler(mockValidator, mockService)
+			h := NewRateHandler(mockValidator, mockService, nil)
 
 			body := `{"base":" usd ","quote":" eur"}`
 			req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString(body))
@@ -404,7 +919,7 @@ func TestHandler_ScheduleUpdate_ValidationErrors(t *testing.T) {
 			var ej errorJSON
 			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
 			require.Equal(t, tc.wantMsg, ej.Error)
-			mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything)
+			mockService.AssertNotCalled(t, "ScheduleUpdate", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 			mockValidator.AssertExpectations(t)
 		})
 	}
@@ -413,14 +928,14 @@ func TestHandler_ScheduleUpdate_ValidationErrors(t *testing.T) {
 func TestHandler_ScheduleUpdate_ServiceError(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	body := `{"base":" usd ","quote":" eur"}`
 	req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString(body))
 	rr := httptest.NewRecorder()
 
 	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
-	mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR").Return(uuid.Nil, errors.New("failed")).Once()
+	mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR", "").Return(uuid.Nil, errors.New("failed")).Once()
 
 	h.ScheduleUpdate(rr, req)
 
@@ -436,7 +951,7 @@ func TestHandler_ScheduleUpdate_ServiceError(t *testing.T) {
 func TestHandler_ScheduleUpdate_Success(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	body := `{"base":" usd ","quote":" eur"}`
 	req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString(body))
@@ -444,7 +959,7 @@ func TestHandler_ScheduleUpdate_Success(t *testing.T) {
 
 	updateID := uuid.New()
 	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
-	mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR").Return(updateID, nil).Once()
+	mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR", "").Return(updateID, nil).Once()
 
 	h.ScheduleUpdate(rr, req)
 
@@ -457,10 +972,58 @@ func TestHandler_ScheduleUpdate_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestHandler_ScheduleUpdate_WithCallbackURL(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	body := `{"base":"USD","quote":"EUR","callback_url":" https://pricing.example.com/hooks/fx "}`
+	req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	updateID := uuid.New()
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR", "https://pricing.example.com/hooks/fx").Return(updateID, nil).Once()
+
+	h.ScheduleUpdate(rr, req)
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	var res ScheduleUpdateResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, updateID.String(), res.UpdateID)
+	mockValidator.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ScheduleUpdate_CallbackErrors(t *testing.T) {
+	for _, serviceErr := range []error{rate.ErrCallbackURLInvalid, rate.ErrCallbackURLNotPublic, rate.ErrCallbacksDisabled} {
+		t.Run(serviceErr.Error(), func(t *testing.T) {
+			mockValidator := new(MockValidator)
+			mockService := new(MockService)
+			h := NewRateHandler(mockValidator, mockService, nil)
+
+			body := `{"base":"USD","quote":"EUR","callback_url":"ftp://example.com"}`
+			req := httptest.NewRequest(http.MethodPost, "/rates/updates", bytes.NewBufferString(body))
+			rr := httptest.NewRecorder()
+
+			mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+			mockService.On("ScheduleUpdate", mock.Anything, "USD", "EUR", "ftp://example.com").Return(uuid.Nil, serviceErr).Once()
+
+			h.ScheduleUpdate(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			var ej errorJSON
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+			require.Equal(t, serviceErr.Error(), ej.Error)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestHandler_GetSupportedCodes(t *testing.T) {
 	mockValidator := new(MockValidator)
 	mockService := new(MockService)
-	h := NewRateHandler(mockValidator, mockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
 
 	mockValidator.On("SupportedCodes").Return([]string{"USD", "EUR"}).Once()
 
@@ -478,3 +1041,215 @@ func TestHandler_GetSupportedCodes(t *testing.T) {
 	mockValidator.AssertExpectations(t)
 	mockService.AssertExpectations(t)
 }
+
+// --- ScheduleUpdatesBatch ---
+
+func TestHandler_ScheduleUpdatesBatch_InvalidRequest(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		wantMsg string
+	}{
+		{name: "invalid json", body: `{"pairs":`, wantMsg: "invalid request body"},
+		{name: "unknown field", body: `{"pairs":[{"base":"USD","quote":"EUR","x":1}]}`, wantMsg: "invalid request body"},
+		{name: "no pairs", body: `{"pairs":[]}`, wantMsg: "'pairs' are required"},
+		{name: "too many pairs", body: `{"pairs":[` + strings.TrimSuffix(strings.Repeat(`{"base":"USD","quote":"EUR"},`, maxBatchPairs+1), ",") + `]}`, wantMsg: "at most 100 pairs can be scheduled at once"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := new(MockService)
+			h := NewRateHandler(new(MockValidator), mockService, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/rates/updates:batch", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+
+			h.ScheduleUpdatesBatch(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			var ej errorJSON
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+			require.Equal(t, tc.wantMsg, ej.Error)
+			mockService.AssertNotCalled(t, "ScheduleUpdates", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestHandler_ScheduleUpdatesBatch_PerPairResults(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	body := `{"pairs":[{"base":" usd ","quote":"eur"},{"base":"USD","quote":"XXX"},{"base":"GBP","quote":"JPY"},{"base":"USD","quote":"EUR"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/rates/updates:batch", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	usdEUR, gbpJPY := uuid.New(), uuid.New()
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Twice()
+	mockValidator.On("ValidateCodes", "USD", "XXX").Return(rate.ErrQuoteUnsupported).Once()
+	mockValidator.On("ValidateCodes", "GBP", "JPY").Return(nil).Once()
+	mockService.On("ScheduleUpdates", mock.Anything, []domain.RatePair{{Base: "USD", Quote: "EUR"}, {Base: "GBP", Quote: "JPY"}, {Base: "USD", Quote: "EUR"}}).
+		Return(map[domain.RatePair]uuid.UUID{{Base: "USD", Quote: "EUR"}: usdEUR, {Base: "GBP", Quote: "JPY"}: gbpJPY}, nil).Once()
+
+	h.ScheduleUpdatesBatch(rr, req)
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	require.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var res ScheduleUpdatesBatchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, []ScheduleUpdatesBatchResult{
+		{Base: "USD", Quote: "EUR", UpdateID: usdEUR.String()},
+		{Base: "USD", Quote: "XXX", Error: rate.ErrQuoteUnsupported.Error()},
+		{Base: "GBP", Quote: "JPY", UpdateID: gbpJPY.String()},
+		{Base: "USD", Quote: "EUR", UpdateID: usdEUR.String()},
+	}, res.Results)
+	mockValidator.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ScheduleUpdatesBatch_AllInvalid_NothingScheduled(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	body := `{"pairs":[{"base":"USD","quote":"USD"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/rates/updates:batch", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "USD").Return(rate.ErrSameCodes).Once()
+
+	h.ScheduleUpdatesBatch(rr, req)
+
+	require.Equal(t, http.StatusAccepted, rr.Code)
+	var res ScheduleUpdatesBatchResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Equal(t, []ScheduleUpdatesBatchResult{{Base: "USD", Quote: "USD", Error: rate.ErrSameCodes.Error()}}, res.Results)
+	mockService.AssertNotCalled(t, "ScheduleUpdates", mock.Anything, mock.Anything)
+}
+
+func TestHandler_ScheduleUpdatesBatch_ServiceError(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockService := new(MockService)
+	h := NewRateHandler(mockValidator, mockService, nil)
+
+	body := `{"pairs":[{"base":"USD","quote":"EUR"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/rates/updates:batch", bytes.NewBufferString(body))
+	rr := httptest.NewRecorder()
+
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockService.On("ScheduleUpdates", mock.Anything, mock.Anything).Return(nil, errors.New("db down")).Once()
+
+	h.ScheduleUpdatesBatch(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	var ej errorJSON
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+	require.Equal(t, "failed to schedule rate updates", ej.Error)
+}
+
+// --- StreamRates ---
+
+func TestHandler_StreamRates_InvalidPairs(t *testing.T) {
+	cases := []struct {
+		name    string
+		pairs   string
+		wantMsg string
+	}{
+		{name: "missing", pairs: "", wantMsg: "'pairs' parameter is required"},
+		{name: "malformed", pairs: "USDEUR", wantMsg: `invalid pair "USDEUR", BASE/QUOTE expected`},
+		{name: "unsupported", pairs: "USD/EUR,usd/xxx", wantMsg: `invalid pair "usd/xxx": ` + rate.ErrQuoteUnsupported.Error()},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockValidator := new(MockValidator)
+			mockSubscriber := new(MockSubscriber)
+			h := NewRateHandler(mockValidator, new(MockService), mockSubscriber)
+
+			mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Maybe()
+			mockValidator.On("ValidateCodes", "USD", "XXX").Return(rate.ErrQuoteUnsupported).Maybe()
+
+			req := httptest.NewRequest(http.MethodGet, "/rates/stream?pairs="+tc.pairs, nil)
+			rr := httptest.NewRecorder()
+
+			h.StreamRates(rr, req)
+
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			var ej errorJSON
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ej))
+			require.Equal(t, tc.wantMsg, ej.Error)
+			mockSubscriber.AssertNotCalled(t, "Subscribe", mock.Anything)
+		})
+	}
+}
+
+func TestHandler_StreamRates_TooManyPairs(t *testing.T) {
+	h := NewRateHandler(new(MockValidator), new(MockService), new(MockSubscriber))
+
+	pairs := strings.TrimSuffix(strings.Repeat("USD/EUR,", maxStreamPairs+1), ",")
+	req := httptest.NewRequest(http.MethodGet, "/rates/stream?pairs="+pairs, nil)
+	rr := httptest.NewRecorder()
+
+	h.StreamRates(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestHandler_StreamRates_SendsEventsUntilClosed(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockSubscriber := new(MockSubscriber)
+	h := NewRateHandler(mockValidator, new(MockService), mockSubscriber)
+
+	changes := make(chan domain.RateChange, 1)
+	updateID := uuid.New()
+	updatedAt := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	changes <- domain.RateChange{UpdateID: updateID, Base: "USD", Quote: "EUR", Value: dec("0.9231"), Source: "frankfurter", UpdatedAt: updatedAt}
+	close(changes) // broker closed on shutdown
+
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockValidator.On("ValidateCodes", "GBP", "JPY").Return(nil).Once()
+	mockSubscriber.On("Subscribe", []domain.RatePair{{Base: "USD", Quote: "EUR"}, {Base: "GBP", Quote: "JPY"}}).Return(changes).Once()
+	mockSubscriber.On("Unsubscribe").Return().Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/rates/stream?pairs=usd/eur,%20GBP/JPY", nil)
+	rr := httptest.NewRecorder()
+
+	h.StreamRates(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	require.Equal(t, "no-cache", rr.Header().Get("Cache-Control"))
+
+	body := rr.Body.String()
+	require.True(t, strings.HasPrefix(body, ": connected\n\n"))
+	require.Contains(t, body, "id: "+updateID.String()+"\nevent: rate\ndata: ")
+
+	data := body[strings.Index(body, "data: ")+len("data: "):]
+	data = data[:strings.Index(data, "\n\n")]
+	var ev RateEvent
+	require.NoError(t, json.Unmarshal([]byte(data), &ev))
+	require.Equal(t, RateEvent{UpdateID: updateID.String(), Base: "USD", Quote: "EUR", Value: "0.92310000", Source: "frankfurter", UpdatedAt: updatedAt}, ev)
+	mockSubscriber.AssertExpectations(t)
+}
+
+func TestHandler_StreamRates_HeartbeatsAndStopsOnClientDisconnect(t *testing.T) {
+	mockValidator := new(MockValidator)
+	mockSubscriber := new(MockSubscriber)
+	h := NewRateHandler(mockValidator, new(MockService), mockSubscriber)
+	h.streamHeartbeat = 5 * time.Millisecond
+
+	mockValidator.On("ValidateCodes", "USD", "EUR").Return(nil).Once()
+	mockSubscriber.On("Subscribe", []domain.RatePair{{Base: "USD", Quote: "EUR"}}).Return(make(chan domain.RateChange)).Once()
+	mockSubscriber.On("Unsubscribe").Return().Once()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/rates/stream?pairs=USD/EUR", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	h.StreamRates(rr, req) // returns once the request context is done
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	require.Contains(t, rr.Body.String(), ": heartbeat\n\n")
+	mockSubscriber.AssertExpectations(t)
+}
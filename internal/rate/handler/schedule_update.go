@@ -2,15 +2,22 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fxrates/internal/rate"
 	"net/http"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 )
 
+// maxScheduleUpdateBodyBytes leaves room for a callback URL
+const maxScheduleUpdateBodyBytes = 4 << 10
+
 type ScheduleUpdateRequest struct {
 	Base  string `json:"base" example:"USD"`
 	Quote string `json:"quote" example:"EUR"`
+	// CallbackURL receives a signed POST with the applied rate, see README for the payload and signature
+	CallbackURL string `json:"callback_url,omitempty" example:"https://pricing.example.com/hooks/fx"`
 }
 
 type ScheduleUpdateResponse struct {
@@ -19,17 +26,21 @@ type ScheduleUpdateResponse struct {
 
 // ScheduleUpdate godoc
 // @Summary Schedule rate update
-// @Description Schedule a rate update for a currency pair
+// @Description Schedule a rate update for a currency pair. Optional callback_url gets a signed POST once the update is applied
+// @Description (retried with exponential backoff until a 2xx response), so polling the update isn't required
 // @Tags Rates
 // @Accept json
 // @Produce json
+// @Security ApiKeyAuth
 // @Param request body ScheduleUpdateRequest true "ApplyUpdates parameters"
 // @Success 202 {object} ScheduleUpdateResponse
 // @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Router /rates/updates [post]
 func (h *Handler) ScheduleUpdate(w http.ResponseWriter, r *http.Request) {
-	r.Body = http.MaxBytesReader(w, r.Body, 256)
+	r.Body = http.MaxBytesReader(w, r.Body, maxScheduleUpdateBodyBytes)
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 
@@ -47,8 +58,14 @@ func (h *Handler) ScheduleUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updateID, err := h.service.ScheduleUpdate(r.Context(), base, quote)
+	callbackURL := strings.TrimSpace(req.CallbackURL)
+
+	updateID, err := h.service.ScheduleUpdate(r.Context(), base, quote, callbackURL)
 	if err != nil {
+		if errors.Is(err, rate.ErrCallbackURLInvalid) || errors.Is(err, rate.ErrCallbackURLNotPublic) || errors.Is(err, rate.ErrCallbacksDisabled) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		logrus.WithError(err).WithFields(logrus.Fields{"handler": "ScheduleUpdate", "base": base, "quote": quote}).Error("update wasn't scheduled")
 		writeError(w, http.StatusInternalServerError, "failed to schedule rate update")
 		return
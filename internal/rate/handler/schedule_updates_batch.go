@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"fxrates/internal/domain"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxBatchPairs                    = 100
+	maxScheduleUpdatesBatchBodyBytes = 16 << 10
+)
+
+type ScheduleUpdatesBatchRequest struct {
+	Pairs []ScheduleUpdatesBatchPair `json:"pairs"`
+}
+
+type ScheduleUpdatesBatchPair struct {
+	Base  string `json:"base" example:"USD"`
+	Quote string `json:"quote" example:"EUR"`
+}
+
+type ScheduleUpdatesBatchResponse struct {
+	Results []ScheduleUpdatesBatchResult `json:"results"`
+}
+
+// ScheduleUpdatesBatchResult has either the update ID or the validation error of a pair
+type ScheduleUpdatesBatchResult struct {
+	Base     string `json:"base" example:"USD"`
+	Quote    string `json:"quote" example:"EUR"`
+	UpdateID string `json:"update_id,omitempty" example:"77b5d9f5-0569-47e3-aee2-f659d59fbd97"`
+	Error    string `json:"error,omitempty" example:"quote currency not supported"`
+}
+
+// ScheduleUpdatesBatch godoc
+// @Summary Schedule rate updates in batch
+// @Description Schedule rate updates for up to 100 pairs at once. Results follow the request order, each has either
+// @Description an update ID or the reason the pair was rejected. Valid pairs are scheduled even when others are rejected
+// @Tags Rates
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body ScheduleUpdatesBatchRequest true "Pairs to update"
+// @Success 202 {object} ScheduleUpdatesBatchResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /rates/updates:batch [post]
+func (h *Handler) ScheduleUpdatesBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxScheduleUpdatesBatchBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	var req ScheduleUpdatesBatchRequest
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Pairs) == 0 {
+		writeError(w, http.StatusBadRequest, "'pairs' are required")
+		return
+	}
+	if len(req.Pairs) > maxBatchPairs {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("at most %d pairs can be scheduled at once", maxBatchPairs))
+		return
+	}
+
+	results := make([]ScheduleUpdatesBatchResult, 0, len(req.Pairs))
+	valid := make([]domain.RatePair, 0, len(req.Pairs))
+	for _, p := range req.Pairs {
+		res := ScheduleUpdatesBatchResult{
+			Base:  strings.ToUpper(strings.TrimSpace(p.Base)),
+			Quote: strings.ToUpper(strings.TrimSpace(p.Quote)),
+		}
+		if err := h.validator.ValidateCodes(res.Base, res.Quote); err != nil {
+			res.Error = err.Error()
+		} else {
+			valid = append(valid, domain.RatePair{Base: res.Base, Quote: res.Quote})
+		}
+		results = append(results, res)
+	}
+
+	if len(valid) > 0 {
+		updateIDs, err := h.service.ScheduleUpdates(r.Context(), valid)
+		if err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{"handler": "ScheduleUpdatesBatch", "pairs": len(valid)}).Error("updates weren't scheduled")
+			writeError(w, http.StatusInternalServerError, "failed to schedule rate updates")
+			return
+		}
+		for i := range results {
+			if results[i].Error != "" {
+				continue
+			}
+			updateID, ok := updateIDs[domain.RatePair{Base: results[i].Base, Quote: results[i].Quote}]
+			if !ok {
+				logrus.WithFields(logrus.Fields{"handler": "ScheduleUpdatesBatch", "base": results[i].Base, "quote": results[i].Quote}).Error("update wasn't scheduled")
+				writeError(w, http.StatusInternalServerError, "failed to schedule rate updates")
+				return
+			}
+			results[i].UpdateID = updateID.String()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(ScheduleUpdatesBatchResponse{Results: results})
+}
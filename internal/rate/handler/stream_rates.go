@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"fxrates/internal/domain"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxStreamPairs         = 50
+	defaultStreamHeartbeat = 15 * time.Second
+)
+
+type RateEvent struct {
+	UpdateID  string    `json:"update_id" example:"77b5d9f5-0569-47e3-aee2-f659d59fbd97"`
+	Base      string    `json:"base" example:"USD"`
+	Quote     string    `json:"quote" example:"EUR"`
+	Value     string    `json:"value" example:"0.92310000"`
+	Source    string    `json:"source,omitempty" example:"frankfurter"`
+	UpdatedAt time.Time `json:"updated_at" example:"2025-01-02T15:04:05Z"`
+}
+
+// StreamRates godoc
+// @Summary Stream applied rates
+// @Description Server-Sent Events stream of values applied for the given pairs. Every applied value is sent as a `rate` event
+// @Description with RateEvent JSON data and the update ID as event ID. Comment lines are sent as heartbeats to keep the connection alive
+// @Tags Rates
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Param pairs query string true "Comma-separated pairs, up to 50" example(USD/EUR,GBP/JPY)
+// @Success 200 {object} RateEvent
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 403 {object} errorResponse
+// @Router /rates/stream [get]
+func (h *Handler) StreamRates(w http.ResponseWriter, r *http.Request) {
+	pairs, err := h.parsePairs(r.URL.Query().Get("pairs"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	changes, unsubscribe := h.subscriber.Subscribe(pairs)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disables proxy buffering
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if _, err = fmt.Fprint(w, ": connected\n\n"); err == nil {
+		err = rc.Flush()
+	}
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{"handler": "StreamRates"}).Warn("ups, couldn't start streaming this time")
+		return
+	}
+
+	heartbeat := time.NewTicker(h.streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return // server is shutting down
+			}
+			data, _ := json.Marshal(RateEvent{
+				UpdateID:  change.UpdateID.String(),
+				Base:      change.Base,
+				Quote:     change.Quote,
+				Value:     formatRate(change.Value),
+				Source:    change.Source,
+				UpdatedAt: change.UpdatedAt,
+			})
+			_, err = fmt.Fprintf(w, "id: %s\nevent: rate\ndata: %s\n\n", change.UpdateID, data)
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return // client is gone
+		}
+	}
+}
+
+// parsePairs parses and validates comma-separated "BASE/QUOTE" pairs
+func (h *Handler) parsePairs(raw string) ([]domain.RatePair, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("'pairs' parameter is required")
+	}
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxStreamPairs {
+		return nil, fmt.Errorf("at most %d pairs can be streamed", maxStreamPairs)
+	}
+
+	pairs := make([]domain.RatePair, 0, len(parts))
+	for _, part := range parts {
+		base, quote, ok := strings.Cut(strings.TrimSpace(part), "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid pair %q, BASE/QUOTE expected", part)
+		}
+		base = strings.ToUpper(strings.TrimSpace(base))
+		quote = strings.ToUpper(strings.TrimSpace(quote))
+		if err := h.validator.ValidateCodes(base, quote); err != nil {
+			return nil, fmt.Errorf("invalid pair %q: %w", part, err)
+		}
+		pairs = append(pairs, domain.RatePair{Base: base, Quote: quote})
+	}
+	return pairs, nil
+}
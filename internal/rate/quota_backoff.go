@@ -0,0 +1,51 @@
+package rate
+
+import (
+	"sync"
+	"time"
+)
+
+// QuotaBackoff pauses upstream fetches of update runs once a provider reported its quota exhausted. The pause
+// starts at the initial duration and doubles with every run hitting the quota again, up to the max.
+// A run fetching successfully lifts it. Runs of other replicas keep their own backoff
+type QuotaBackoff struct {
+	initial time.Duration
+	max     time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	pause time.Duration
+	until time.Time
+}
+
+// pausedUntil returns the end of the current pause, false when fetches aren't paused
+func (b *QuotaBackoff) pausedUntil() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.until, b.now().Before(b.until)
+}
+
+// exhausted pauses fetches, doubling the previous pause
+func (b *QuotaBackoff) exhausted() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pause == 0 {
+		b.pause = b.initial
+	} else {
+		b.pause = min(b.pause*2, b.max)
+	}
+	b.until = b.now().Add(b.pause)
+	return b.until
+}
+
+// reset lifts the pause, the next exhausted quota starts from the initial duration again
+func (b *QuotaBackoff) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pause, b.until = 0, time.Time{}
+}
+
+// NewQuotaBackoff creates a backoff pausing fetches for initial up to maxPause, a maxPause below initial is raised to it
+func NewQuotaBackoff(initial, maxPause time.Duration) *QuotaBackoff {
+	return &QuotaBackoff{initial: initial, max: max(initial, maxPause), now: time.Now}
+}
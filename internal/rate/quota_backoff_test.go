@@ -0,0 +1,29 @@
+package rate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuotaBackoff_DoublesUpToMaxAndResets(t *testing.T) {
+	now := time.Now()
+	b := NewQuotaBackoff(time.Minute, 3*time.Minute)
+	b.now = func() time.Time { return now }
+
+	_, paused := b.pausedUntil()
+	require.False(t, paused)
+
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, 3 * time.Minute} {
+		require.Equal(t, now.Add(want), b.exhausted())
+		until, paused := b.pausedUntil()
+		require.True(t, paused)
+		require.Equal(t, now.Add(want), until)
+	}
+
+	b.reset()
+	_, paused = b.pausedUntil()
+	require.False(t, paused)
+	require.Equal(t, now.Add(time.Minute), b.exhausted())
+}
@@ -14,9 +14,19 @@ type Scheduler struct {
 	rateUpdateRepo adapters.RateUpdateRepository
 	rateClient     adapters.RateClient
 	cache          adapters.RateUpdateCache
+	publisher      adapters.RatePublisher
+	jobOpts        JobOptions
+	// callbacks delivery, disabled when callbackRepo is nil
+	callbackRepo   adapters.RateUpdateCallbackRepository
+	callbackSender adapters.CallbackSender
+	callbackOpts   CallbackOptions
+	// wakeups trigger an extra update run after the debounce window, disabled when nil
+	wakeups        <-chan struct{}
+	wakeupDebounce time.Duration
 	// -----
-	sched                  gocron.Scheduler
-	updateRatesJobDuration time.Duration
+	sched                       gocron.Scheduler
+	updateRatesJobDuration      time.Duration
+	deliverCallbacksJobDuration time.Duration
 }
 
 func (s *Scheduler) Start(ctx context.Context) error {
@@ -28,13 +38,13 @@ func (s *Scheduler) Start(ctx context.Context) error {
 
 	job := func(jobCtx context.Context) {
 		execID := uuid.NewString()
-		updErr := UpdatePendingRates(jobCtx, execID, s.rateUpdateRepo, s.rateClient, s.cache)
+		updErr := UpdatePendingRates(jobCtx, execID, s.rateUpdateRepo, s.rateClient, s.cache, s.publisher, s.jobOpts)
 		if updErr != nil {
 			logrus.Errorf("Update pending rates job %s failed: %v", execID, updErr)
 		}
 	}
 
-	_, err = scheduler.NewJob(
+	updateJob, err := scheduler.NewJob(
 		gocron.DurationJob(s.updateRatesJobDuration),
 		gocron.NewTask(job),
 		gocron.WithSingletonMode(gocron.LimitModeReschedule),
@@ -43,6 +53,26 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if s.wakeups != nil {
+		go s.runOnWakeups(ctx, updateJob)
+	}
+
+	if s.callbackRepo != nil {
+		callbacksJob := func(jobCtx context.Context) {
+			execID := uuid.NewString()
+			if deliverErr := DeliverCallbacks(jobCtx, execID, s.callbackRepo, s.callbackSender, s.callbackOpts); deliverErr != nil {
+				logrus.Errorf("Deliver callbacks job %s failed: %v", execID, deliverErr)
+			}
+		}
+		_, err = scheduler.NewJob(
+			gocron.DurationJob(s.deliverCallbacksJobDuration),
+			gocron.NewTask(callbacksJob),
+			gocron.WithSingletonMode(gocron.LimitModeReschedule),
+		)
+		if err != nil {
+			return err
+		}
+	}
 
 	scheduler.Start()
 
@@ -65,11 +95,70 @@ func (s *Scheduler) Shutdown() error {
 	return err
 }
 
+// WithCallbacks enables the job delivering callbacks of applied updates, must be called before Start
+func (s *Scheduler) WithCallbacks(
+	callbackRepo adapters.RateUpdateCallbackRepository,
+	callbackSender adapters.CallbackSender,
+	deliverCallbacksJobDuration time.Duration,
+	callbackOpts CallbackOptions,
+) *Scheduler {
+	if deliverCallbacksJobDuration <= 0 {
+		deliverCallbacksJobDuration = 2 * time.Second
+	}
+	s.callbackRepo = callbackRepo
+	s.callbackSender = callbackSender
+	s.deliverCallbacksJobDuration = deliverCallbacksJobDuration
+	s.callbackOpts = callbackOpts
+	return s
+}
+
+// WithWakeups makes every wakeup trigger an update run within the debounce window, so new updates don't wait
+// for the next periodic run; wakeups arriving within the window share the run. Must be called before Start
+func (s *Scheduler) WithWakeups(wakeups <-chan struct{}, debounce time.Duration) *Scheduler {
+	if debounce <= 0 {
+		debounce = 200 * time.Millisecond
+	}
+	s.wakeups = wakeups
+	s.wakeupDebounce = debounce
+	return s
+}
+
+// runOnWakeups runs the update job once the debounce window after a wakeup is over, until ctx is done.
+// A run still in progress makes the triggered one skipped, the periodic job stays the safety net
+func (s *Scheduler) runOnWakeups(ctx context.Context, updateJob gocron.Job) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wakeups:
+		}
+
+		timer := time.NewTimer(s.wakeupDebounce)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		// wakeups of the window are served by this run
+		select {
+		case <-s.wakeups:
+		default:
+		}
+
+		if err := updateJob.RunNow(); err != nil {
+			logrus.Warnf("Update pending rates job wasn't triggered by wakeup: %v", err)
+		}
+	}
+}
+
 func NewScheduler(
 	rateUpdatesRepo adapters.RateUpdateRepository,
 	rateClient adapters.RateClient,
 	cache adapters.RateUpdateCache,
+	publisher adapters.RatePublisher,
 	updateRatesJobDuration time.Duration,
+	jobOpts JobOptions,
 ) *Scheduler {
 	if updateRatesJobDuration <= 0 {
 		updateRatesJobDuration = 30 * time.Second
@@ -78,6 +167,8 @@ func NewScheduler(
 		rateUpdateRepo:         rateUpdatesRepo,
 		rateClient:             rateClient,
 		cache:                  cache,
+		publisher:              publisher,
 		updateRatesJobDuration: updateRatesJobDuration,
+		jobOpts:                jobOpts,
 	}
 }
@@ -12,20 +12,20 @@ import (
 )
 
 func TestNewScheduler_Constructs(t *testing.T) {
-	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, 10*time.Second)
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 10*time.Second, JobOptions{})
 	require.NotNil(t, s)
 	require.Nil(t, s.sched)
 }
 
 func TestScheduler_Shutdown_NoScheduler_ReturnsNil(t *testing.T) {
-	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, 10*time.Second)
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 10*time.Second, JobOptions{})
 	err := s.Shutdown()
 	require.NoError(t, err)
 	require.Nil(t, s.sched)
 }
 
 func TestScheduler_Start_And_ContextCancel_ShutsDown(t *testing.T) {
-	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, 10*time.Second)
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 10*time.Second, JobOptions{})
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Start scheduler
@@ -49,8 +49,8 @@ func TestScheduler_Start_And_ContextCancel_ShutsDown(t *testing.T) {
 
 func TestScheduler_Shutdown_AfterStart_Idempotent(t *testing.T) {
 	repo := new(MockRateUpdateRepository)
-	repo.On("GetPending", mock.Anything).Return([]domain.PendingRateUpdate{}, nil).Maybe()
-	s := NewScheduler(repo, new(MockRateClient), nil, 10*time.Second)
+	repo.On("ClaimPending", mock.Anything, mock.Anything, defaultClaimLease).Return([]domain.PendingRateUpdate{}, nil).Maybe()
+	s := NewScheduler(repo, new(MockRateClient), nil, nil, 10*time.Second, JobOptions{})
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -66,11 +66,75 @@ func TestScheduler_Shutdown_AfterStart_Idempotent(t *testing.T) {
 }
 
 func TestNewScheduler_UsesProvidedInterval(t *testing.T) {
-	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, 42*time.Second)
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 42*time.Second, JobOptions{})
 	require.Equal(t, 42*time.Second, s.updateRatesJobDuration)
 }
 
 func TestNewScheduler_DefaultsIntervalWhenInvalid(t *testing.T) {
-	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, 0)
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 0, JobOptions{})
 	require.Equal(t, 30*time.Second, s.updateRatesJobDuration)
 }
+
+func TestScheduler_WithCallbacks_StartsDeliveryJob(t *testing.T) {
+	callbackRepo := new(MockRateUpdateCallbackRepository)
+	delivered := make(chan struct{})
+	callbackRepo.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]domain.RateUpdateCallback{}, nil).Run(func(mock.Arguments) {
+		select {
+		case delivered <- struct{}{}:
+		default:
+		}
+	})
+	repo := new(MockRateUpdateRepository)
+	repo.On("ClaimPending", mock.Anything, mock.Anything, defaultClaimLease).Return([]domain.PendingRateUpdate{}, nil).Maybe()
+
+	s := NewScheduler(repo, new(MockRateClient), nil, nil, time.Hour, JobOptions{}).
+		WithCallbacks(callbackRepo, new(MockCallbackSender), 10*time.Millisecond, CallbackOptions{BatchSize: 10})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, s.Start(ctx))
+	defer func() { _ = s.Shutdown() }()
+
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected callbacks delivery job to run")
+	}
+}
+
+func TestScheduler_WithCallbacks_DefaultsIntervalWhenInvalid(t *testing.T) {
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 0, JobOptions{}).
+		WithCallbacks(new(MockRateUpdateCallbackRepository), new(MockCallbackSender), 0, CallbackOptions{})
+	require.Equal(t, 2*time.Second, s.deliverCallbacksJobDuration)
+}
+
+func TestScheduler_WithWakeups_RunsUpdateJobAfterDebounce(t *testing.T) {
+	claimed := make(chan struct{}, 10)
+	repo := new(MockRateUpdateRepository)
+	repo.On("ClaimPending", mock.Anything, mock.Anything, defaultClaimLease).Return([]domain.PendingRateUpdate{}, nil).Run(func(mock.Arguments) {
+		claimed <- struct{}{}
+	})
+	wakeups := make(chan struct{}, 1)
+
+	s := NewScheduler(repo, new(MockRateClient), nil, nil, time.Hour, JobOptions{}).
+		WithWakeups(wakeups, 50*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, s.Start(ctx))
+	defer func() { _ = s.Shutdown() }()
+
+	// the periodic run is an hour away
+	wakeups <- struct{}{}
+	select {
+	case <-claimed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected update job to run after wakeup")
+	}
+}
+
+func TestScheduler_WithWakeups_DefaultsDebounceWhenInvalid(t *testing.T) {
+	s := NewScheduler(new(MockRateUpdateRepository), new(MockRateClient), nil, nil, 0, JobOptions{}).
+		WithWakeups(make(chan struct{}), 0)
+	require.Equal(t, 200*time.Millisecond, s.wakeupDebounce)
+}
@@ -2,21 +2,93 @@ package rate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"fxrates/internal/adapters"
 	"fxrates/internal/domain"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Service struct {
 	rateUpdatesRepo adapters.RateUpdateRepository
 	rateRepo        adapters.RateRepository
 	cache           adapters.RateUpdateCache
+	callbackRepo    adapters.RateUpdateCallbackRepository
+	pivotCurrency   string
+	minorUnits      func(code string) int
+	// privateCallbacks lets callback URLs point to loopback and private networks, e.g. for local development
+	privateCallbacks bool
 }
 
-// ScheduleUpdate checks if pair presents in cache first, otherwise goes to DB
-func (s *Service) ScheduleUpdate(ctx context.Context, base string, quote string) (uuid.UUID, error) {
+// ScheduleUpdate checks if pair presents in cache first, otherwise goes to DB.
+// Non-empty callbackURL is registered to be called once the update is applied
+func (s *Service) ScheduleUpdate(ctx context.Context, base string, quote string, callbackURL string) (_ uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ScheduleUpdate", pairAttributes(base, quote))
+	defer func() { endSpan(span, err) }()
+
+	if callbackURL != "" {
+		if s.callbackRepo == nil {
+			return uuid.Nil, ErrCallbacksDisabled
+		}
+		if err := validateCallbackURL(ctx, callbackURL, s.privateCallbacks); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	updateID, err := s.scheduleUpdate(ctx, base, quote)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if callbackURL != "" {
+		if err = s.callbackRepo.Register(ctx, updateID, callbackURL); err != nil {
+			return uuid.Nil, err
+		}
+	}
+	return updateID, nil
+}
+
+// ScheduleUpdates schedules many pairs at once: cached pairs are served from cache and the rest goes to DB in one round trip
+func (s *Service) ScheduleUpdates(ctx context.Context, pairs []domain.RatePair) (_ map[domain.RatePair]uuid.UUID, err error) {
+	ctx, span := tracer.Start(ctx, "Service.ScheduleUpdates", trace.WithAttributes(attribute.Int("fx.pairs", len(pairs))))
+	defer func() { endSpan(span, err) }()
+
+	updateIDs := make(map[domain.RatePair]uuid.UUID, len(pairs))
+	missing := make([]domain.RatePair, 0, len(pairs))
+	seen := make(map[domain.RatePair]struct{}, len(pairs))
+	for _, pair := range pairs {
+		if _, ok := seen[pair]; ok {
+			continue // repo requires unique pairs
+		}
+		seen[pair] = struct{}{}
+		if cachedID, ok := s.cache.Get(pair); ok {
+			updateIDs[pair] = cachedID
+			continue
+		}
+		missing = append(missing, pair)
+	}
+	span.SetAttributes(attribute.Int("fx.cache_misses", len(missing)))
+	if len(missing) == 0 {
+		return updateIDs, nil
+	}
+
+	scheduled, err := s.rateUpdatesRepo.ScheduleNewOrGetExistingBatch(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for pair, updateID := range scheduled {
+		s.cache.Set(pair, updateID)
+		updateIDs[pair] = updateID
+	}
+	return updateIDs, nil
+}
+
+func (s *Service) scheduleUpdate(ctx context.Context, base string, quote string) (uuid.UUID, error) {
 	pair := domain.RatePair{Base: base, Quote: quote}
 	if cachedID, ok := s.cache.Get(pair); ok {
 		return cachedID, nil
@@ -32,7 +104,10 @@ func (s *Service) ScheduleUpdate(ctx context.Context, base string, quote string)
 }
 
 // GetByUpdateID defines View structure depending on update status and returns it
-func (s *Service) GetByUpdateID(ctx context.Context, updateID uuid.UUID) (View, error) {
+func (s *Service) GetByUpdateID(ctx context.Context, updateID uuid.UUID) (_ View, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetByUpdateID", trace.WithAttributes(attribute.String("fx.update_id", updateID.String())))
+	defer func() { endSpan(span, err) }()
+
 	rate, status, err := s.rateRepo.GetByUpdateID(ctx, updateID)
 	if err != nil {
 		return View{}, err
@@ -45,30 +120,160 @@ func (s *Service) GetByUpdateID(ctx context.Context, updateID uuid.UUID) (View,
 			Status:    status,
 			Value:     &rate.Value,     // never nil (DB constraint)
 			UpdatedAt: &rate.UpdatedAt, // never nil (DB constraint)
+			Source:    rate.Source,
+			Quotes:    rate.Quotes,
 		}, nil
 	case domain.StatusPending:
 		return View{
-			Base:   rate.Base,
-			Quote:  rate.Quote,
-			Status: status,
+			Base:     rate.Base,
+			Quote:    rate.Quote,
+			Status:   status,
+			Attempts: rate.Attempts,
+		}, nil
+	case domain.StatusFailed, domain.StatusExpired:
+		return View{
+			Base:      rate.Base,
+			Quote:     rate.Quote,
+			Status:    status,
+			UpdatedAt: &rate.UpdatedAt,
+			Attempts:  rate.Attempts,
+			Reason:    rate.Reason,
 		}, nil
 	default:
 		return View{}, fmt.Errorf("unknown rate update status: %q", status)
 	}
 }
 
-func (s *Service) GetByCodes(ctx context.Context, base string, quote string) (View, error) {
+// GetByCodes returns the stored last rate of the pair, falling back to the reversed pair. When both are missing and pivot
+// currency is configured, the rate is derived through the pivot and the view is marked as derived with the legs used
+func (s *Service) GetByCodes(ctx context.Context, base string, quote string) (_ View, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetByCodes", pairAttributes(base, quote))
+	defer func() { endSpan(span, err) }()
+
+	view, err := s.lookupRate(ctx, base, quote)
+	if errors.Is(err, domain.ErrRateNotFound) && s.canTriangulate(base, quote) {
+		return s.triangulate(ctx, base, quote)
+	}
+	if err != nil {
+		return View{}, err
+	}
+	return view, nil
+}
+
+// Convert converts amount using the stored last rate of from/to pair, falling back to the reversed pair
+// and then to the pivot currency. The result is rounded half away from zero to the minor units of the target currency
+func (s *Service) Convert(ctx context.Context, from string, to string, amount decimal.Decimal) (_ ConversionView, err error) {
+	ctx, span := tracer.Start(ctx, "Service.Convert", pairAttributes(from, to))
+	defer func() { endSpan(span, err) }()
+
+	view, err := s.lookupRate(ctx, from, to)
+	if errors.Is(err, domain.ErrRateNotFound) && s.canTriangulate(from, to) {
+		view, err = s.triangulate(ctx, from, to)
+	}
+	if err != nil {
+		return ConversionView{}, err
+	}
+
+	minorUnits := s.minorUnits(to)
+	return ConversionView{
+		From:       from,
+		To:         to,
+		Amount:     amount,
+		Result:     amount.Mul(*view.Value).Round(int32(minorUnits)),
+		MinorUnits: minorUnits,
+		Rate:       *view.Value,
+		UpdatedAt:  *view.UpdatedAt,
+		Derived:    view.Derived,
+		Legs:       view.Legs,
+	}, nil
+}
+
+// lookupRate returns the stored base/quote rate, inverting the reversed pair when the direct one is missing
+func (s *Service) lookupRate(ctx context.Context, base string, quote string) (View, error) {
 	rate, err := s.rateRepo.GetByCodes(ctx, base, quote)
+	if err == nil {
+		return View{Base: rate.Base, Quote: rate.Quote, Value: &rate.Value, UpdatedAt: &rate.UpdatedAt}, nil
+	}
+	if !errors.Is(err, domain.ErrRateNotFound) {
+		return View{}, err
+	}
+
+	rate, err = s.rateRepo.GetByCodes(ctx, quote, base)
+	if err != nil {
+		return View{}, err
+	}
+	if !rate.Value.IsPositive() {
+		return View{}, fmt.Errorf("invalid stored rate %s for pair %q/%q", rate.Value, rate.Base, rate.Quote)
+	}
+	value := domain.InverseRate(rate.Value)
+	return View{Base: base, Quote: quote, Value: &value, UpdatedAt: &rate.UpdatedAt}, nil
+}
+
+func (s *Service) canTriangulate(base string, quote string) bool {
+	return s.pivotCurrency != "" && base != s.pivotCurrency && quote != s.pivotCurrency
+}
+
+// triangulate derives base/quote rate as base/pivot * pivot/quote rounded to the rate scale. The oldest leg defines the update time
+func (s *Service) triangulate(ctx context.Context, base string, quote string) (View, error) {
+	first, err := s.lookupRate(ctx, base, s.pivotCurrency)
 	if err != nil {
 		return View{}, err
 	}
-	return View{Base: rate.Base, Quote: rate.Quote, Value: &rate.Value, UpdatedAt: &rate.UpdatedAt}, nil
+	second, err := s.lookupRate(ctx, s.pivotCurrency, quote)
+	if err != nil {
+		return View{}, err
+	}
+
+	value := first.Value.Mul(*second.Value).Round(domain.RateScale)
+	updatedAt := *first.UpdatedAt
+	if second.UpdatedAt.Before(updatedAt) {
+		updatedAt = *second.UpdatedAt
+	}
+	return View{
+		Base:      base,
+		Quote:     quote,
+		Value:     &value,
+		UpdatedAt: &updatedAt,
+		Derived:   true,
+		Legs:      []View{first, second},
+	}, nil
+}
+
+// GetHistory returns ordered history points of the pair within [from, to)
+func (s *Service) GetHistory(ctx context.Context, base string, quote string, from time.Time, to time.Time, interval time.Duration) (_ []domain.RateHistoryPoint, err error) {
+	ctx, span := tracer.Start(ctx, "Service.GetHistory", pairAttributes(base, quote))
+	defer func() { endSpan(span, err) }()
+
+	return s.rateRepo.GetHistory(ctx, base, quote, from, to, interval)
 }
 
-func NewService(rateUpdatesRepo adapters.RateUpdateRepository, rateRepo adapters.RateRepository, cache adapters.RateUpdateCache) *Service {
+// NewService creates rate service. Nil callbackRepo disables callbacks, empty pivotCurrency disables triangulation of missing pairs
+func NewService(
+	rateUpdatesRepo adapters.RateUpdateRepository,
+	rateRepo adapters.RateRepository,
+	cache adapters.RateUpdateCache,
+	callbackRepo adapters.RateUpdateCallbackRepository,
+	pivotCurrency string,
+) *Service {
 	return &Service{
 		rateUpdatesRepo: rateUpdatesRepo,
 		rateRepo:        rateRepo,
 		cache:           cache,
+		callbackRepo:    callbackRepo,
+		pivotCurrency:   pivotCurrency,
+		minorUnits:      domain.MinorUnits,
 	}
 }
+
+// WithPrivateCallbackURLs lets callback URLs point to loopback and private networks, callbacks must be sent through
+// a client allowing them too
+func (s *Service) WithPrivateCallbackURLs() *Service {
+	s.privateCallbacks = true
+	return s
+}
+
+// WithMinorUnits makes conversions round to minor units of the given lookup instead of ISO 4217 defaults
+func (s *Service) WithMinorUnits(minorUnits func(code string) int) *Service {
+	s.minorUnits = minorUnits
+	return s
+}
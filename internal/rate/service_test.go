@@ -23,14 +23,35 @@ func (m *MockRateUpdateRepository) ScheduleNewOrGetExisting(ctx context.Context,
 	return id, args.Error(1)
 }
 
-func (m *MockRateUpdateRepository) GetPending(ctx context.Context) ([]domain.PendingRateUpdate, error) {
-	args := m.Called(ctx)
+func (m *MockRateUpdateRepository) ScheduleNewOrGetExistingBatch(ctx context.Context, pairs []domain.RatePair) (map[domain.RatePair]uuid.UUID, error) {
+	args := m.Called(ctx, pairs)
+	ids, _ := args.Get(0).(map[domain.RatePair]uuid.UUID)
+	return ids, args.Error(1)
+}
+
+func (m *MockRateUpdateRepository) ClaimPending(ctx context.Context, claimID string, lease time.Duration) ([]domain.PendingRateUpdate, error) {
+	args := m.Called(ctx, claimID, lease)
 	updates, _ := args.Get(0).([]domain.PendingRateUpdate)
 	return updates, args.Error(1)
 }
 
-func (m *MockRateUpdateRepository) ApplyUpdates(ctx context.Context, rates []domain.AppliedRateUpdate) error {
-	args := m.Called(ctx, rates)
+func (m *MockRateUpdateRepository) ApplyUpdates(ctx context.Context, claimID string, rates []domain.AppliedRateUpdate) error {
+	args := m.Called(ctx, claimID, rates)
+	return args.Error(0)
+}
+
+func (m *MockRateUpdateRepository) IncrementAttempts(ctx context.Context, claimID string, updateIDs []uuid.UUID) error {
+	args := m.Called(ctx, claimID, updateIDs)
+	return args.Error(0)
+}
+
+func (m *MockRateUpdateRepository) ReleaseClaims(ctx context.Context, claimID string, updateIDs []uuid.UUID) error {
+	args := m.Called(ctx, claimID, updateIDs)
+	return args.Error(0)
+}
+
+func (m *MockRateUpdateRepository) CloseUpdates(ctx context.Context, claimID string, closed []domain.ClosedRateUpdate) error {
+	args := m.Called(ctx, claimID, closed)
 	return args.Error(0)
 }
 
@@ -49,6 +70,12 @@ func (m *MockRateRepository) GetByUpdateID(ctx context.Context, updateID uuid.UU
 	return r, status, args.Error(2)
 }
 
+func (m *MockRateRepository) GetHistory(ctx context.Context, base string, quote string, from time.Time, to time.Time, interval time.Duration) ([]domain.RateHistoryPoint, error) {
+	args := m.Called(ctx, base, quote, from, to, interval)
+	points, _ := args.Get(0).([]domain.RateHistoryPoint)
+	return points, args.Error(1)
+}
+
 type MockRateUpdateCache struct{ mock.Mock }
 
 func (m *MockRateUpdateCache) Get(pair domain.RatePair) (uuid.UUID, bool) {
@@ -71,7 +98,7 @@ func TestService_ScheduleUpdate_Success(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
 	mockCache := new(MockRateUpdateCache)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, mockCache)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, mockCache, nil, "")
 
 	ctx := context.Background()
 	updateID := uuid.New()
@@ -81,7 +108,7 @@ func TestService_ScheduleUpdate_Success(t *testing.T) {
 	mockUpdatesRepo.On("ScheduleNewOrGetExisting", mock.Anything, "USD", "EUR").Return(updateID, nil).Once()
 	mockCache.On("Set", pair, updateID).Return().Once()
 
-	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR")
+	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR", "")
 
 	require.NoError(t, err)
 	require.Equal(t, updateID, id)
@@ -94,7 +121,7 @@ func TestService_ScheduleUpdate_Error(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
 	mockCache := new(MockRateUpdateCache)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, mockCache)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, mockCache, nil, "")
 
 	ctx := context.Background()
 	wantErr := errors.New("db temporarily unavailable")
@@ -103,7 +130,7 @@ func TestService_ScheduleUpdate_Error(t *testing.T) {
 	mockCache.On("Get", pair).Return(uuid.Nil, false).Once()
 	mockUpdatesRepo.On("ScheduleNewOrGetExisting", mock.Anything, "USD", "EUR").Return(uuid.Nil, wantErr).Once()
 
-	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR")
+	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR", "")
 
 	require.Error(t, err)
 	require.Equal(t, uuid.Nil, id)
@@ -117,7 +144,7 @@ func TestService_ScheduleUpdate_UsesCacheHit(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
 	mockCache := new(MockRateUpdateCache)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, mockCache)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, mockCache, nil, "")
 
 	ctx := context.Background()
 	updateID := uuid.New()
@@ -125,7 +152,7 @@ func TestService_ScheduleUpdate_UsesCacheHit(t *testing.T) {
 
 	mockCache.On("Get", pair).Return(updateID, true).Once()
 
-	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR")
+	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR", "")
 
 	require.NoError(t, err)
 	require.Equal(t, updateID, id)
@@ -133,17 +160,135 @@ func TestService_ScheduleUpdate_UsesCacheHit(t *testing.T) {
 	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
 }
 
+func TestService_ScheduleUpdate_RegistersCallback(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockCache := new(MockRateUpdateCache)
+	mockCallbackRepo := new(MockRateUpdateCallbackRepository)
+	svc := NewService(mockUpdatesRepo, new(MockRateRepository), mockCache, mockCallbackRepo, "")
+
+	ctx := context.Background()
+	updateID := uuid.New()
+	pair := domain.RatePair{Base: "USD", Quote: "EUR"}
+
+	mockCache.On("Get", pair).Return(updateID, true).Once()
+	mockCallbackRepo.On("Register", mock.Anything, updateID, "https://pricing.example.com/hooks/fx").Return(nil).Once()
+
+	id, err := svc.ScheduleUpdate(ctx, "USD", "EUR", "https://pricing.example.com/hooks/fx")
+
+	require.NoError(t, err)
+	require.Equal(t, updateID, id)
+	mockCallbackRepo.AssertExpectations(t)
+}
+
+func TestService_ScheduleUpdate_RegisterCallbackError(t *testing.T) {
+	mockCache := new(MockRateUpdateCache)
+	mockCallbackRepo := new(MockRateUpdateCallbackRepository)
+	svc := NewService(new(MockRateUpdateRepository), new(MockRateRepository), mockCache, mockCallbackRepo, "")
+
+	updateID := uuid.New()
+	wantErr := errors.New("db temporarily unavailable")
+	mockCache.On("Get", domain.RatePair{Base: "USD", Quote: "EUR"}).Return(updateID, true).Once()
+	mockCallbackRepo.On("Register", mock.Anything, updateID, "https://pricing.example.com").Return(wantErr).Once()
+
+	id, err := svc.ScheduleUpdate(context.Background(), "USD", "EUR", "https://pricing.example.com")
+
+	require.ErrorIs(t, err, wantErr)
+	require.Equal(t, uuid.Nil, id)
+}
+
+func TestService_ScheduleUpdate_InvalidCallbackURL_NotScheduled(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockCache := new(MockRateUpdateCache)
+	mockCallbackRepo := new(MockRateUpdateCallbackRepository)
+	svc := NewService(mockUpdatesRepo, new(MockRateRepository), mockCache, mockCallbackRepo, "")
+
+	_, err := svc.ScheduleUpdate(context.Background(), "USD", "EUR", "ftp://pricing.example.com")
+
+	require.ErrorIs(t, err, ErrCallbackURLInvalid)
+	mockCache.AssertNotCalled(t, "Get", mock.Anything)
+	mockUpdatesRepo.AssertNotCalled(t, "ScheduleNewOrGetExisting", mock.Anything, mock.Anything, mock.Anything)
+	mockCallbackRepo.AssertNotCalled(t, "Register", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_ScheduleUpdate_CallbacksDisabled(t *testing.T) {
+	mockCache := new(MockRateUpdateCache)
+	svc := NewService(new(MockRateUpdateRepository), new(MockRateRepository), mockCache, nil, "")
+
+	_, err := svc.ScheduleUpdate(context.Background(), "USD", "EUR", "https://pricing.example.com")
+
+	require.ErrorIs(t, err, ErrCallbacksDisabled)
+	mockCache.AssertNotCalled(t, "Get", mock.Anything)
+}
+
+func TestService_ScheduleUpdates_CachedAndMissingInOneCall(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockCache := new(MockRateUpdateCache)
+	svc := NewService(mockUpdatesRepo, new(MockRateRepository), mockCache, nil, "")
+
+	usdEUR := domain.RatePair{Base: "USD", Quote: "EUR"}
+	gbpJPY := domain.RatePair{Base: "GBP", Quote: "JPY"}
+	mxnCAD := domain.RatePair{Base: "MXN", Quote: "CAD"}
+	cachedID, gbpID, mxnID := uuid.New(), uuid.New(), uuid.New()
+
+	mockCache.On("Get", usdEUR).Return(cachedID, true).Once()
+	mockCache.On("Get", gbpJPY).Return(uuid.Nil, false).Once()
+	mockCache.On("Get", mxnCAD).Return(uuid.Nil, false).Once()
+	mockUpdatesRepo.On("ScheduleNewOrGetExistingBatch", mock.Anything, []domain.RatePair{gbpJPY, mxnCAD}).
+		Return(map[domain.RatePair]uuid.UUID{gbpJPY: gbpID, mxnCAD: mxnID}, nil).Once()
+	mockCache.On("Set", gbpJPY, gbpID).Return().Once()
+	mockCache.On("Set", mxnCAD, mxnID).Return().Once()
+
+	ids, err := svc.ScheduleUpdates(context.Background(), []domain.RatePair{usdEUR, gbpJPY, mxnCAD, gbpJPY})
+
+	require.NoError(t, err)
+	require.Equal(t, map[domain.RatePair]uuid.UUID{usdEUR: cachedID, gbpJPY: gbpID, mxnCAD: mxnID}, ids)
+	mockUpdatesRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestService_ScheduleUpdates_AllCached_NoDBCall(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockCache := new(MockRateUpdateCache)
+	svc := NewService(mockUpdatesRepo, new(MockRateRepository), mockCache, nil, "")
+
+	usdEUR := domain.RatePair{Base: "USD", Quote: "EUR"}
+	cachedID := uuid.New()
+	mockCache.On("Get", usdEUR).Return(cachedID, true).Once()
+
+	ids, err := svc.ScheduleUpdates(context.Background(), []domain.RatePair{usdEUR})
+
+	require.NoError(t, err)
+	require.Equal(t, map[domain.RatePair]uuid.UUID{usdEUR: cachedID}, ids)
+	mockUpdatesRepo.AssertNotCalled(t, "ScheduleNewOrGetExistingBatch", mock.Anything, mock.Anything)
+}
+
+func TestService_ScheduleUpdates_RepoError(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockCache := new(MockRateUpdateCache)
+	svc := NewService(mockUpdatesRepo, new(MockRateRepository), mockCache, nil, "")
+
+	wantErr := errors.New("db temporarily unavailable")
+	mockCache.On("Get", mock.Anything).Return(uuid.Nil, false)
+	mockUpdatesRepo.On("ScheduleNewOrGetExistingBatch", mock.Anything, mock.Anything).Return(nil, wantErr).Once()
+
+	_, err := svc.ScheduleUpdates(context.Background(), []domain.RatePair{{Base: "USD", Quote: "EUR"}})
+
+	require.ErrorIs(t, err, wantErr)
+	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
+}
+
 // --- GetByUpdateID ---
 
 func TestService_GetByUpdateID_StatusApplied(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, nil)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
 
 	ctx := context.Background()
 	updateID := uuid.New()
 	fixedTime := time.Date(2024, 11, 15, 10, 9, 8, 0, time.UTC)
-	rate := domain.Rate{Base: "USD", Quote: "EUR", Value: 1.2345, UpdatedAt: fixedTime}
+	quotes := []domain.ProviderQuote{{Provider: "open_er_api", Value: dec("1.2345"), Accepted: true}}
+	rate := domain.Rate{Base: "USD", Quote: "EUR", Value: dec("1.2345"), UpdatedAt: fixedTime, Source: "open_er_api", Quotes: quotes}
 
 	mockRateRepo.On("GetByUpdateID", mock.Anything, updateID).Return(rate, domain.StatusApplied, nil).Once()
 
@@ -154,9 +299,11 @@ func TestService_GetByUpdateID_StatusApplied(t *testing.T) {
 	require.Equal(t, "EUR", view.Quote)
 	require.Equal(t, domain.StatusApplied, view.Status)
 	require.NotNil(t, view.Value)
-	require.InDelta(t, 1.2345, *view.Value, 1e-9)
+	requireDecimal(t, "1.2345", *view.Value)
 	require.NotNil(t, view.UpdatedAt)
 	require.True(t, view.UpdatedAt.Equal(fixedTime))
+	require.Equal(t, "open_er_api", view.Source)
+	require.Equal(t, quotes, view.Quotes)
 	mockRateRepo.AssertExpectations(t)
 	mockUpdatesRepo.AssertExpectations(t)
 }
@@ -164,7 +311,7 @@ func TestService_GetByUpdateID_StatusApplied(t *testing.T) {
 func TestService_GetByUpdateID_StatusPending(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, nil)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
 
 	ctx := context.Background()
 	updateID := uuid.New()
@@ -184,10 +331,35 @@ func TestService_GetByUpdateID_StatusPending(t *testing.T) {
 	mockUpdatesRepo.AssertExpectations(t)
 }
 
+func TestService_GetByUpdateID_StatusExpired(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
+
+	ctx := context.Background()
+	updateID := uuid.New()
+	fixedTime := time.Date(2024, 11, 15, 10, 9, 8, 0, time.UTC)
+	rate := domain.Rate{Base: "GBP", Quote: "JPY", UpdatedAt: fixedTime, Attempts: 3, Reason: "rate wasn't fetched within 1h0m0s"}
+
+	mockRateRepo.On("GetByUpdateID", mock.Anything, updateID).Return(rate, domain.StatusExpired, nil).Once()
+
+	view, err := svc.GetByUpdateID(ctx, updateID)
+
+	require.NoError(t, err)
+	require.Equal(t, domain.StatusExpired, view.Status)
+	require.Nil(t, view.Value)
+	require.NotNil(t, view.UpdatedAt)
+	require.True(t, view.UpdatedAt.Equal(fixedTime))
+	require.Equal(t, 3, view.Attempts)
+	require.Equal(t, "rate wasn't fetched within 1h0m0s", view.Reason)
+	mockRateRepo.AssertExpectations(t)
+	mockUpdatesRepo.AssertExpectations(t)
+}
+
 func TestService_GetByUpdateID_UnknownStatus(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, nil)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
 
 	ctx := context.Background()
 	updateID := uuid.New()
@@ -207,7 +379,7 @@ func TestService_GetByUpdateID_UnknownStatus(t *testing.T) {
 func TestService_GetByUpdateID_RepoError(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, nil)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
 
 	ctx := context.Background()
 	updateID := uuid.New()
@@ -228,11 +400,11 @@ func TestService_GetByUpdateID_RepoError(t *testing.T) {
 func TestService_GetByCodes_Success(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, nil)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
 
 	ctx := context.Background()
 	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
-	rate := domain.Rate{Base: "USD", Quote: "CHF", Value: 0.915, UpdatedAt: fixedTime}
+	rate := domain.Rate{Base: "USD", Quote: "CHF", Value: dec("0.915"), UpdatedAt: fixedTime}
 
 	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "CHF").Return(rate, nil).Once()
 
@@ -242,7 +414,7 @@ func TestService_GetByCodes_Success(t *testing.T) {
 	require.Equal(t, "USD", view.Base)
 	require.Equal(t, "CHF", view.Quote)
 	require.NotNil(t, view.Value)
-	require.InDelta(t, 0.915, *view.Value, 1e-9)
+	requireDecimal(t, "0.915", *view.Value)
 	require.NotNil(t, view.UpdatedAt)
 	require.True(t, view.UpdatedAt.Equal(fixedTime))
 	mockRateRepo.AssertExpectations(t)
@@ -252,12 +424,13 @@ func TestService_GetByCodes_Success(t *testing.T) {
 func TestService_GetByCodes_Error(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockRateRepo := new(MockRateRepository)
-	svc := NewService(mockUpdatesRepo, mockRateRepo, nil)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
 
 	ctx := context.Background()
 	wantErr := domain.ErrRateNotFound
 
 	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "PLN").Return(domain.Rate{}, wantErr).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "PLN", "USD").Return(domain.Rate{}, wantErr).Once()
 
 	_, err := svc.GetByCodes(ctx, "USD", "PLN")
 
@@ -266,3 +439,255 @@ func TestService_GetByCodes_Error(t *testing.T) {
 	mockRateRepo.AssertExpectations(t)
 	mockUpdatesRepo.AssertExpectations(t)
 }
+
+func TestService_GetByCodes_ReversedPair_NotTriangulated(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "USD")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "MXN", "JPY").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "JPY", "MXN").
+		Return(domain.Rate{Base: "JPY", Quote: "MXN", Value: dec("0.125"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.GetByCodes(context.Background(), "MXN", "JPY")
+
+	require.NoError(t, err)
+	require.False(t, view.Derived)
+	require.Equal(t, "MXN", view.Base)
+	require.Equal(t, "JPY", view.Quote)
+	requireDecimal(t, "8", *view.Value)
+	require.True(t, view.UpdatedAt.Equal(fixedTime))
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_GetByCodes_Triangulates_ThroughPivot(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "USD")
+
+	older := time.Date(2024, 10, 1, 11, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "MXN", "JPY").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "JPY", "MXN").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "MXN", "USD").
+		Return(domain.Rate{Base: "MXN", Quote: "USD", Value: dec("0.05"), UpdatedAt: newer}, nil).Once()
+	// second leg is only stored reversed
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "JPY").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "JPY", "USD").
+		Return(domain.Rate{Base: "JPY", Quote: "USD", Value: dec("0.0064"), UpdatedAt: older}, nil).Once()
+
+	view, err := svc.GetByCodes(context.Background(), "MXN", "JPY")
+
+	require.NoError(t, err)
+	require.True(t, view.Derived)
+	require.Equal(t, "MXN", view.Base)
+	require.Equal(t, "JPY", view.Quote)
+	requireDecimal(t, "7.8125", *view.Value) // 0.05 * 156.25
+	require.True(t, view.UpdatedAt.Equal(older))
+	require.Len(t, view.Legs, 2)
+	require.Equal(t, "MXN", view.Legs[0].Base)
+	require.Equal(t, "USD", view.Legs[0].Quote)
+	require.Equal(t, "USD", view.Legs[1].Base)
+	require.Equal(t, "JPY", view.Legs[1].Quote)
+	requireDecimal(t, "156.25", *view.Legs[1].Value)
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_GetByCodes_PivotPair_NotTriangulated(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "USD")
+
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "JPY").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "JPY", "USD").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+
+	_, err := svc.GetByCodes(context.Background(), "USD", "JPY")
+
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_GetByCodes_MissingLeg_NotFound(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "USD")
+
+	mockRateRepo.On("GetByCodes", mock.Anything, "MXN", "JPY").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "JPY", "MXN").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "MXN", "USD").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "MXN").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+
+	_, err := svc.GetByCodes(context.Background(), "MXN", "JPY")
+
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+	mockRateRepo.AssertExpectations(t)
+}
+
+// --- Convert ---
+
+func TestService_Convert_DirectPair_RoundsToTargetMinorUnits(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "EUR").
+		Return(domain.Rate{Base: "USD", Quote: "EUR", Value: dec("0.9231"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "USD", "EUR", dec("125.50"))
+
+	require.NoError(t, err)
+	require.Equal(t, "USD", view.From)
+	require.Equal(t, "EUR", view.To)
+	requireDecimal(t, "0.9231", view.Rate)
+	requireDecimal(t, "115.85", view.Result) // 115.84905 -> 115.85
+	require.True(t, view.UpdatedAt.Equal(fixedTime))
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_WithMinorUnits_UsesLookup(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "").
+		WithMinorUnits(func(code string) int { return 3 })
+
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "EUR").
+		Return(domain.Rate{Base: "USD", Quote: "EUR", Value: dec("0.9231"), UpdatedAt: time.Now()}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "USD", "EUR", dec("125.50"))
+
+	require.NoError(t, err)
+	requireDecimal(t, "115.849", view.Result) // 115.84905 -> 115.849
+	require.Equal(t, 3, view.MinorUnits)
+}
+
+func TestService_Convert_ReversedPair_UsesInverse(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "JPY").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "JPY", "USD").
+		Return(domain.Rate{Base: "JPY", Quote: "USD", Value: dec("0.0064"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "USD", "JPY", dec("10"))
+
+	require.NoError(t, err)
+	requireDecimal(t, "156.25", view.Rate)
+	requireDecimal(t, "1563.0", view.Result) // JPY has no minor units: 1562.5 -> 1563
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_ExactHalfRoundsAwayFromZero(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "EUR").
+		Return(domain.Rate{Base: "USD", Quote: "EUR", Value: dec("1.005"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "USD", "EUR", dec("1"))
+
+	require.NoError(t, err)
+	requireDecimal(t, "1.01", view.Result) // binary floating point would give 1.00
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_ReversedPair_InverseRoundedToRateScale(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "EUR").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "EUR", "USD").
+		Return(domain.Rate{Base: "EUR", Quote: "USD", Value: dec("3"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "USD", "EUR", dec("3"))
+
+	require.NoError(t, err)
+	require.Equal(t, "0.33333333", view.Rate.String())
+	requireDecimal(t, "1", view.Result)
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_PrefersReversedOverTriangulation(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "USD")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "EUR", "GBP").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "GBP", "EUR").
+		Return(domain.Rate{Base: "GBP", Quote: "EUR", Value: dec("1.25"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "EUR", "GBP", dec("100"))
+
+	require.NoError(t, err)
+	require.False(t, view.Derived)
+	requireDecimal(t, "0.8", view.Rate)
+	requireDecimal(t, "80.0", view.Result)
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_Triangulated(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "USD")
+
+	fixedTime := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mockRateRepo.On("GetByCodes", mock.Anything, "EUR", "GBP").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "GBP", "EUR").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "EUR", "USD").
+		Return(domain.Rate{Base: "EUR", Quote: "USD", Value: dec("1.1"), UpdatedAt: fixedTime}, nil).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "GBP").
+		Return(domain.Rate{Base: "USD", Quote: "GBP", Value: dec("0.8"), UpdatedAt: fixedTime}, nil).Once()
+
+	view, err := svc.Convert(context.Background(), "EUR", "GBP", dec("10"))
+
+	require.NoError(t, err)
+	require.True(t, view.Derived)
+	require.Len(t, view.Legs, 2)
+	requireDecimal(t, "0.88", view.Rate)
+	requireDecimal(t, "8.8", view.Result)
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_NotFound(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "")
+
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "CAD").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+	mockRateRepo.On("GetByCodes", mock.Anything, "CAD", "USD").Return(domain.Rate{}, domain.ErrRateNotFound).Once()
+
+	_, err := svc.Convert(context.Background(), "USD", "CAD", dec("10"))
+
+	require.ErrorIs(t, err, domain.ErrRateNotFound)
+	mockRateRepo.AssertExpectations(t)
+}
+
+func TestService_Convert_RepoError_NoFallback(t *testing.T) {
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(new(MockRateUpdateRepository), mockRateRepo, nil, nil, "")
+
+	wantErr := errors.New("db down")
+	mockRateRepo.On("GetByCodes", mock.Anything, "USD", "CAD").Return(domain.Rate{}, wantErr).Once()
+
+	_, err := svc.Convert(context.Background(), "USD", "CAD", dec("10"))
+
+	require.Equal(t, wantErr, err)
+	mockRateRepo.AssertNotCalled(t, "GetByCodes", mock.Anything, "CAD", "USD")
+}
+
+// --- GetHistory ---
+
+func TestService_GetHistory_DelegatesToRepo(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockRateRepo := new(MockRateRepository)
+	svc := NewService(mockUpdatesRepo, mockRateRepo, nil, nil, "")
+
+	ctx := context.Background()
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	points := []domain.RateHistoryPoint{{Value: dec("0.91"), RecordedAt: from.Add(time.Hour)}}
+
+	mockRateRepo.On("GetHistory", mock.Anything, "USD", "CHF", from, to, time.Hour).Return(points, nil).Once()
+
+	got, err := svc.GetHistory(ctx, "USD", "CHF", from, to, time.Hour)
+
+	require.NoError(t, err)
+	require.Equal(t, points, got)
+	mockRateRepo.AssertExpectations(t)
+}
@@ -0,0 +1,24 @@
+package rate
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer resolves the global provider lazily, so spans are exported once tracing is set up on start
+var tracer = otel.Tracer("fxrates/internal/rate")
+
+func pairAttributes(base string, quote string) trace.SpanStartEventOption {
+	return trace.WithAttributes(attribute.String("fx.base", base), attribute.String("fx.quote", quote))
+}
+
+// endSpan marks the span failed with a non-nil err and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -2,31 +2,142 @@ package rate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"fxrates/internal/adapters"
 	"fxrates/internal/domain"
+	"fxrates/internal/metrics"
 	"maps"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const numWorkers = 5
 const perRequestTimeout = 5 * time.Second
 
+// defaultClaimLease is how long pending updates stay claimed by a run, it must outlast the run
+const defaultClaimLease = 2 * time.Minute
+
 type rateUpdate struct {
-	Pair  domain.RatePair
-	Value float64
+	Pair   domain.RatePair
+	Value  decimal.Decimal
+	Source string
+	Quotes []domain.ProviderQuote
+}
+
+// fetchedRate is a value fetched for a pair along with the provider which served it.
+// Quotes are set when the value was agreed by several providers
+type fetchedRate struct {
+	Value  decimal.Decimal
+	Source string
+	Quotes []domain.ProviderQuote
+}
+
+// JobOptions tunes UpdatePendingRates behaviour
+type JobOptions struct {
+	// PivotCurrency, when set, makes pairs not involving it, which weren't fetched directly, derived from the pivot rates table
+	PivotCurrency string
+	// MaxAttempts, when positive, fails a pending update after that many runs without a fetched rate
+	MaxAttempts int
+	// MaxAge, when positive, expires a pending update not applied within that time since it was scheduled
+	MaxAge time.Duration
+	// Budget, when set, caps base fetches; bases over the budget aren't fetched and their updates are retried next run
+	Budget adapters.UpstreamBudget
+	// ClaimLease is how long claimed updates are kept from other runs; updates of a run that died are retried after it.
+	// Non-positive means defaultClaimLease
+	ClaimLease time.Duration
+	// Notifier, when set, is told about updates applied or closed by the run, so requests waiting for them return at once
+	Notifier adapters.UpdateNotifier
+	// QuotaBackoff, when set, skips runs for a while once a provider reported its quota exhausted
+	QuotaBackoff *QuotaBackoff
+	// FetchTimeout bounds the fetch of a single base, fallback providers included. Non-positive means perRequestTimeout
+	FetchTimeout time.Duration
+}
+
+// fetchOutcome is what a run learned about the upstream besides the fetched values
+type fetchOutcome struct {
+	// unsupported are bases the provider doesn't support, updates involving them can't ever be fetched
+	unsupported map[string]struct{}
+	// stopped is the rejected credentials or exhausted quota error which made the run stop fetching, nil otherwise
+	stopped error
+	// attempted are bases whose rates were asked for, postponed are those which weren't as the budget didn't allow it
+	// or the run stopped fetching first. served are the attempted bases whose rates came back
+	attempted map[string]struct{}
+	postponed map[string]struct{}
+	served    map[string]struct{}
+}
+
+// merge adds what another fetch of the same run learned
+func (o *fetchOutcome) merge(other fetchOutcome) {
+	maps.Copy(o.unsupported, other.unsupported)
+	maps.Copy(o.attempted, other.attempted)
+	maps.Copy(o.postponed, other.postponed)
+	maps.Copy(o.served, other.served)
+	if o.stopped == nil {
+		o.stopped = other.stopped
+	}
+}
+
+// postponedOnly tells whether no rate able to serve the update was asked for, as the bases which could were postponed.
+// Such an update wasn't tried at all
+func (o *fetchOutcome) postponedOnly(pr domain.PendingRateUpdate, pivot string) bool {
+	postponed := false
+	for _, code := range []string{pr.Base, pr.Quote, pivot} {
+		if code == "" {
+			continue
+		}
+		if _, ok := o.attempted[code]; ok {
+			return false
+		}
+		if _, ok := o.postponed[code]; ok {
+			postponed = true
+		}
+	}
+	return postponed
 }
 
 // UpdatePendingRates updates rates in database with values from external API
-func UpdatePendingRates(ctx context.Context, execID string, rateUpdateRepo adapters.RateUpdateRepository, rateClient adapters.RateClient, cache adapters.RateUpdateCache) error {
-	// STEP 1: getting pending rate updates from DB
-	pending, err := rateUpdateRepo.GetPending(ctx)
+func UpdatePendingRates(
+	ctx context.Context,
+	execID string,
+	rateUpdateRepo adapters.RateUpdateRepository,
+	rateClient adapters.RateClient,
+	cache adapters.RateUpdateCache,
+	publisher adapters.RatePublisher,
+	opts JobOptions,
+) (err error) {
+	start := time.Now()
+	defer func() { metrics.UpdateJobDuration.Observe(time.Since(start).Seconds()) }()
+
+	// every run is a trace of its own, so a slow update can be followed through the DB, the worker queue and upstream calls
+	ctx, span := tracer.Start(ctx, "UpdatePendingRates", trace.WithNewRoot(), trace.WithAttributes(attribute.String("fx.exec_id", execID)))
+	defer func() { endSpan(span, err) }()
+
+	// fetches are paused after an exhausted quota. Updates aren't even claimed meanwhile, so no attempts are counted
+	if opts.QuotaBackoff != nil {
+		if until, paused := opts.QuotaBackoff.pausedUntil(); paused {
+			logrus.Infof("Upstream quota is exhausted, updates wait until %s; execID: %s", until.Format(time.RFC3339), execID)
+			return nil
+		}
+	}
+
+	// STEP 1: claiming pending rate updates in DB, so runs of other replicas skip them. The run ID is the claim ID
+	lease := opts.ClaimLease
+	if lease <= 0 {
+		lease = defaultClaimLease
+	}
+	pending, err := rateUpdateRepo.ClaimPending(ctx, execID, lease)
 	if err != nil {
-		return fmt.Errorf("failed to get pending rates: %w", err)
+		return fmt.Errorf("failed to claim pending rates: %w", err)
 	}
+	metrics.UpdateJobPending.Set(float64(len(pending)))
+	span.SetAttributes(attribute.Int("fx.pending", len(pending)))
 
 	if len(pending) == 0 {
 		logrus.Infof("Nothing to update this time; execID: %s", execID)
@@ -44,17 +155,52 @@ func UpdatePendingRates(ctx context.Context, execID string, rateUpdateRepo adapt
 	// }
 	// ! NOTE: set doesn't contain reversed pairs (for example if "USD/EUR" presents, then "EUR/USD" will not)
 	pairSet := getUniquePairs(pending)
+	pivotFetched := false
+	if opts.PivotCurrency != "" {
+		if _, pivotFetched = getUniqueBases(pairSet)[opts.PivotCurrency]; pivotFetched {
+			// the pivot base is requested anyway, so its legs for all pairs come with the same response
+			maps.Copy(pairSet, toPivotLegs(pairSet, opts.PivotCurrency))
+		}
+	}
 
 	// STEP 3: processing set in parallel using workers pool. The result is a map of pairs with values
-	pairValueMap := processInParallel(ctx, rateClient, pairSet)
+	pairValueMap, outcome := processInParallel(ctx, rateClient, pairSet, opts)
+	if opts.PivotCurrency != "" && !pivotFetched && outcome.stopped == nil {
+		// pairs whose base wasn't fetched are derived through the pivot: "MXN/JPY" needs "USD/MXN" and "USD/JPY" legs (for "USD" pivot)
+		if legs := missingPivotLegs(pairSet, pairValueMap, opts.PivotCurrency); len(legs) > 0 {
+			legValues, legOutcome := processInParallel(ctx, rateClient, legs, opts)
+			maps.Copy(pairValueMap, legValues)
+			outcome.merge(legOutcome)
+		}
+	}
+	if opts.PivotCurrency != "" && outcome.stopped == nil {
+		// codes missing from the pivot table are asked for as bases, so an unsupported one fails the way it does as a base
+		if direct := missingFromPivot(pairSet, pairValueMap, outcome, opts.PivotCurrency); len(direct) > 0 {
+			directValues, directOutcome := processInParallel(ctx, rateClient, direct, opts)
+			maps.Copy(pairValueMap, directValues)
+			outcome.merge(directOutcome)
+		}
+	}
+	if opts.QuotaBackoff != nil {
+		if errors.Is(outcome.stopped, domain.ErrUpstreamQuota) {
+			until := opts.QuotaBackoff.exhausted()
+			logrus.Warnf("Upstream quota is exhausted, the next runs are skipped until %s; execID: %s", until.Format(time.RFC3339), execID)
+		} else if len(pairValueMap) > 0 {
+			opts.QuotaBackoff.reset()
+		}
+	}
 
-	// STEP 4: actually updating values in DB, then cleaning cache
-	countUpdated, err := doUpdateRates(ctx, pending, pairValueMap, rateUpdateRepo, cache)
+	// STEP 4: actually updating values in DB, then cleaning cache and publishing changes. Updates left without a value are retried or closed
+	countUpdated, err := doUpdateRates(ctx, execID, pending, pairValueMap, outcome, opts, rateUpdateRepo, cache, publisher)
 	if err != nil {
 		return err
 	}
 
+	span.SetAttributes(attribute.Int("fx.applied", countUpdated))
 	logrus.Infof("%d pending rates were successfully updated; execID %s", countUpdated, execID)
+	if errors.Is(outcome.stopped, domain.ErrUpstreamAuth) {
+		return fmt.Errorf("run was stopped as the rate provider rejected the credentials: %w", outcome.stopped)
+	}
 	return nil
 }
 
@@ -70,12 +216,102 @@ func getUniquePairs(pending []domain.PendingRateUpdate) map[domain.RatePair]stru
 	return pairSet
 }
 
-// processInParallel runs workers, which fetch rates from external API
-func processInParallel(ctx context.Context, rateClient adapters.RateClient, pairs map[domain.RatePair]struct{}) map[domain.RatePair]float64 {
+// missingPivotLegs returns pivot legs of pairs left without a value, directly or reversed, which could be derived through the pivot
+func missingPivotLegs(pairs map[domain.RatePair]struct{}, pairValueMap map[domain.RatePair]fetchedRate, pivot string) map[domain.RatePair]struct{} {
+	missing := make(map[domain.RatePair]struct{})
+	for p := range pairs {
+		if p.Base == pivot || p.Quote == pivot {
+			continue // the pivot base itself failed, there is nothing to derive from
+		}
+		if _, ok := pairValueMap[p]; ok {
+			continue
+		}
+		if _, ok := pairValueMap[p.Reversed()]; ok {
+			continue
+		}
+		missing[p] = struct{}{}
+	}
+	return toPivotLegs(missing, pivot)
+}
+
+// missingFromPivot returns unresolved pairs the served pivot table had no leg for, based on the missing code.
+// Codes already asked for as bases are left out, they failed on their own
+func missingFromPivot(pairs map[domain.RatePair]struct{}, pairValueMap map[domain.RatePair]fetchedRate, outcome fetchOutcome, pivot string) map[domain.RatePair]struct{} {
+	if _, ok := outcome.served[pivot]; !ok {
+		return nil
+	}
+	direct := make(map[domain.RatePair]struct{})
+	for p := range pairs {
+		if _, ok := pairValueMap[p]; ok {
+			continue
+		}
+		if _, ok := pairValueMap[p.Reversed()]; ok {
+			continue
+		}
+		if _, ok := deriveFromPivot(p, pairValueMap, pivot); ok {
+			continue
+		}
+		for _, code := range []string{p.Base, p.Quote} {
+			if code == pivot {
+				continue
+			}
+			if _, ok := pairValueMap[domain.RatePair{Base: pivot, Quote: code}]; ok {
+				continue
+			}
+			if _, ok := outcome.attempted[code]; ok {
+				continue
+			}
+			if code == p.Base {
+				direct[p] = struct{}{}
+			} else {
+				direct[p.Reversed()] = struct{}{}
+			}
+			break
+		}
+	}
+	return direct
+}
+
+// toPivotLegs expresses every pair through pivot legs, so all of them are served by the single pivot base request
+func toPivotLegs(pairs map[domain.RatePair]struct{}, pivot string) map[domain.RatePair]struct{} {
+	legs := make(map[domain.RatePair]struct{}, len(pairs))
+	for p := range pairs {
+		if p.Base != pivot {
+			legs[domain.RatePair{Base: pivot, Quote: p.Base}] = struct{}{}
+		}
+		if p.Quote != pivot {
+			legs[domain.RatePair{Base: pivot, Quote: p.Quote}] = struct{}{}
+		}
+	}
+	return legs
+}
+
+// processInParallel runs workers, which fetch rates from external API within opts.Budget and opts.FetchTimeout.
+// Rejected credentials or an exhausted quota stop the fetches, the remaining bases would fail the same way
+func processInParallel(ctx context.Context, rateClient adapters.RateClient, pairs map[domain.RatePair]struct{}, opts JobOptions) (map[domain.RatePair]fetchedRate, fetchOutcome) {
 	// STEP 1: extracting unique "bases"
 	// Pairs can contain same base values, for example "USD/EUR and "USD/MXN", we should not
 	// make several requests for the same currency! So let's extract only unique "bases"
 	bases := getUniqueBases(pairs) // bases is a set like: {"USD" -> {}, "EUR" -> {}, ...}
+	outcome := fetchOutcome{
+		unsupported: make(map[string]struct{}),
+		attempted:   make(map[string]struct{}, len(bases)),
+		postponed:   make(map[string]struct{}),
+		served:      make(map[string]struct{}, len(bases)),
+	}
+	if opts.Budget != nil {
+		granted := withinBudget(ctx, opts.Budget, bases)
+		for base := range bases {
+			if _, ok := granted[base]; !ok {
+				outcome.postponed[base] = struct{}{}
+			}
+		}
+		bases = granted
+	}
+	timeout := opts.FetchTimeout
+	if timeout <= 0 {
+		timeout = perRequestTimeout
+	}
 
 	// STEP 2: creating workQueue for parallel execution and then using it for parallel http requests
 	workQueue := make(chan string, len(bases))
@@ -84,27 +320,82 @@ func processInParallel(ctx context.Context, rateClient adapters.RateClient, pair
 	}
 	close(workQueue)
 
-	// STEP 3: running workers in parallel. Each worker puts its results into channel
+	// STEP 3: running workers in parallel. Each worker puts its results into channel and reports every fetch,
+	// failures tell something about the upstream
 	updatesCh := make(chan rateUpdate, len(pairs))
+	fetchCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	var mu sync.Mutex
+	onFetched := func(base string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if outcome.stopped != nil && errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			// cut short by the stop, the base wasn't at fault
+			outcome.postponed[base] = struct{}{}
+			return
+		}
+		outcome.attempted[base] = struct{}{}
+		switch {
+		case err == nil:
+			outcome.served[base] = struct{}{}
+		case errors.Is(err, domain.ErrUpstreamAuth), errors.Is(err, domain.ErrUpstreamQuota):
+			if outcome.stopped == nil {
+				outcome.stopped = err
+				logrus.Errorf("Fetching is stopped for this run: %v", err)
+				stop()
+			}
+		case errors.Is(err, domain.ErrUnsupportedCurrency):
+			outcome.unsupported[base] = struct{}{}
+		}
+	}
 
 	var wg sync.WaitGroup
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			runWorker(ctx, workerID, workQueue, rateClient, pairs, updatesCh)
+			runWorker(fetchCtx, workerID, workQueue, rateClient, timeout, pairs, updatesCh, onFetched)
 		}(i)
 	}
 
 	wg.Wait()
 	close(updatesCh)
+	// bases left in the queue once the run stopped fetching (or ran out of time) weren't asked for
+	for base := range bases {
+		if _, ok := outcome.attempted[base]; !ok {
+			outcome.postponed[base] = struct{}{}
+		}
+	}
 
 	// STEP 4: after all workers finished their jobs, creating a map containing pairs with values
-	pairValueMap := make(map[domain.RatePair]float64, len(pairs))
+	pairValueMap := make(map[domain.RatePair]fetchedRate, len(pairs))
 	for upd := range updatesCh {
-		pairValueMap[upd.Pair] = upd.Value
+		pairValueMap[upd.Pair] = fetchedRate{Value: upd.Value, Source: upd.Source, Quotes: upd.Quotes}
+	}
+	return pairValueMap, outcome
+}
+
+// withinBudget keeps as many bases as the budget grants, a budget error grants nothing as upstream calls are paid
+func withinBudget(ctx context.Context, budget adapters.UpstreamBudget, bases map[string]struct{}) map[string]struct{} {
+	granted, err := budget.Reserve(ctx, len(bases))
+	if err != nil {
+		logrus.Errorf("Upstream budget wasn't reserved, nothing is fetched this time: %v", err)
+		granted = 0
+	}
+	if granted >= len(bases) {
+		return bases
+	}
+
+	metrics.UpstreamBudgetDenied.Add(float64(len(bases) - granted))
+	logrus.Warnf("Upstream budget is exhausted, %d of %d bases are postponed", len(bases)-granted, len(bases))
+	kept := make(map[string]struct{}, granted)
+	for base := range bases {
+		if len(kept) == granted {
+			break
+		}
+		kept[base] = struct{}{}
 	}
-	return pairValueMap
+	return kept
 }
 
 func getUniqueBases(pairs map[domain.RatePair]struct{}) map[string]struct{} {
@@ -115,85 +406,341 @@ func getUniqueBases(pairs map[domain.RatePair]struct{}) map[string]struct{} {
 	return baseSet
 }
 
-func runWorker(ctx context.Context, workerID int, workQueue <-chan string, rateClient adapters.RateClient, pairs map[domain.RatePair]struct{}, updatesCh chan<- rateUpdate) {
+// runWorker processes bases of the queue until it's drained or ctx is done, every fetch is reported to onFetched with its error
+func runWorker(
+	ctx context.Context,
+	workerID int,
+	workQueue <-chan string,
+	rateClient adapters.RateClient,
+	timeout time.Duration,
+	pairs map[domain.RatePair]struct{},
+	updatesCh chan<- rateUpdate,
+	onFetched func(base string, err error),
+) {
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case base, ok := <-workQueue:
-			if !ok {
+			// select picks a ready case at random, so a done ctx is checked again before fetching
+			if !ok || ctx.Err() != nil {
 				return
 			}
-			processBase(ctx, workerID, base, rateClient, pairs, updatesCh)
+			onFetched(base, processBase(ctx, workerID, base, rateClient, timeout, pairs, updatesCh))
 		}
 	}
 }
 
-// processBase fetches new values from external API and pushes matching pairs to the updates channel
-func processBase(ctx context.Context, workerID int, base string, rateClient adapters.RateClient, pairs map[domain.RatePair]struct{}, updatesCh chan<- rateUpdate) {
-	reqCtx, cancel := context.WithTimeout(ctx, perRequestTimeout)
+// processBase fetches new values from external API within timeout and pushes matching pairs to the updates channel, the fetch error is returned
+func processBase(ctx context.Context, workerID int, base string, rateClient adapters.RateClient, timeout time.Duration, pairs map[domain.RatePair]struct{}, updatesCh chan<- rateUpdate) (err error) {
+	ctx, span := tracer.Start(ctx, "processBase", trace.WithAttributes(attribute.String("fx.base", base), attribute.Int("fx.worker_id", workerID)))
+	defer func() { endSpan(span, err) }()
+
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	// STEP 1: make external API request
 	// We are using context with timeout as we better interrupt request and process "Base" on the next scheduler job rather than wait!
-	// After successful request, ratesMap will look like this:
+	// After successful request, fetched.Rates will look like this:
 	// {
 	//		"MXN": 1.234,
 	//		"EUR": 1.431,
 	//      ...
 	// }
-	ratesMap, err := rateClient.GetExchangeRates(reqCtx, base)
+	// and fetched.Provider will tell which provider served them (fetched.Quotes keeps per-provider values in consensus mode)
+	start := time.Now()
+	fetched, err := rateClient.GetExchangeRates(reqCtx, base)
+	metrics.ProviderRequestDuration.WithLabelValues(base).Observe(time.Since(start).Seconds())
 	if err != nil {
+		metrics.ProviderErrors.WithLabelValues(base).Inc()
 		logrus.Warnf("Base '%s' wasn't processed by Worker %d as external api call returned error: %s", base, workerID, err)
-		return
+		return err
 	}
+	span.SetAttributes(attribute.String("fx.provider", fetched.Provider), attribute.Int("fx.rates", len(fetched.Rates)))
 
-	// STEP 2: iterating over ratesMap from response, find all pairs that present in pairsMap and put them into channel with updated values
-	for quote, v := range ratesMap {
+	// STEP 2: iterating over rates from response, find all pairs that present in pairsMap and put them into channel with updated values
+	for quote, v := range fetched.Rates {
 		p := domain.RatePair{Base: base, Quote: quote}
 		if _, ok := pairs[p]; ok {
-			updatesCh <- rateUpdate{Pair: p, Value: v}
+			updatesCh <- rateUpdate{Pair: p, Value: v, Source: fetched.Provider, Quotes: fetched.Quotes[quote]}
 		}
 	}
+	return nil
 }
 
-// doUpdateRates actually updates rates in DB and cleans cache
-func doUpdateRates(ctx context.Context, pending []domain.PendingRateUpdate, pairValueMap map[domain.RatePair]float64, rateUpdatesRepo adapters.RateUpdateRepository, cache adapters.RateUpdateCache) (int, error) {
+// doUpdateRates actually updates rates in DB, cleans cache, publishes applied values (nil publisher skips it) and notifies waiters.
+// Updates are written on behalf of the claimID run, those left without a value are retried or closed by what the fetch outcome tells
+func doUpdateRates(
+	ctx context.Context,
+	claimID string,
+	pending []domain.PendingRateUpdate,
+	pairValueMap map[domain.RatePair]fetchedRate,
+	outcome fetchOutcome,
+	opts JobOptions,
+	rateUpdatesRepo adapters.RateUpdateRepository,
+	cache adapters.RateUpdateCache,
+	publisher adapters.RatePublisher,
+) (int, error) {
 	// STEP 1: for all pending rates we:
 	// - build a list of AppliedRateUpdate, which will be updated in DB
 	// - build a list of RatePairs, which will be cleaned from cache
+	// - collect skipped updates, which will be retried or closed
 	updatesToApply := make([]domain.AppliedRateUpdate, 0, len(pending))
 	updatedPairs := make([]domain.RatePair, 0, len(pending))
+	skipped := make([]domain.PendingRateUpdate, 0)
 
 	for _, pr := range pending {
-		var value float64
+		var value fetchedRate
 		pair := domain.RatePair{Base: pr.Base, Quote: pr.Quote}
 
 		if v, ok := pairValueMap[pair]; ok {
 			value = v
-		} else if v, ok = pairValueMap[pair.Reversed()]; ok {
+		} else if v, ok = pairValueMap[pair.Reversed()]; ok && v.Value.IsPositive() {
 			// check if reversed pair presents and compute the value
-			value = 1 / v
+			value = fetchedRate{Value: domain.InverseRate(v.Value), Source: v.Source, Quotes: invertQuotes(v.Quotes)}
+		} else if v, ok = deriveFromPivot(pair, pairValueMap, opts.PivotCurrency); ok {
+			// base wasn't fetched, but both pivot legs are known
+			value = v
 		} else {
 			// this can happen when some workers failed to fetch rates from external api
 			logrus.Warnf("Skipping update for '%s', it'll be processed next time", pr.Base+"/"+pr.Quote)
+			skipped = append(skipped, pr)
 			continue
 		}
 
-		updatesToApply = append(updatesToApply, domain.AppliedRateUpdate{UpdateID: pr.UpdateID, PairID: pr.PairID, Value: value})
+		updatesToApply = append(updatesToApply, domain.AppliedRateUpdate{
+			UpdateID: pr.UpdateID,
+			PairID:   pr.PairID,
+			Value:    value.Value,
+			Source:   value.Source,
+			Quotes:   value.Quotes,
+		})
 		updatedPairs = append(updatedPairs, domain.RatePair{Base: pr.Base, Quote: pr.Quote})
 	}
 
-	if len(updatesToApply) == 0 {
-		return 0, nil
+	// STEP 2: applying updates in DB and clean cache, updates reclaimed by another run are left to it
+	var claimLostErr *domain.ClaimLostError
+	if len(updatesToApply) > 0 {
+		err := rateUpdatesRepo.ApplyUpdates(ctx, claimID, updatesToApply)
+		if errors.As(err, &claimLostErr) {
+			logrus.Warnf("%d updates were reclaimed by another run, they're left to it", len(claimLostErr.UpdateIDs))
+			updatesToApply, updatedPairs = withoutLost(updatesToApply, updatedPairs, claimLostErr.UpdateIDs)
+		} else if err != nil {
+			return 0, fmt.Errorf("failed to update rates: %w", err)
+		}
+	}
+	if len(updatesToApply) > 0 {
+		// Potentially before CleanBatch called, some other thread can access old cache inside ScheduleUpdate (service.go).
+		// This isn't a problem as user will get fresh data on the next request
+		cache.CleanBatch(updatedPairs)
+		metrics.UpdatesApplied.Add(float64(len(updatesToApply)))
+		if publisher != nil {
+			publisher.Publish(toRateChanges(updatesToApply, updatedPairs, time.Now().UTC()))
+		}
+		if opts.Notifier != nil {
+			opts.Notifier.NotifyFinished(updateIDsOf(updatesToApply))
+		}
 	}
 
-	// STEP 2: applying updates in DB and clean cache
-	err := rateUpdatesRepo.ApplyUpdates(ctx, updatesToApply)
-	if err != nil {
-		return 0, fmt.Errorf("failed to update rates: %w", err)
+	// STEP 3: counting the failed attempt of skipped updates, closing those exceeding the limits
+	metrics.UpdatesSkipped.Add(float64(len(skipped)))
+	if err := retryOrCloseSkipped(ctx, claimID, skipped, outcome, opts, rateUpdatesRepo, cache); err != nil {
+		return len(updatedPairs), err
+	}
+	if claimLostErr != nil {
+		return len(updatedPairs), fmt.Errorf("failed to update rates: %w", claimLostErr)
 	}
-	// Potentially before CleanBatch called, some other thread can access old cache inside ScheduleUpdate (service.go).
-	// This isn't a problem as user will get fresh data on the next request
-	cache.CleanBatch(updatedPairs)
 	return len(updatedPairs), nil
 }
+
+// withoutLost drops updates reclaimed by another run along with their pairs, both slices are in the same order
+func withoutLost(applied []domain.AppliedRateUpdate, pairs []domain.RatePair, lost []uuid.UUID) ([]domain.AppliedRateUpdate, []domain.RatePair) {
+	lostIDs := make(map[uuid.UUID]struct{}, len(lost))
+	for _, id := range lost {
+		lostIDs[id] = struct{}{}
+	}
+	keptUpdates := make([]domain.AppliedRateUpdate, 0, len(applied))
+	keptPairs := make([]domain.RatePair, 0, len(pairs))
+	for i, upd := range applied {
+		if _, ok := lostIDs[upd.UpdateID]; !ok {
+			keptUpdates = append(keptUpdates, upd)
+			keptPairs = append(keptPairs, pairs[i])
+		}
+	}
+	return keptUpdates, keptPairs
+}
+
+// toRateChanges pairs applied updates with their currencies, both slices are built in the same order
+func toRateChanges(applied []domain.AppliedRateUpdate, pairs []domain.RatePair, updatedAt time.Time) []domain.RateChange {
+	changes := make([]domain.RateChange, 0, len(applied))
+	for i, upd := range applied {
+		changes = append(changes, domain.RateChange{
+			UpdateID:  upd.UpdateID,
+			Base:      pairs[i].Base,
+			Quote:     pairs[i].Quote,
+			Value:     upd.Value,
+			Source:    upd.Source,
+			UpdatedAt: updatedAt,
+		})
+	}
+	return changes
+}
+
+func updateIDsOf(applied []domain.AppliedRateUpdate) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(applied))
+	for _, upd := range applied {
+		ids = append(ids, upd.UpdateID)
+	}
+	return ids
+}
+
+// retryOrCloseSkipped leaves skipped updates pending for the next run unless they reached MaxAge or MaxAttempts
+// or involve a currency the provider doesn't support (the pivot one included, as pairs are derived through it).
+// Updates whose rates weren't asked for at all are released without counting an attempt, they only expire by age.
+// Closed updates are failed or expired with a reason and dropped from cache, so the pair can be scheduled again
+func retryOrCloseSkipped(
+	ctx context.Context,
+	claimID string,
+	skipped []domain.PendingRateUpdate,
+	outcome fetchOutcome,
+	opts JobOptions,
+	rateUpdatesRepo adapters.RateUpdateRepository,
+	cache adapters.RateUpdateCache,
+) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	retried := make([]uuid.UUID, 0, len(skipped))
+	released := make([]uuid.UUID, 0)
+	closed := make([]domain.ClosedRateUpdate, 0)
+	closedPairs := make([]domain.RatePair, 0)
+	for _, pr := range skipped {
+		attempts := pr.Attempts + 1
+		code, isUnsupported := unsupportedCode(pr, outcome.unsupported, opts.PivotCurrency)
+		notTried := outcome.postponedOnly(pr, opts.PivotCurrency)
+		switch {
+		case isUnsupported:
+			closed = append(closed, domain.ClosedRateUpdate{
+				UpdateID: pr.UpdateID,
+				Status:   domain.StatusFailed,
+				Reason:   fmt.Sprintf("currency %s isn't supported by the rate provider", code),
+			})
+		case opts.MaxAge > 0 && now.Sub(pr.CreatedAt) >= opts.MaxAge:
+			closed = append(closed, domain.ClosedRateUpdate{
+				UpdateID: pr.UpdateID,
+				Status:   domain.StatusExpired,
+				Reason:   fmt.Sprintf("rate wasn't fetched within %s", opts.MaxAge),
+			})
+		case notTried:
+			released = append(released, pr.UpdateID)
+			continue
+		case opts.MaxAttempts > 0 && attempts >= opts.MaxAttempts:
+			closed = append(closed, domain.ClosedRateUpdate{
+				UpdateID: pr.UpdateID,
+				Status:   domain.StatusFailed,
+				Reason:   fmt.Sprintf("rate wasn't fetched after %d attempts", attempts),
+			})
+		default:
+			retried = append(retried, pr.UpdateID)
+			continue
+		}
+		closedPairs = append(closedPairs, domain.RatePair{Base: pr.Base, Quote: pr.Quote})
+	}
+
+	if len(retried) > 0 {
+		if err := rateUpdatesRepo.IncrementAttempts(ctx, claimID, retried); err != nil {
+			return fmt.Errorf("failed to count attempts: %w", err)
+		}
+	}
+	if len(released) > 0 {
+		if err := rateUpdatesRepo.ReleaseClaims(ctx, claimID, released); err != nil {
+			return fmt.Errorf("failed to release claims: %w", err)
+		}
+	}
+	if len(closed) > 0 {
+		if err := rateUpdatesRepo.CloseUpdates(ctx, claimID, closed); err != nil {
+			return fmt.Errorf("failed to close updates: %w", err)
+		}
+		cache.CleanBatch(closedPairs)
+		if opts.Notifier != nil {
+			ids := make([]uuid.UUID, 0, len(closed))
+			for _, c := range closed {
+				ids = append(ids, c.UpdateID)
+			}
+			opts.Notifier.NotifyFinished(ids)
+		}
+		logrus.Warnf("%d pending rates were closed without a value", len(closed))
+	}
+	return nil
+}
+
+// unsupportedCode returns the currency of the update the provider doesn't support, if any
+func unsupportedCode(pr domain.PendingRateUpdate, unsupported map[string]struct{}, pivot string) (string, bool) {
+	for _, code := range []string{pr.Base, pr.Quote, pivot} {
+		if _, ok := unsupported[code]; ok && code != "" {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// deriveFromPivot computes base/quote as (pivot/quote) / (pivot/base) rounded to the rate scale
+func deriveFromPivot(pair domain.RatePair, pairValueMap map[domain.RatePair]fetchedRate, pivot string) (fetchedRate, bool) {
+	if pivot == "" || pair.Base == pivot || pair.Quote == pivot {
+		return fetchedRate{}, false
+	}
+	toBase, ok := pairValueMap[domain.RatePair{Base: pivot, Quote: pair.Base}]
+	if !ok || !toBase.Value.IsPositive() {
+		return fetchedRate{}, false
+	}
+	toQuote, ok := pairValueMap[domain.RatePair{Base: pivot, Quote: pair.Quote}]
+	if !ok {
+		return fetchedRate{}, false
+	}
+	return fetchedRate{
+		Value:  toQuote.Value.DivRound(toBase.Value, domain.RateScale),
+		Source: toQuote.Source,
+		Quotes: deriveQuotes(toBase.Quotes, toQuote.Quotes),
+	}, true
+}
+
+// deriveQuotes combines provider quotes of both pivot legs into quotes of the derived pair, a provider's value is
+// its quote leg divided by its base leg. Only providers answering both legs are kept, accepted if both legs were
+func deriveQuotes(toBase []domain.ProviderQuote, toQuote []domain.ProviderQuote) []domain.ProviderQuote {
+	if len(toBase) == 0 || len(toQuote) == 0 {
+		return nil
+	}
+	baseLegs := make(map[string]domain.ProviderQuote, len(toBase))
+	for _, q := range toBase {
+		baseLegs[q.Provider] = q
+	}
+	derived := make([]domain.ProviderQuote, 0, len(toQuote))
+	for _, q := range toQuote {
+		baseLeg, ok := baseLegs[q.Provider]
+		if !ok || !baseLeg.Value.IsPositive() {
+			continue
+		}
+		derived = append(derived, domain.ProviderQuote{
+			Provider: q.Provider,
+			Value:    q.Value.DivRound(baseLeg.Value, domain.RateScale),
+			Accepted: q.Accepted && baseLeg.Accepted,
+		})
+	}
+	if len(derived) == 0 {
+		return nil
+	}
+	return derived
+}
+
+// invertQuotes expresses provider quotes of a pair in terms of its reversed pair
+func invertQuotes(quotes []domain.ProviderQuote) []domain.ProviderQuote {
+	if len(quotes) == 0 {
+		return nil
+	}
+	inverted := make([]domain.ProviderQuote, 0, len(quotes))
+	for _, q := range quotes {
+		inverted = append(inverted, domain.ProviderQuote{Provider: q.Provider, Value: domain.InverseRate(q.Value), Accepted: q.Accepted})
+	}
+	return inverted
+}
@@ -3,27 +3,64 @@ package rate
 import (
 	"context"
 	"errors"
+	"fmt"
 	"maps"
 	"slices"
 	"sort"
 	"testing"
+	"time"
 
 	"fxrates/internal/domain"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type MockRateClient struct{ mock.Mock }
 
-func (m *MockRateClient) GetExchangeRates(ctx context.Context, code string) (map[string]float64, error) {
+func (m *MockRateClient) GetExchangeRates(ctx context.Context, code string) (domain.ExchangeRates, error) {
 	args := m.Called(ctx, code)
-	rates, _ := args.Get(0).(map[string]float64)
+	rates, _ := args.Get(0).(domain.ExchangeRates)
 	return rates, args.Error(1)
 }
 
+type MockUpstreamBudget struct{ mock.Mock }
+
+func (m *MockUpstreamBudget) Reserve(ctx context.Context, n int) (int, error) {
+	args := m.Called(ctx, n)
+	return args.Int(0), args.Error(1)
+}
+
+type MockRatePublisher struct{ mock.Mock }
+
+func (m *MockRatePublisher) Publish(changes []domain.RateChange) {
+	m.Called(changes)
+}
+
+type MockUpdateNotifier struct{ mock.Mock }
+
+func (m *MockUpdateNotifier) NotifyFinished(updateIDs []uuid.UUID) {
+	m.Called(updateIDs)
+}
+
+func dec(v string) decimal.Decimal {
+	return decimal.RequireFromString(v)
+}
+
+// requireDecimal compares values numerically, so "1.20" and "1.2" are equal
+func requireDecimal(t *testing.T, expected string, actual decimal.Decimal) {
+	t.Helper()
+	require.Truef(t, dec(expected).Equal(actual), "expected %s, got %s", expected, actual)
+}
+
 // --- getUniquePairs ---
 
 func TestGetUniquePairs_SkipsReversedAndSetsDefaults(t *testing.T) {
@@ -53,6 +90,25 @@ func TestGetUniquePairs_SkipsReversedAndSetsDefaults(t *testing.T) {
 	require.Equal(t, struct{}{}, pairs[domain.RatePair{Base: "MXN", Quote: "EUR"}])
 }
 
+// --- toPivotLegs ---
+
+func TestToPivotLegs_ReplacesPairsWithPivotLegs(t *testing.T) {
+	pairs := map[domain.RatePair]struct{}{
+		{Base: "MXN", Quote: "JPY"}: {},
+		{Base: "EUR", Quote: "USD"}: {},
+		{Base: "USD", Quote: "MXN"}: {},
+	}
+
+	legs := toPivotLegs(pairs, "USD")
+
+	require.Equal(t, map[domain.RatePair]struct{}{
+		{Base: "USD", Quote: "MXN"}: {},
+		{Base: "USD", Quote: "JPY"}: {},
+		{Base: "USD", Quote: "EUR"}: {},
+	}, legs)
+	require.Equal(t, map[string]struct{}{"USD": {}}, getUniqueBases(legs))
+}
+
 // --- getUniqueBases ---
 
 func TestGetUniqueBases_CollectsUnique(t *testing.T) {
@@ -75,10 +131,10 @@ func TestProcessBase_ErrorFromClient_DoesNotModify(t *testing.T) {
 	pairs := map[domain.RatePair]struct{}{
 		{Base: "USD", Quote: "EUR"}: {},
 	}
-	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(nil, errors.New("timeout")).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, errors.New("timeout")).Once()
 
 	updates := make(chan rateUpdate, 1)
-	processBase(context.Background(), 1, "USD", mockClient, pairs, updates)
+	processBase(context.Background(), 1, "USD", mockClient, perRequestTimeout, pairs, updates)
 
 	select {
 	case <-updates:
@@ -95,23 +151,23 @@ func TestProcessBase_UpdatesMatchingPairsOnly(t *testing.T) {
 		{Base: "USD", Quote: "PLN"}: {},
 		{Base: "EUR", Quote: "JPY"}: {},
 	}
-	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(map[string]float64{
-		"EUR": 1.2,
-		"PLN": 4.0,
-		"JPY": 150,
-	}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{
+		"EUR": dec("1.2"),
+		"PLN": dec("4.0"),
+		"JPY": dec("150"),
+	}}, nil).Once()
 
 	updates := make(chan rateUpdate, len(pairs))
 
-	processBase(context.Background(), 2, "USD", mockClient, pairs, updates)
+	processBase(context.Background(), 2, "USD", mockClient, perRequestTimeout, pairs, updates)
 	close(updates)
 
-	results := map[domain.RatePair]float64{}
+	results := map[domain.RatePair]decimal.Decimal{}
 	for upd := range updates {
 		results[upd.Pair] = upd.Value
 	}
-	require.InDelta(t, 1.2, results[domain.RatePair{Base: "USD", Quote: "EUR"}], 1e-9)
-	require.InDelta(t, 4.0, results[domain.RatePair{Base: "USD", Quote: "PLN"}], 1e-9)
+	requireDecimal(t, "1.2", results[domain.RatePair{Base: "USD", Quote: "EUR"}])
+	requireDecimal(t, "4.0", results[domain.RatePair{Base: "USD", Quote: "PLN"}])
 	require.NotContains(t, results, domain.RatePair{Base: "EUR", Quote: "JPY"})
 	mockClient.AssertExpectations(t)
 }
@@ -130,25 +186,25 @@ func TestRunWorker_ProcessesQueue(t *testing.T) {
 		{Base: "EUR", Quote: "USD"}: {},
 	}
 
-	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(map[string]float64{"EUR": 1.3}, nil).Once()
-	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(map[string]float64{"USD": 0.77}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("1.3")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"USD": dec("0.77")}}, nil).Once()
 
 	done := make(chan struct{})
 	updates := make(chan rateUpdate, 4)
 	go func() {
-		runWorker(context.Background(), 7, queue, mockClient, pairs, updates)
+		runWorker(context.Background(), 7, queue, mockClient, perRequestTimeout, pairs, updates, func(string, error) {})
 		close(done)
 	}()
 
 	<-done
 	close(updates)
 
-	results := make(map[domain.RatePair]float64)
+	results := make(map[domain.RatePair]decimal.Decimal)
 	for upd := range updates {
 		results[upd.Pair] = upd.Value
 	}
-	require.InDelta(t, 1.3, results[domain.RatePair{Base: "USD", Quote: "EUR"}], 1e-9)
-	require.InDelta(t, 0.77, results[domain.RatePair{Base: "EUR", Quote: "USD"}], 1e-9)
+	requireDecimal(t, "1.3", results[domain.RatePair{Base: "USD", Quote: "EUR"}])
+	requireDecimal(t, "0.77", results[domain.RatePair{Base: "EUR", Quote: "USD"}])
 	mockClient.AssertExpectations(t)
 }
 
@@ -162,19 +218,70 @@ func TestProcessInParallel_UpdatesAllBasesAndReturnsPairValueMap(t *testing.T) {
 		{Base: "EUR", Quote: "GBP"}: {},
 	}
 
-	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(map[string]float64{"EUR": 1.11, "PLN": 3.99}, nil).Once()
-	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(map[string]float64{"GBP": 0.86}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("1.11"), "PLN": dec("3.99")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"GBP": dec("0.86")}}, nil).Once()
 
-	pairValueMap := processInParallel(context.Background(), mockClient, pairs)
+	pairValueMap, _ := processInParallel(context.Background(), mockClient, pairs, JobOptions{})
 
-	require.InDelta(t, 1.11, pairValueMap[domain.RatePair{Base: "USD", Quote: "EUR"}], 1e-9)
-	require.InDelta(t, 3.99, pairValueMap[domain.RatePair{Base: "USD", Quote: "PLN"}], 1e-9)
-	require.InDelta(t, 0.86, pairValueMap[domain.RatePair{Base: "EUR", Quote: "GBP"}], 1e-9)
+	requireDecimal(t, "1.11", pairValueMap[domain.RatePair{Base: "USD", Quote: "EUR"}].Value)
+	requireDecimal(t, "3.99", pairValueMap[domain.RatePair{Base: "USD", Quote: "PLN"}].Value)
+	requireDecimal(t, "0.86", pairValueMap[domain.RatePair{Base: "EUR", Quote: "GBP"}].Value)
+	require.Equal(t, "test", pairValueMap[domain.RatePair{Base: "EUR", Quote: "GBP"}].Source)
 	mockClient.AssertExpectations(t)
 }
 
 // --- doUpdateRates ---
 
+func TestProcessInParallel_FetchesOnlyBasesWithinBudget(t *testing.T) {
+	mockClient := new(MockRateClient)
+	budget := new(MockUpstreamBudget)
+	pairs := map[domain.RatePair]struct{}{
+		{Base: "USD", Quote: "EUR"}: {},
+		{Base: "GBP", Quote: "JPY"}: {},
+	}
+	budget.On("Reserve", mock.Anything, 2).Return(1, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, mock.Anything).Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("0.92"), "JPY": dec("190")}}, nil).Once()
+
+	pairValueMap, outcome := processInParallel(context.Background(), mockClient, pairs, JobOptions{Budget: budget})
+
+	require.Len(t, pairValueMap, 1)
+	require.Len(t, outcome.attempted, 1)
+	require.Len(t, outcome.postponed, 1)
+	mockClient.AssertNumberOfCalls(t, "GetExchangeRates", 1)
+	budget.AssertExpectations(t)
+}
+
+func TestUpdatePendingRates_OverBudget_ReleasesWithoutCountingAttempt(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	budget := new(MockUpstreamBudget)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR", CreatedAt: time.Now()}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-11", defaultClaimLease).Return([]domain.PendingRateUpdate{p1}, nil).Once()
+	budget.On("Reserve", mock.Anything, 1).Return(0, nil).Once()
+	mockUpdatesRepo.On("ReleaseClaims", mock.Anything, "exec-11", []uuid.UUID{p1.UpdateID}).Return(nil).Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-11", mockUpdatesRepo, mockClient, new(MockRateUpdateCache), nil, JobOptions{Budget: budget, MaxAttempts: 1})
+
+	require.NoError(t, err)
+	mockClient.AssertNotCalled(t, "GetExchangeRates", mock.Anything, mock.Anything)
+	mockUpdatesRepo.AssertNotCalled(t, "IncrementAttempts", mock.Anything, mock.Anything, mock.Anything)
+	mockUpdatesRepo.AssertNotCalled(t, "CloseUpdates", mock.Anything, mock.Anything, mock.Anything)
+	mockUpdatesRepo.AssertExpectations(t)
+	budget.AssertExpectations(t)
+}
+
+func TestProcessInParallel_BudgetError_FetchesNothing(t *testing.T) {
+	mockClient := new(MockRateClient)
+	budget := new(MockUpstreamBudget)
+	budget.On("Reserve", mock.Anything, 1).Return(0, errors.New("db down")).Once()
+
+	pairValueMap, _ := processInParallel(context.Background(), mockClient, map[domain.RatePair]struct{}{{Base: "USD", Quote: "EUR"}: {}}, JobOptions{Budget: budget})
+
+	require.Empty(t, pairValueMap)
+	mockClient.AssertNotCalled(t, "GetExchangeRates", mock.Anything, mock.Anything)
+}
+
 func TestDoUpdateRates_AppliesDirectAndReversedAndSkipsMissing(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	cacheMock := new(MockRateUpdateCache)
@@ -184,23 +291,26 @@ func TestDoUpdateRates_AppliesDirectAndReversedAndSkipsMissing(t *testing.T) {
 		{UpdateID: uuid.New(), PairID: 3, Base: "GBP", Quote: "JPY"}, // missing -> skip
 		{UpdateID: uuid.New(), PairID: 4, Base: "AUD", Quote: "NZD"}, // non-positive -> skip
 	}
-	pairValueMap := map[domain.RatePair]float64{
-		{Base: "USD", Quote: "EUR"}: 0.9, // direct
-		{Base: "PLN", Quote: "EUR"}: 4.0, // reversed available => 1/4.0
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("0.9"), Source: "test"}, // direct
+		{Base: "PLN", Quote: "EUR"}: {Value: dec("4.0"), Source: "test"}, // reversed available => 1/4.0
 	}
 
 	mockUpdatesRepo.
-		On("ApplyUpdates", mock.Anything, mock.Anything).
+		On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).
 		Return(nil).
 		Run(func(args mock.Arguments) {
-			applied, ok := args.Get(1).([]domain.AppliedRateUpdate)
+			applied, ok := args.Get(2).([]domain.AppliedRateUpdate)
 			require.True(t, ok)
 			require.Len(t, applied, 2)
 
-			require.InDelta(t, 0.9, applied[0].Value, 1e-9)
-			require.InDelta(t, 1.0/4.0, applied[1].Value, 1e-9)
+			requireDecimal(t, "0.9", applied[0].Value)
+			requireDecimal(t, "0.25", applied[1].Value)
+			require.Equal(t, "test", applied[1].Source)
 		}).Once()
 
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "run-1", []uuid.UUID{pending[2].UpdateID, pending[3].UpdateID}).Return(nil).Once()
+
 	expectedPairs := []domain.RatePair{
 		{Base: "USD", Quote: "EUR"},
 		{Base: "EUR", Quote: "PLN"},
@@ -209,7 +319,7 @@ func TestDoUpdateRates_AppliesDirectAndReversedAndSkipsMissing(t *testing.T) {
 		return assert.ElementsMatch(t, expectedPairs, pairs)
 	})).Return().Once()
 
-	count, err := doUpdateRates(context.Background(), pending, pairValueMap, mockUpdatesRepo, cacheMock)
+	count, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, nil)
 
 	require.NoError(t, err)
 	require.Equal(t, 2, count)
@@ -217,21 +327,221 @@ func TestDoUpdateRates_AppliesDirectAndReversedAndSkipsMissing(t *testing.T) {
 	cacheMock.AssertExpectations(t)
 }
 
+func TestDoUpdateRates_KeepsConsensusQuotes(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}, // direct
+		{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "USD"}, // reversed
+	}
+	quotes := []domain.ProviderQuote{
+		{Provider: "first", Value: dec("0.8"), Accepted: true},
+		{Provider: "second", Value: dec("0.5"), Accepted: false},
+	}
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("0.8"), Source: "consensus", Quotes: quotes},
+	}
+
+	mockUpdatesRepo.
+		On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			applied := args.Get(2).([]domain.AppliedRateUpdate)
+			require.Len(t, applied, 2)
+			require.Equal(t, quotes, applied[0].Quotes)
+
+			require.Len(t, applied[1].Quotes, 2)
+			require.Equal(t, "second", applied[1].Quotes[1].Provider)
+			requireDecimal(t, "2.0", applied[1].Quotes[1].Value)
+			require.False(t, applied[1].Quotes[1].Accepted)
+		}).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+
+	count, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	mockUpdatesRepo.AssertExpectations(t)
+}
+
+func TestDoUpdateRates_PublishesAppliedChanges(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	publisherMock := new(MockRatePublisher)
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"},
+		{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "USD"},
+	}
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("0.8"), Source: "frankfurter"},
+	}
+
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).Return(nil).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+	publisherMock.On("Publish", mock.Anything).Run(func(args mock.Arguments) {
+		changes := args.Get(0).([]domain.RateChange)
+		require.Len(t, changes, 2)
+		require.Equal(t, pending[0].UpdateID, changes[0].UpdateID)
+		require.Equal(t, "USD", changes[0].Base)
+		require.Equal(t, "EUR", changes[0].Quote)
+		requireDecimal(t, "0.8", changes[0].Value)
+		require.Equal(t, "frankfurter", changes[0].Source)
+		require.False(t, changes[0].UpdatedAt.IsZero())
+		require.Equal(t, pending[1].UpdateID, changes[1].UpdateID)
+		require.Equal(t, "EUR", changes[1].Base)
+		requireDecimal(t, "1.25", changes[1].Value)
+	}).Return().Once()
+
+	_, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, publisherMock)
+
+	require.NoError(t, err)
+	publisherMock.AssertExpectations(t)
+}
+
+func TestDoUpdateRates_ApplyError_NothingPublished(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	publisherMock := new(MockRatePublisher)
+	pending := []domain.PendingRateUpdate{{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}}
+	pairValueMap := map[domain.RatePair]fetchedRate{{Base: "USD", Quote: "EUR"}: {Value: dec("0.8")}}
+
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).Return(errors.New("db down")).Once()
+
+	_, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, new(MockRateUpdateCache), publisherMock)
+
+	require.Error(t, err)
+	publisherMock.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestDoUpdateRates_DerivesFromPivotLegs(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "MXN", Quote: "JPY"}, // derived via USD/MXN and USD/JPY
+		{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "USD"}, // reversed leg
+		{UpdateID: uuid.New(), PairID: 3, Base: "GBP", Quote: "JPY"}, // USD/GBP missing -> skip
+	}
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "MXN"}: {Value: dec("20.0"), Source: "test"},
+		{Base: "USD", Quote: "JPY"}: {Value: dec("150.0"), Source: "test"},
+		{Base: "USD", Quote: "EUR"}: {Value: dec("0.8"), Source: "test"},
+	}
+
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		applied := args.Get(2).([]domain.AppliedRateUpdate)
+		require.Len(t, applied, 2)
+		requireDecimal(t, "7.5", applied[0].Value)
+		requireDecimal(t, "1.25", applied[1].Value)
+	}).Once()
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "run-1", []uuid.UUID{pending[2].UpdateID}).Return(nil).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+
+	count, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{PivotCurrency: "USD"}, mockUpdatesRepo, cacheMock, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	mockUpdatesRepo.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestDeriveFromPivot_CombinesLegQuotesPerProvider(t *testing.T) {
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "MXN"}: {Value: dec("20"), Source: "consensus", Quotes: []domain.ProviderQuote{
+			{Provider: "a", Value: dec("20"), Accepted: true},
+			{Provider: "b", Value: dec("25"), Accepted: false},
+			{Provider: "c", Value: dec("20.1"), Accepted: true},
+		}},
+		{Base: "USD", Quote: "JPY"}: {Value: dec("150"), Source: "consensus", Quotes: []domain.ProviderQuote{
+			{Provider: "a", Value: dec("150"), Accepted: true},
+			{Provider: "b", Value: dec("150"), Accepted: true},
+		}},
+	}
+
+	derived, ok := deriveFromPivot(domain.RatePair{Base: "MXN", Quote: "JPY"}, pairValueMap, "USD")
+
+	require.True(t, ok)
+	requireDecimal(t, "7.5", derived.Value)
+	require.Len(t, derived.Quotes, 2) // "c" didn't answer the JPY leg
+	require.Equal(t, "a", derived.Quotes[0].Provider)
+	requireDecimal(t, "7.5", derived.Quotes[0].Value)
+	require.True(t, derived.Quotes[0].Accepted)
+	require.Equal(t, "b", derived.Quotes[1].Provider)
+	requireDecimal(t, "6", derived.Quotes[1].Value)
+	require.False(t, derived.Quotes[1].Accepted)
+}
+
+func TestUpdatePendingRates_WithPivot_DerivesOnlyMissingPairs(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "MXN", Quote: "JPY"}
+	p2 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "GBP"}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-5", defaultClaimLease).Return([]domain.PendingRateUpdate{p1, p2}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "EUR").
+		Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"GBP": dec("0.85")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "MXN").Return(domain.ExchangeRates{}, errors.New("boom")).Once()
+	// only legs of the pair left without a value are fetched through the pivot
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").
+		Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"MXN": dec("20"), "JPY": dec("150"), "EUR": dec("0.8")}}, nil).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-5", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		applied := args.Get(2).([]domain.AppliedRateUpdate)
+		require.Len(t, applied, 2)
+		requireDecimal(t, "7.5", applied[0].Value)
+		requireDecimal(t, "0.85", applied[1].Value)
+	}).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-5", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{PivotCurrency: "USD"})
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockUpdatesRepo.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestUpdatePendingRates_WithPivotBase_DerivesFromTheSameResponse(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}
+	p2 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 2, Base: "MXN", Quote: "JPY"}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-6", defaultClaimLease).Return([]domain.PendingRateUpdate{p1, p2}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").
+		Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"MXN": dec("20"), "JPY": dec("150"), "EUR": dec("0.8")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "MXN").Return(domain.ExchangeRates{}, errors.New("boom")).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-6", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		applied := args.Get(2).([]domain.AppliedRateUpdate)
+		require.Len(t, applied, 2)
+		requireDecimal(t, "0.8", applied[0].Value)
+		requireDecimal(t, "7.5", applied[1].Value)
+	}).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-6", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{PivotCurrency: "USD"})
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockUpdatesRepo.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
 func TestDoUpdateRates_NoApplicableUpdates_DoesNotCallRepoAndDoesNotCleanCache(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	cacheMock := new(MockRateUpdateCache)
 	pending := []domain.PendingRateUpdate{
 		{UpdateID: uuid.New(), PairID: 10, Base: "USD", Quote: "FOO"},
 	}
-	pairValueMap := map[domain.RatePair]float64{
-		{Base: "USD", Quote: "EUR"}: 1.47,
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("1.47"), Source: "test"},
 	}
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "run-1", []uuid.UUID{pending[0].UpdateID}).Return(nil).Once()
 
-	count, err := doUpdateRates(context.Background(), pending, pairValueMap, mockUpdatesRepo, cacheMock)
+	count, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, nil)
 
 	require.NoError(t, err)
 	require.Equal(t, 0, count)
-	mockUpdatesRepo.AssertNotCalled(t, "ApplyUpdates", mock.Anything, mock.Anything)
+	mockUpdatesRepo.AssertNotCalled(t, "ApplyUpdates", mock.Anything, "run-1", mock.Anything)
 	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
 }
 
@@ -241,14 +551,14 @@ func TestDoUpdateRates_ApplyUpdatesError_Propagates(t *testing.T) {
 	pending := []domain.PendingRateUpdate{
 		{UpdateID: uuid.New(), PairID: 5, Base: "USD", Quote: "EUR"},
 	}
-	pairs := map[domain.RatePair]float64{
-		{Base: "USD", Quote: "EUR"}: 1.01,
+	pairs := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("1.01"), Source: "test"},
 	}
 	wantErr := errors.New("db fail")
 
-	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, mock.Anything).Return(wantErr).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).Return(wantErr).Once()
 
-	count, err := doUpdateRates(context.Background(), pending, pairs, mockUpdatesRepo, cacheMock)
+	count, err := doUpdateRates(context.Background(), "run-1", pending, pairs, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, nil)
 
 	require.Error(t, err)
 	require.ErrorContains(t, err, "failed to update rates")
@@ -257,20 +567,91 @@ func TestDoUpdateRates_ApplyUpdatesError_Propagates(t *testing.T) {
 	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
 }
 
+func TestDoUpdateRates_ClosesSkippedByPolicy(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	now := time.Now()
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR", Attempts: 0, CreatedAt: now},                     // retried
+		{UpdateID: uuid.New(), PairID: 2, Base: "USD", Quote: "JPY", Attempts: 2, CreatedAt: now},                     // third attempt -> failed
+		{UpdateID: uuid.New(), PairID: 3, Base: "USD", Quote: "GBP", Attempts: 0, CreatedAt: now.Add(-2 * time.Hour)}, // too old -> expired
+	}
+	opts := JobOptions{MaxAttempts: 3, MaxAge: time.Hour}
+
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "run-1", []uuid.UUID{pending[0].UpdateID}).Return(nil).Once()
+	mockUpdatesRepo.On("CloseUpdates", mock.Anything, "run-1", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		closed := args.Get(2).([]domain.ClosedRateUpdate)
+		require.Len(t, closed, 2)
+		require.Equal(t, pending[1].UpdateID, closed[0].UpdateID)
+		require.Equal(t, domain.StatusFailed, closed[0].Status)
+		require.Equal(t, "rate wasn't fetched after 3 attempts", closed[0].Reason)
+		require.Equal(t, pending[2].UpdateID, closed[1].UpdateID)
+		require.Equal(t, domain.StatusExpired, closed[1].Status)
+		require.Equal(t, "rate wasn't fetched within 1h0m0s", closed[1].Reason)
+	}).Once()
+	cacheMock.On("CleanBatch", []domain.RatePair{{Base: "USD", Quote: "JPY"}, {Base: "USD", Quote: "GBP"}}).Return().Once()
+
+	count, err := doUpdateRates(context.Background(), "run-1", pending, map[domain.RatePair]fetchedRate{}, fetchOutcome{}, opts, mockUpdatesRepo, cacheMock, nil)
+
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+	mockUpdatesRepo.AssertExpectations(t)
+	mockUpdatesRepo.AssertNotCalled(t, "ApplyUpdates", mock.Anything, "run-1", mock.Anything)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestDoUpdateRates_NotifiesAppliedAndClosed(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	notifierMock := new(MockUpdateNotifier)
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR", CreatedAt: time.Now()},
+		{UpdateID: uuid.New(), PairID: 2, Base: "USD", Quote: "JPY", Attempts: 2, CreatedAt: time.Now()}, // closed
+		{UpdateID: uuid.New(), PairID: 3, Base: "USD", Quote: "GBP", CreatedAt: time.Now()},              // retried
+	}
+	pairValueMap := map[domain.RatePair]fetchedRate{{Base: "USD", Quote: "EUR"}: {Value: dec("0.8")}}
+
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).Return(nil).Once()
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "run-1", []uuid.UUID{pending[2].UpdateID}).Return(nil).Once()
+	mockUpdatesRepo.On("CloseUpdates", mock.Anything, "run-1", mock.Anything).Return(nil).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return()
+	notifierMock.On("NotifyFinished", []uuid.UUID{pending[0].UpdateID}).Return().Once()
+	notifierMock.On("NotifyFinished", []uuid.UUID{pending[1].UpdateID}).Return().Once()
+
+	_, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{MaxAttempts: 3, Notifier: notifierMock}, mockUpdatesRepo, cacheMock, nil)
+
+	require.NoError(t, err)
+	notifierMock.AssertExpectations(t)
+}
+
+func TestDoUpdateRates_CloseUpdatesError_Propagates(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR", Attempts: 4, CreatedAt: time.Now()},
+	}
+	mockUpdatesRepo.On("CloseUpdates", mock.Anything, "run-1", mock.Anything).Return(errors.New("db fail")).Once()
+
+	_, err := doUpdateRates(context.Background(), "run-1", pending, map[domain.RatePair]fetchedRate{}, fetchOutcome{}, JobOptions{MaxAttempts: 5}, mockUpdatesRepo, cacheMock, nil)
+
+	require.ErrorContains(t, err, "failed to close updates")
+	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
+}
+
 // --- UpdatePendingRates ---
 
-func TestUpdatePendingRates_GetPendingError(t *testing.T) {
+func TestUpdatePendingRates_ClaimPendingError(t *testing.T) {
 	mockUpdatesRepo := new(MockRateUpdateRepository)
 	mockClient := new(MockRateClient)
 	cacheMock := new(MockRateUpdateCache)
 	wantErr := errors.New("db unavailable")
 
-	mockUpdatesRepo.On("GetPending", mock.Anything).Return(nil, wantErr).Once()
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-1", defaultClaimLease).Return(nil, wantErr).Once()
 
-	err := UpdatePendingRates(context.Background(), "exec-1", mockUpdatesRepo, mockClient, cacheMock)
+	err := UpdatePendingRates(context.Background(), "exec-1", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{})
 
 	require.Error(t, err)
-	require.ErrorContains(t, err, "failed to get pending rates")
+	require.ErrorContains(t, err, "failed to claim pending rates")
 	mockUpdatesRepo.AssertExpectations(t)
 	mockClient.AssertExpectations(t)
 	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
@@ -281,13 +662,13 @@ func TestUpdatePendingRates_NoPending(t *testing.T) {
 	mockClient := new(MockRateClient)
 	cacheMock := new(MockRateUpdateCache)
 
-	mockUpdatesRepo.On("GetPending", mock.Anything).Return([]domain.PendingRateUpdate{}, nil).Once()
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-2", defaultClaimLease).Return([]domain.PendingRateUpdate{}, nil).Once()
 
-	err := UpdatePendingRates(context.Background(), "exec-2", mockUpdatesRepo, mockClient, cacheMock)
+	err := UpdatePendingRates(context.Background(), "exec-2", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{})
 
 	require.NoError(t, err)
 	mockUpdatesRepo.AssertExpectations(t)
-	mockUpdatesRepo.AssertNotCalled(t, "ApplyUpdates", mock.Anything, mock.Anything)
+	mockUpdatesRepo.AssertNotCalled(t, "ApplyUpdates", mock.Anything, "exec-2", mock.Anything)
 	mockClient.AssertExpectations(t)
 	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
 }
@@ -299,22 +680,22 @@ func TestUpdatePendingRates_Success_AppliesValues(t *testing.T) {
 
 	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}
 	p2 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "PLN"}
-	mockUpdatesRepo.On("GetPending", mock.Anything).Return([]domain.PendingRateUpdate{p1, p2}, nil).Once()
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-3", defaultClaimLease).Return([]domain.PendingRateUpdate{p1, p2}, nil).Once()
 
-	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(map[string]float64{"EUR": 1.23}, nil).Once()
-	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(map[string]float64{"PLN": 4.56}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("1.23")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"PLN": dec("4.56")}}, nil).Once()
 
-	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
-		updates := args.Get(1).([]domain.AppliedRateUpdate)
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-3", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		updates := args.Get(2).([]domain.AppliedRateUpdate)
 		require.Len(t, updates, 2)
 		// Sort by PairID for deterministic check
 		if updates[0].PairID > updates[1].PairID {
 			updates[0], updates[1] = updates[1], updates[0]
 		}
 		require.Equal(t, int64(1), updates[0].PairID)
-		require.InDelta(t, 1.23, updates[0].Value, 1e-9)
+		requireDecimal(t, "1.23", updates[0].Value)
 		require.Equal(t, int64(2), updates[1].PairID)
-		require.InDelta(t, 4.56, updates[1].Value, 1e-9)
+		requireDecimal(t, "4.56", updates[1].Value)
 	}).Once()
 
 	expectedPairs := []domain.RatePair{
@@ -325,7 +706,7 @@ func TestUpdatePendingRates_Success_AppliesValues(t *testing.T) {
 		return assert.ElementsMatch(t, expectedPairs, pairs)
 	})).Return().Once()
 
-	err := UpdatePendingRates(context.Background(), "exec-3", mockUpdatesRepo, mockClient, cacheMock)
+	err := UpdatePendingRates(context.Background(), "exec-3", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{})
 
 	require.NoError(t, err)
 	mockUpdatesRepo.AssertExpectations(t)
@@ -341,11 +722,11 @@ func TestDoUpdateRates_CleansCacheForUpdatedPairs(t *testing.T) {
 		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"},
 		{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "USD"},
 	}
-	pairs := map[domain.RatePair]float64{
-		{Base: "USD", Quote: "EUR"}: 1.2,
+	pairs := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("1.2"), Source: "test"},
 	}
 
-	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, mock.Anything).Return(nil).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).Return(nil).Once()
 	expectedPairs := []domain.RatePair{
 		{Base: "USD", Quote: "EUR"},
 		{Base: "EUR", Quote: "USD"},
@@ -354,7 +735,7 @@ func TestDoUpdateRates_CleansCacheForUpdatedPairs(t *testing.T) {
 		return assert.ElementsMatch(t, expectedPairs, pairs)
 	})).Return().Once()
 
-	count, err := doUpdateRates(context.Background(), pending, pairs, mockUpdatesRepo, cacheMock)
+	count, err := doUpdateRates(context.Background(), "run-1", pending, pairs, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, nil)
 
 	require.NoError(t, err)
 	require.Equal(t, 2, count)
@@ -368,14 +749,14 @@ func TestUpdatePendingRates_ApplyUpdatesError_Propagates(t *testing.T) {
 	cacheMock := new(MockRateUpdateCache)
 
 	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}
-	mockUpdatesRepo.On("GetPending", mock.Anything).Return([]domain.PendingRateUpdate{p1}, nil).Once()
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-4", defaultClaimLease).Return([]domain.PendingRateUpdate{p1}, nil).Once()
 
-	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(map[string]float64{"EUR": 1.11}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("1.11")}}, nil).Once()
 
 	wantErr := errors.New("apply failed")
-	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, mock.Anything).Return(wantErr).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-4", mock.Anything).Return(wantErr).Once()
 
-	err := UpdatePendingRates(context.Background(), "exec-4", mockUpdatesRepo, mockClient, cacheMock)
+	err := UpdatePendingRates(context.Background(), "exec-4", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{})
 
 	require.Error(t, err)
 	require.ErrorContains(t, err, "failed to update rates")
@@ -383,3 +764,272 @@ func TestUpdatePendingRates_ApplyUpdatesError_Propagates(t *testing.T) {
 	mockClient.AssertExpectations(t)
 	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
 }
+
+func TestUpdatePendingRates_ClaimLost_NothingPublished(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+	publisherMock := new(MockRatePublisher)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-6", time.Minute).Return([]domain.PendingRateUpdate{p1}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("1.11")}}, nil).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-6", mock.Anything).Return(domain.ErrClaimLost).Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-6", mockUpdatesRepo, mockClient, cacheMock, publisherMock, JobOptions{ClaimLease: time.Minute})
+
+	require.ErrorIs(t, err, domain.ErrClaimLost)
+	mockUpdatesRepo.AssertExpectations(t)
+	cacheMock.AssertNotCalled(t, "CleanBatch", mock.Anything)
+	publisherMock.AssertNotCalled(t, "Publish", mock.Anything)
+}
+
+func TestDoUpdateRates_PartlyLostClaim_PublishesAppliedOnly(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	cacheMock := new(MockRateUpdateCache)
+	publisherMock := new(MockRatePublisher)
+	pending := []domain.PendingRateUpdate{
+		{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"},
+		{UpdateID: uuid.New(), PairID: 2, Base: "USD", Quote: "GBP"},
+	}
+	pairValueMap := map[domain.RatePair]fetchedRate{
+		{Base: "USD", Quote: "EUR"}: {Value: dec("0.8"), Source: "test"},
+		{Base: "USD", Quote: "GBP"}: {Value: dec("0.7"), Source: "test"},
+	}
+
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "run-1", mock.Anything).
+		Return(&domain.ClaimLostError{UpdateIDs: []uuid.UUID{pending[1].UpdateID}}).Once()
+	cacheMock.On("CleanBatch", []domain.RatePair{{Base: "USD", Quote: "EUR"}}).Return().Once()
+	publisherMock.On("Publish", mock.Anything).Run(func(args mock.Arguments) {
+		changes := args.Get(0).([]domain.RateChange)
+		require.Len(t, changes, 1)
+		require.Equal(t, pending[0].UpdateID, changes[0].UpdateID)
+	}).Return().Once()
+
+	updated, err := doUpdateRates(context.Background(), "run-1", pending, pairValueMap, fetchOutcome{}, JobOptions{}, mockUpdatesRepo, cacheMock, publisherMock)
+
+	require.ErrorIs(t, err, domain.ErrClaimLost)
+	require.Equal(t, 1, updated)
+	cacheMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+}
+
+func TestUpdatePendingRates_TracesRunAsRootWithProcessBaseChildren(t *testing.T) {
+	// the package tracer delegates to the first global provider only, so this is the one test installing it
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR"}
+	p2 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 2, Base: "GBP", Quote: "JPY", Attempts: 1}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-traced", defaultClaimLease).Return([]domain.PendingRateUpdate{p1, p2}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("0.92")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "GBP").Return(domain.ExchangeRates{}, errors.New("upstream down")).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-traced", mock.Anything).Return(nil).Once()
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "exec-traced", []uuid.UUID{p2.UpdateID}).Return(nil).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-traced", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	var root sdktrace.ReadOnlySpan
+	children := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range spans {
+		switch s.Name() {
+		case "UpdatePendingRates":
+			root = s
+		case "processBase":
+			for _, attr := range s.Attributes() {
+				if attr.Key == "fx.base" {
+					children[attr.Value.AsString()] = s
+				}
+			}
+		}
+	}
+	require.NotNil(t, root)
+	require.False(t, root.Parent().IsValid())
+	require.Contains(t, root.Attributes(), attribute.String("fx.exec_id", "exec-traced"))
+	require.Len(t, children, 2)
+	for _, child := range children {
+		require.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID())
+		require.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID())
+	}
+	require.NotEqual(t, codes.Error, children["USD"].Status().Code)
+	require.Equal(t, codes.Error, children["GBP"].Status().Code)
+}
+
+// --- upstream error types ---
+
+func TestProcessInParallel_RejectedCredentials_StopsFetching(t *testing.T) {
+	mockClient := new(MockRateClient)
+	pairs := make(map[domain.RatePair]struct{})
+	for _, base := range []string{"USD", "EUR", "GBP", "JPY", "MXN", "PLN", "CHF", "CAD", "AUD", "NZD", "SEK", "NOK"} {
+		pairs[domain.RatePair{Base: base, Quote: "XXX"}] = struct{}{}
+	}
+	authErr := fmt.Errorf("api returned non-success result: error (invalid-key): %w", domain.ErrUpstreamAuth)
+	mockClient.On("GetExchangeRates", mock.Anything, mock.Anything).Return(domain.ExchangeRates{}, authErr)
+
+	pairValueMap, outcome := processInParallel(context.Background(), mockClient, pairs, JobOptions{})
+
+	require.Empty(t, pairValueMap)
+	require.ErrorIs(t, outcome.stopped, domain.ErrUpstreamAuth)
+	// every worker stops after the fetch it was busy with, bases left in the queue weren't asked for
+	require.LessOrEqual(t, len(mockClient.Calls), numWorkers)
+	require.Len(t, outcome.attempted, len(mockClient.Calls))
+	require.Len(t, outcome.postponed, len(pairs)-len(mockClient.Calls))
+}
+
+func TestUpdatePendingRates_RejectedCredentials_ReleasesBasesNotFetched(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "GBP", CreatedAt: time.Now()}
+	p2 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 2, Base: "EUR", Quote: "JPY", CreatedAt: time.Now()}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-12", defaultClaimLease).Return([]domain.PendingRateUpdate{p1, p2}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, fmt.Errorf("invalid-key: %w", domain.ErrUpstreamAuth)).Once()
+	// cut short by the stop, unless the stop came before it was asked for at all
+	mockClient.On("GetExchangeRates", mock.Anything, "EUR").Return(domain.ExchangeRates{}, context.Canceled).Run(func(args mock.Arguments) {
+		<-args.Get(0).(context.Context).Done()
+	}).Maybe()
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "exec-12", []uuid.UUID{p1.UpdateID}).Return(nil).Once()
+	mockUpdatesRepo.On("ReleaseClaims", mock.Anything, "exec-12", []uuid.UUID{p2.UpdateID}).Return(nil).Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-12", mockUpdatesRepo, mockClient, new(MockRateUpdateCache), nil, JobOptions{})
+
+	require.ErrorIs(t, err, domain.ErrUpstreamAuth)
+	mockUpdatesRepo.AssertExpectations(t)
+}
+
+func TestUpdatePendingRates_CodeMissingFromPivot_FetchedDirectly(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "MXN", Quote: "XAU", CreatedAt: time.Now()}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-13", defaultClaimLease).Return([]domain.PendingRateUpdate{p1}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "MXN").Return(domain.ExchangeRates{}, errors.New("boom")).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").
+		Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"MXN": dec("20")}}, nil).Once()
+	// the pivot table has no XAU, asking for it tells whether it's supported at all
+	mockClient.On("GetExchangeRates", mock.Anything, "XAU").Return(domain.ExchangeRates{}, fmt.Errorf("unsupported-code: %w", domain.ErrUnsupportedCurrency)).Once()
+	mockUpdatesRepo.On("CloseUpdates", mock.Anything, "exec-13", []domain.ClosedRateUpdate{
+		{UpdateID: p1.UpdateID, Status: domain.StatusFailed, Reason: "currency XAU isn't supported by the rate provider"},
+	}).Return(nil).Once()
+	cacheMock.On("CleanBatch", []domain.RatePair{{Base: "MXN", Quote: "XAU"}}).Return().Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-13", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{PivotCurrency: "USD"})
+
+	require.NoError(t, err)
+	mockClient.AssertExpectations(t)
+	mockUpdatesRepo.AssertExpectations(t)
+	cacheMock.AssertExpectations(t)
+}
+
+func TestProcessInParallel_CollectsUnsupportedBases(t *testing.T) {
+	mockClient := new(MockRateClient)
+	pairs := map[domain.RatePair]struct{}{
+		{Base: "USD", Quote: "EUR"}: {},
+		{Base: "XAU", Quote: "USD"}: {},
+	}
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("0.92")}}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "XAU").Return(domain.ExchangeRates{}, fmt.Errorf("unsupported-code: %w", domain.ErrUnsupportedCurrency)).Once()
+
+	pairValueMap, outcome := processInParallel(context.Background(), mockClient, pairs, JobOptions{})
+
+	require.Len(t, pairValueMap, 1)
+	require.NoError(t, outcome.stopped)
+	require.Equal(t, map[string]struct{}{"XAU": {}}, outcome.unsupported)
+	mockClient.AssertExpectations(t)
+}
+
+func TestDoUpdateRates_FailsUpdatesOfUnsupportedCurrencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		pivot      string
+		pending    domain.PendingRateUpdate
+		wantReason string
+	}{
+		{name: "base", pending: domain.PendingRateUpdate{Base: "XAU", Quote: "USD"}, wantReason: "currency XAU isn't supported by the rate provider"},
+		{name: "quote", pending: domain.PendingRateUpdate{Base: "EUR", Quote: "XAU"}, wantReason: "currency XAU isn't supported by the rate provider"},
+		{name: "pivot", pivot: "XAU", pending: domain.PendingRateUpdate{Base: "MXN", Quote: "JPY"}, wantReason: "currency XAU isn't supported by the rate provider"},
+		{name: "other", pending: domain.PendingRateUpdate{Base: "MXN", Quote: "JPY"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUpdatesRepo := new(MockRateUpdateRepository)
+			cacheMock := new(MockRateUpdateCache)
+			pr := tt.pending
+			pr.UpdateID, pr.PairID, pr.CreatedAt = uuid.New(), 1, time.Now()
+			if tt.wantReason == "" {
+				mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "run-1", []uuid.UUID{pr.UpdateID}).Return(nil).Once()
+			} else {
+				mockUpdatesRepo.On("CloseUpdates", mock.Anything, "run-1", []domain.ClosedRateUpdate{
+					{UpdateID: pr.UpdateID, Status: domain.StatusFailed, Reason: tt.wantReason},
+				}).Return(nil).Once()
+				cacheMock.On("CleanBatch", []domain.RatePair{{Base: pr.Base, Quote: pr.Quote}}).Return().Once()
+			}
+
+			_, err := doUpdateRates(context.Background(), "run-1", []domain.PendingRateUpdate{pr}, map[domain.RatePair]fetchedRate{},
+				fetchOutcome{unsupported: map[string]struct{}{"XAU": {}}}, JobOptions{PivotCurrency: tt.pivot}, mockUpdatesRepo, cacheMock, nil)
+
+			require.NoError(t, err)
+			mockUpdatesRepo.AssertExpectations(t)
+			cacheMock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdatePendingRates_RejectedCredentials_AppliesFetchedAndFails(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR", CreatedAt: time.Now()}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, "exec-7", defaultClaimLease).Return([]domain.PendingRateUpdate{p1}, nil).Once()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, fmt.Errorf("invalid-key: %w", domain.ErrUpstreamAuth)).Once()
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "exec-7", []uuid.UUID{p1.UpdateID}).Return(nil).Once()
+
+	err := UpdatePendingRates(context.Background(), "exec-7", mockUpdatesRepo, mockClient, cacheMock, nil, JobOptions{})
+
+	require.ErrorIs(t, err, domain.ErrUpstreamAuth)
+	require.ErrorContains(t, err, "run was stopped as the rate provider rejected the credentials")
+	mockUpdatesRepo.AssertExpectations(t)
+}
+
+func TestUpdatePendingRates_QuotaExhausted_SkipsRunsUntilBackoffEnds(t *testing.T) {
+	mockUpdatesRepo := new(MockRateUpdateRepository)
+	mockClient := new(MockRateClient)
+	cacheMock := new(MockRateUpdateCache)
+	now := time.Now()
+	backoff := NewQuotaBackoff(time.Minute, time.Hour)
+	backoff.now = func() time.Time { return now }
+	opts := JobOptions{QuotaBackoff: backoff}
+
+	p1 := domain.PendingRateUpdate{UpdateID: uuid.New(), PairID: 1, Base: "USD", Quote: "EUR", CreatedAt: now}
+	mockUpdatesRepo.On("ClaimPending", mock.Anything, mock.Anything, defaultClaimLease).Return([]domain.PendingRateUpdate{p1}, nil).Twice()
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{}, fmt.Errorf("quota-reached: %w", domain.ErrUpstreamQuota)).Once()
+	mockUpdatesRepo.On("IncrementAttempts", mock.Anything, "exec-8", []uuid.UUID{p1.UpdateID}).Return(nil).Once()
+
+	require.NoError(t, UpdatePendingRates(context.Background(), "exec-8", mockUpdatesRepo, mockClient, cacheMock, nil, opts))
+
+	// paused: nothing is claimed nor fetched
+	now = now.Add(59 * time.Second)
+	require.NoError(t, UpdatePendingRates(context.Background(), "exec-9", mockUpdatesRepo, mockClient, cacheMock, nil, opts))
+	mockUpdatesRepo.AssertNumberOfCalls(t, "ClaimPending", 1)
+
+	// once the pause is over, a successful fetch lifts the backoff
+	now = now.Add(time.Second)
+	mockClient.On("GetExchangeRates", mock.Anything, "USD").Return(domain.ExchangeRates{Provider: "test", Rates: map[string]decimal.Decimal{"EUR": dec("0.92")}}, nil).Once()
+	mockUpdatesRepo.On("ApplyUpdates", mock.Anything, "exec-10", mock.Anything).Return(nil).Once()
+	cacheMock.On("CleanBatch", mock.Anything).Return().Once()
+
+	require.NoError(t, UpdatePendingRates(context.Background(), "exec-10", mockUpdatesRepo, mockClient, cacheMock, nil, opts))
+	_, paused := backoff.pausedUntil()
+	require.False(t, paused)
+	mockUpdatesRepo.AssertExpectations(t)
+	mockClient.AssertExpectations(t)
+}
@@ -2,8 +2,10 @@ package rate
 
 import (
 	"errors"
+	"fxrates/internal/domain"
 	"maps"
 	"slices"
+	"sync/atomic"
 )
 
 var (
@@ -14,9 +16,16 @@ var (
 	ErrQuoteUnsupported = errors.New("quote currency not supported")
 )
 
+// CurrencyValidator checks codes against the supported currencies. The set is swapped atomically by Replace,
+// so it's refreshed at runtime without locking readers
 type CurrencyValidator struct {
-	supportedCodesSet map[string]struct{} // read only copy
-	supportedCodesLst []string            // read only copy
+	current atomic.Pointer[currencySet]
+}
+
+// currencySet is an immutable snapshot of supported currencies
+type currencySet struct {
+	minorUnits map[string]int // by code
+	codes      []string       // sorted
 }
 
 func (v *CurrencyValidator) ValidateCodes(base, quote string) error {
@@ -29,26 +38,57 @@ func (v *CurrencyValidator) ValidateCodes(base, quote string) error {
 	if base == quote {
 		return ErrSameCodes
 	}
-	if _, ok := v.supportedCodesSet[base]; !ok {
+	set := v.current.Load()
+	if _, ok := set.minorUnits[base]; !ok {
 		return ErrBaseUnsupported
 	}
-	if _, ok := v.supportedCodesSet[quote]; !ok {
+	if _, ok := set.minorUnits[quote]; !ok {
 		return ErrQuoteUnsupported
 	}
 	return nil
 }
 
 func (v *CurrencyValidator) SupportedCodes() []string {
-	return slices.Clone(v.supportedCodesLst)
+	return slices.Clone(v.current.Load().codes)
 }
 
-func NewValidator(supportedCurrencies map[string]struct{}) *CurrencyValidator {
-	codesSet := maps.Clone(supportedCurrencies)
-	codesLst := slices.Collect(maps.Keys(codesSet))
-	slices.Sort(codesLst)
+// IsSupported reports whether the code is currently accepted
+func (v *CurrencyValidator) IsSupported(code string) bool {
+	_, ok := v.current.Load().minorUnits[code]
+	return ok
+}
+
+// MinorUnits returns the decimal places of a supported currency, ISO 4217 defaults are used for unknown ones
+func (v *CurrencyValidator) MinorUnits(code string) int {
+	if units, ok := v.current.Load().minorUnits[code]; ok {
+		return units
+	}
+	return domain.MinorUnits(code)
+}
 
-	return &CurrencyValidator{
-		supportedCodesSet: codesSet,
-		supportedCodesLst: codesLst,
+// Replace atomically swaps supported currencies for the enabled ones of the list
+func (v *CurrencyValidator) Replace(currencies []domain.Currency) {
+	minorUnits := make(map[string]int, len(currencies))
+	for _, c := range currencies {
+		if c.Enabled {
+			minorUnits[c.Code] = c.MinorUnits
+		}
+	}
+	v.store(minorUnits)
+}
+
+func (v *CurrencyValidator) store(minorUnits map[string]int) {
+	codes := slices.Sorted(maps.Keys(minorUnits))
+	v.current.Store(&currencySet{minorUnits: minorUnits, codes: codes})
+}
+
+// NewValidator creates a validator of the given codes with ISO 4217 default minor units
+func NewValidator(supportedCurrencies map[string]struct{}) *CurrencyValidator {
+	minorUnits := make(map[string]int, len(supportedCurrencies))
+	for code := range supportedCurrencies {
+		minorUnits[code] = domain.MinorUnits(code)
 	}
+	v := &CurrencyValidator{}
+	v.store(minorUnits)
+	return v
 }
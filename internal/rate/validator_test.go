@@ -1,6 +1,7 @@
 package rate
 
 import (
+	"fxrates/internal/domain"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -44,3 +45,20 @@ func TestCurrencyValidator_SupportedCodes(t *testing.T) {
 	got[0] = "XXX"
 	require.ElementsMatch(t, []string{"USD", "EUR", "JPY"}, validator.SupportedCodes())
 }
+
+func TestCurrencyValidator_Replace_SwapsToEnabledCurrencies(t *testing.T) {
+	validator := NewValidator(map[string]struct{}{"USD": {}, "EUR": {}})
+
+	validator.Replace([]domain.Currency{
+		{Code: "USD", MinorUnits: 2, Enabled: true},
+		{Code: "EUR", MinorUnits: 2, Enabled: false},
+		{Code: "KWD", MinorUnits: 3, Enabled: true},
+	})
+
+	require.Equal(t, []string{"KWD", "USD"}, validator.SupportedCodes())
+	require.Equal(t, ErrQuoteUnsupported, validator.ValidateCodes("USD", "EUR"))
+	require.NoError(t, validator.ValidateCodes("USD", "KWD"))
+	require.Equal(t, 3, validator.MinorUnits("KWD"))
+	require.Equal(t, 0, validator.MinorUnits("JPY")) // unknown, ISO default
+	require.False(t, validator.IsSupported("EUR"))
+}
@@ -3,12 +3,33 @@ package rate
 import (
 	"fxrates/internal/domain"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type View struct {
 	Base      string
 	Quote     string
 	Status    domain.RateUpdateStatus
-	Value     *float64
+	Value     *decimal.Decimal
 	UpdatedAt *time.Time
+	Source    string
+	Quotes    []domain.ProviderQuote
+	Attempts  int
+	Reason    string
+	Derived   bool
+	Legs      []View
+}
+
+type ConversionView struct {
+	From   string
+	To     string
+	Amount decimal.Decimal
+	Result decimal.Decimal
+	// MinorUnits are the decimal places Result is rounded to
+	MinorUnits int
+	Rate       decimal.Decimal
+	UpdatedAt  time.Time
+	Derived    bool
+	Legs       []View
 }
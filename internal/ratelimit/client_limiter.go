@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fxrates/internal/apikey"
+	"fxrates/internal/metrics"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// clients idle for longer than clientIdleTTL are forgotten, so a refilled bucket doesn't hold memory
+	clientIdleTTL = 10 * time.Minute
+	sweepInterval = time.Minute
+)
+
+// ClientLimiter is a token bucket per client: per API key when the request is authenticated, per IP otherwise
+type ClientLimiter struct {
+	mu        sync.Mutex
+	clients   map[string]*client
+	limit     rate.Limit
+	burst     int
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Allow takes a token of the client, when there is none it returns how long to wait for the next one
+func (l *ClientLimiter) Allow(clientKey string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, c := range l.clients {
+			if now.Sub(c.lastSeen) >= clientIdleTTL {
+				delete(l.clients, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	c, ok := l.clients[clientKey]
+	if !ok {
+		c = &client{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[clientKey] = c
+	}
+	c.lastSeen = now
+
+	reservation := c.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now) // the request is rejected, so the token isn't taken
+		return false, delay
+	}
+	return true, 0
+}
+
+// Middleware rejects requests over the client's limit with 429 and Retry-After in seconds.
+// It must run after API key auth to limit keys rather than IPs
+func (l *ClientLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter := l.Allow(clientKey(r))
+		if !allowed {
+			metrics.RateLimited.Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "too many requests, retry later")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type errorResponse struct {
+	Error string `json:"error" example:"too many requests, retry later"`
+}
+
+func writeError(w http.ResponseWriter, statusCode int, errorMsg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(errorResponse{
+		Error: errorMsg,
+	})
+}
+
+func clientKey(r *http.Request) string {
+	return ClientKey(r.Context(), r.RemoteAddr)
+}
+
+// ClientKey identifies the client by the authenticated API key of ctx, by the IP of remoteAddr without one
+func ClientKey(ctx context.Context, remoteAddr string) string {
+	if key, ok := apikey.FromContext(ctx); ok {
+		if key.ID == 0 {
+			return "key:" + key.Owner // bootstrap key isn't stored
+		}
+		return fmt.Sprintf("key:%d", key.ID)
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+// NewClientLimiter allows every client requestsPerSec on average with bursts up to burst requests
+func NewClientLimiter(requestsPerSec float64, burst int) *ClientLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ClientLimiter{
+		clients: make(map[string]*client),
+		limit:   rate.Limit(requestsPerSec),
+		burst:   burst,
+		now:     time.Now,
+	}
+}
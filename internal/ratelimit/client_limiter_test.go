@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"fxrates/internal/apikey"
+	"fxrates/internal/domain"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientLimiter_Middleware_RejectsOverBurstWithRetryAfter(t *testing.T) {
+	l := NewClientLimiter(0.5, 2) // a token every 2s
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }))
+
+	serve := func(remoteAddr string, key *domain.APIKey) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rates/USD/EUR", nil)
+		req.RemoteAddr = remoteAddr
+		if key != nil {
+			req = req.WithContext(apikey.WithKey(req.Context(), *key))
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	require.Equal(t, http.StatusNoContent, serve("10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusNoContent, serve("10.0.0.1:5678", nil).Code) // same IP, other port
+	rr := serve("10.0.0.1:1234", nil)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "2", rr.Header().Get("Retry-After"))
+	require.JSONEq(t, `{"error":"too many requests, retry later"}`, rr.Body.String())
+
+	// keys are limited separately from their IP
+	key := domain.APIKey{ID: 7}
+	require.Equal(t, http.StatusNoContent, serve("10.0.0.1:1234", &key).Code)
+
+	// rejected requests don't take tokens, so one is available once refilled
+	now = now.Add(2 * time.Second)
+	require.Equal(t, http.StatusNoContent, serve("10.0.0.1:1234", nil).Code)
+	require.Equal(t, http.StatusTooManyRequests, serve("10.0.0.1:1234", nil).Code)
+}
+
+func TestClientLimiter_ForgetsIdleClients(t *testing.T) {
+	l := NewClientLimiter(1, 1)
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	allowed, _ := l.Allow("ip:10.0.0.1")
+	require.True(t, allowed)
+	now = now.Add(clientIdleTTL)
+	allowed, _ = l.Allow("ip:10.0.0.2")
+	require.True(t, allowed)
+
+	require.Len(t, l.clients, 1)
+	require.Contains(t, l.clients, "ip:10.0.0.2")
+}
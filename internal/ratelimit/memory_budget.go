@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBudget is an upstream budget of a single instance, counted in fixed minute and day windows (UTC)
+type MemoryBudget struct {
+	mu          sync.Mutex
+	perMinute   int
+	perDay      int
+	minuteStart time.Time
+	minuteUsed  int
+	dayStart    time.Time
+	dayUsed     int
+	now         func() time.Time
+}
+
+// Reserve takes up to n fetches from the budget and returns how many were granted
+func (b *MemoryBudget) Reserve(_ context.Context, n int) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now().UTC()
+	if minute := now.Truncate(time.Minute); !minute.Equal(b.minuteStart) {
+		b.minuteStart, b.minuteUsed = minute, 0
+	}
+	if day := now.Truncate(24 * time.Hour); !day.Equal(b.dayStart) {
+		b.dayStart, b.dayUsed = day, 0
+	}
+
+	granted := Grant(n, b.perMinute, b.minuteUsed, b.perDay, b.dayUsed)
+	b.minuteUsed += granted
+	b.dayUsed += granted
+	return granted, nil
+}
+
+// Grant returns how many of n fetches fit into both windows, a non-positive limit means unlimited.
+// Budgets of every store share it, so they grant alike
+func Grant(n int, perMinute int, minuteUsed int, perDay int, dayUsed int) int {
+	granted := n
+	if perMinute > 0 {
+		granted = min(granted, perMinute-minuteUsed)
+	}
+	if perDay > 0 {
+		granted = min(granted, perDay-dayUsed)
+	}
+	return max(granted, 0)
+}
+
+func NewMemoryBudget(perMinute int, perDay int) *MemoryBudget {
+	return &MemoryBudget{perMinute: perMinute, perDay: perDay, now: time.Now}
+}
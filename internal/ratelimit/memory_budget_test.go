@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBudget_GrantsWithinBothWindows(t *testing.T) {
+	b := NewMemoryBudget(3, 5)
+	now := time.Date(2025, 1, 2, 23, 58, 30, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	reserve := func(n int) int {
+		granted, err := b.Reserve(ctx, n)
+		require.NoError(t, err)
+		return granted
+	}
+
+	require.Equal(t, 2, reserve(2))
+	require.Equal(t, 1, reserve(4)) // minute limit
+	require.Equal(t, 0, reserve(1))
+
+	now = now.Add(time.Minute)      // next minute, same day
+	require.Equal(t, 2, reserve(3)) // day limit
+
+	now = now.Add(time.Minute) // next day
+	require.Equal(t, 3, reserve(3))
+}
+
+func TestMemoryBudget_ZeroLimitIsUnlimited(t *testing.T) {
+	b := NewMemoryBudget(0, 2)
+
+	granted, err := b.Reserve(context.Background(), 10)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, granted)
+}
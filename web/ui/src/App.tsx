@@ -1,7 +1,7 @@
 import { useEffect, useMemo, useState } from 'react'
 import type { FormEvent } from 'react'
 import './App.css'
-import { fetchLatestRate, fetchRateUpdate, fetchSupportedCurrencies, scheduleRateUpdate } from './api'
+import { fetchLatestRate, fetchRateUpdate, fetchSupportedCurrencies, scheduleRateUpdate, subscribeRateChanges } from './api'
 import type { RateUpdateView } from './api'
 import { ArrowPathIcon } from '@heroicons/react/24/outline'
 import { ArrowLeftRight } from 'lucide-react'
@@ -15,7 +15,7 @@ type UpdateRow = RateUpdateView & {
 type LatestRateState = {
     base: string
     quote: string
-    value: number
+    value: string
     updatedAt: string
 }
 
@@ -75,6 +75,25 @@ function App() {
         load()
     }, [])
 
+    // pending pairs are streamed, so rows get applied values without checking
+    const pendingPairs = useMemo(
+        () => [...new Set(updates.filter((u) => u.status === 'pending').map((u) => `${u.base}/${u.quote}`))].sort().join(','),
+        [updates],
+    )
+
+    useEffect(() => {
+        if (!pendingPairs) return
+        return subscribeRateChanges(pendingPairs.split(','), (change) => {
+            setUpdates((prev) =>
+                prev.map((item) =>
+                    item.updateId === change.updateId
+                        ? { ...item, status: 'applied', value: change.value, updatedAt: change.updatedAt, error: null }
+                        : item,
+                ),
+            )
+        })
+    }, [pendingPairs])
+
     const pairValid = useMemo(() => {
         if (!baseCode || !quoteCode) return false
         if (isCodesLoading) return false
@@ -171,7 +190,7 @@ function App() {
                             value: latest.value ?? item.value,
                             updatedAt: latest.updatedAt ?? item.updatedAt,
                             checking: false,
-                            error: null,
+                            error: latest.reason ?? null,
                         }
                         : item,
                 ),
@@ -2,7 +2,7 @@ type ErrorResponse = {
   error?: string
 }
 
-async function request<T>(path: string, init?: RequestInit): Promise<T> {
+async function request<T>(path: string, init?: RequestInit, acceptedStatuses: number[] = []): Promise<T> {
   const response = await fetch(path, {
     headers: {
       'Content-Type': 'application/json',
@@ -15,7 +15,7 @@ async function request<T>(path: string, init?: RequestInit): Promise<T> {
   const isJSON = contentType.includes('application/json')
   const payload = isJSON ? await response.json() : await response.text()
 
-  if (!response.ok) {
+  if (!response.ok && !acceptedStatuses.includes(response.status)) {
     const message =
       typeof payload === 'string'
         ? payload || 'Request failed'
@@ -33,7 +33,7 @@ export type SupportedCurrenciesResponse = {
 export type RateResponse = {
   base: string
   quote: string
-  value: number
+  value: string
   updated_at: string
 }
 
@@ -41,7 +41,7 @@ export type ScheduleUpdateResponse = {
   update_id: string
 }
 
-export type RateUpdateStatus = 'pending' | 'applied' | string
+export type RateUpdateStatus = 'pending' | 'applied' | 'failed' | 'expired' | string
 
 export type RateUpdatePendingResponse = {
   update_id: string
@@ -51,7 +51,13 @@ export type RateUpdatePendingResponse = {
 }
 
 export type RateUpdateAppliedResponse = RateUpdatePendingResponse & {
-  value: number
+  value: string
+  updated_at: string
+}
+
+// returned with 410 status for updates closed without a value
+export type RateUpdateClosedResponse = RateUpdatePendingResponse & {
+  reason: string
   updated_at: string
 }
 
@@ -60,6 +66,7 @@ const API_PATHS = {
   latest: (base: string, quote: string) => `/api/v1/rates/${base}/${quote}`,
   schedule: '/api/v1/rates/updates',
   update: (id: string) => `/api/v1/rates/updates/${id}`,
+  stream: '/api/v1/rates/stream',
 }
 
 export async function fetchSupportedCurrencies() {
@@ -72,7 +79,7 @@ export async function fetchSupportedCurrencies() {
 export type RateView = {
   base: string
   quote: string
-  value: number
+  value: string
   updatedAt: string
 }
 
@@ -101,14 +108,28 @@ export type RateUpdateView = {
   base: string
   quote: string
   status: RateUpdateStatus
-  value?: number
+  value?: string
   updatedAt?: string
+  reason?: string
 }
 
 export async function fetchRateUpdate(updateId: string): Promise<RateUpdateView> {
-  const res = await request<RateUpdatePendingResponse | RateUpdateAppliedResponse>(API_PATHS.update(updateId), {
-    method: 'GET',
-  })
+  const res = await request<RateUpdatePendingResponse | RateUpdateAppliedResponse | RateUpdateClosedResponse>(
+    API_PATHS.update(updateId),
+    { method: 'GET' },
+    [410],
+  )
+
+  if ('reason' in res) {
+    return {
+      updateId: res.update_id,
+      base: res.base,
+      quote: res.quote,
+      status: res.status,
+      updatedAt: res.updated_at,
+      reason: res.reason,
+    }
+  }
 
   if ('value' in res && 'updated_at' in res) {
     return {
@@ -128,3 +149,36 @@ export async function fetchRateUpdate(updateId: string): Promise<RateUpdateView>
     status: res.status,
   }
 }
+
+type RateEventResponse = {
+  update_id: string
+  base: string
+  quote: string
+  value: string
+  source?: string
+  updated_at: string
+}
+
+export type RateChange = {
+  updateId: string
+  base: string
+  quote: string
+  value: string
+  updatedAt: string
+}
+
+// subscribeRateChanges streams values applied for "BASE/QUOTE" pairs, returns a func closing the stream
+export function subscribeRateChanges(pairs: string[], onChange: (change: RateChange) => void): () => void {
+  const source = new EventSource(`${API_PATHS.stream}?pairs=${encodeURIComponent(pairs.join(','))}`)
+  source.addEventListener('rate', (event) => {
+    const res = JSON.parse((event as MessageEvent<string>).data) as RateEventResponse
+    onChange({
+      updateId: res.update_id,
+      base: res.base,
+      quote: res.quote,
+      value: res.value,
+      updatedAt: res.updated_at,
+    })
+  })
+  return () => source.close()
+}
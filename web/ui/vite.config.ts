@@ -5,10 +5,13 @@ export default defineConfig(({ mode }) => {
   const env = loadEnv(mode, process.cwd(), '')
   const proxyTarget = env.VITE_PROXY_TARGET || 'http://localhost:8080'
   const port = Number(env.VITE_PORT || 5173)
+  // the key is added by the proxy, so it never reaches the browser (EventSource can't send headers anyway)
+  const apiKey = env.VITE_PROXY_API_KEY
   const sharedProxy = {
     '/api': {
       target: proxyTarget,
       changeOrigin: true,
+      headers: apiKey ? { 'X-API-Key': apiKey } : undefined,
     },
   }
 